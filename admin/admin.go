@@ -0,0 +1,256 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package admin contains an http.Handler for controlling logging at runtime. A GET
+// shows the levels, queue depths and backend health as JSON, and POSTs change
+// levels, flush backends or toggle sampling.
+//
+//	http.Handle("/debug/jog", admin.New(admin.Config{
+//		Jog:     j,
+//		Loggers: map[string]jog.Logger{"async": async, "sampler": sampler},
+//	}))
+//
+//	$ curl -d action=level -d logger=db -d level=debug -d for=5m localhost:8080/debug/jog
+//	$ curl -d action=flush localhost:8080/debug/jog
+//	$ curl -d action=sampling -d logger=sampler -d enabled=false localhost:8080/debug/jog
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"code.minty.io/jog"
+	"code.minty.io/jog/loggers"
+)
+
+// Config holds the settings for a Handler
+type Config struct {
+	// Jog whose named loggers (see jog.Named) have their levels changed
+	Jog *jog.Jog
+	// Loggers shown and controlled by name, their state is found through the
+	// methods they implement, eg. Len, Dropped, Health, Latency, Flush and SetEnabled
+	Loggers map[string]jog.Logger
+}
+
+type flusher interface {
+	Flush() error
+}
+
+// Handler shows and controls the logging of a process, see the package docs.
+// It should be mounted behind the application's authentication.
+type Handler struct {
+	cfg  Config
+	mu   sync.Mutex
+	undo map[string]*levelOverride
+}
+
+// A level override set by a POST, reverted by undo
+type levelOverride struct {
+	undo func()
+	// Timer reverting an override set `for` a duration
+	timer *time.Timer
+}
+
+// Reverts the override, stopping its timer
+func (o *levelOverride) revert() {
+	if o.timer != nil {
+		o.timer.Stop()
+	}
+	o.undo()
+}
+
+// Status is the state shown by a GET
+type Status struct {
+	// Level of the unnamed logger, and the overridden levels of named loggers
+	Level     jog.Level               `json:"level"`
+	Overrides map[string]jog.Level    `json:"overrides"`
+	Loggers   map[string]LoggerStatus `json:"loggers"`
+	Counts    map[string]uint64       `json:"counts"`
+}
+
+// LoggerStatus is the state of a logger, only the fields it supports are set
+type LoggerStatus struct {
+	Queue      *int                     `json:"queue,omitempty"`
+	Dropped    *uint64                  `json:"dropped,omitempty"`
+	Health     []loggers.EndpointHealth `json:"health,omitempty"`
+	Sampling   *bool                    `json:"sampling,omitempty"`
+	SampleRate *int                     `json:"sample_rate,omitempty"`
+	Latency    *LatencyStatus           `json:"latency,omitempty"`
+}
+
+// LatencyStatus is the latency of a logger's Log, and LogBatch, calls, see loggers.Timed
+type LatencyStatus struct {
+	Log   jog.LatencyStats `json:"log"`
+	Batch jog.LatencyStats `json:"batch"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET", "HEAD":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h.Status())
+	case "POST":
+		if err := h.action(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h.Status())
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Status returns the current state
+func (h *Handler) Status() Status {
+	s := Status{
+		Level:     h.cfg.Jog.Level(),
+		Overrides: h.cfg.Jog.Overrides(),
+		Loggers:   make(map[string]LoggerStatus, len(h.cfg.Loggers)),
+		Counts:    h.cfg.Jog.Counts(),
+	}
+	for name, l := range h.cfg.Loggers {
+		var ls LoggerStatus
+		if q, ok := l.(interface{ Len() int }); ok {
+			n := q.Len()
+			ls.Queue = &n
+		}
+		if d, ok := l.(jog.Dropper); ok {
+			n := d.Dropped()
+			ls.Dropped = &n
+		}
+		if hl, ok := l.(interface {
+			Health() []loggers.EndpointHealth
+		}); ok {
+			ls.Health = hl.Health()
+		}
+		if lt, ok := l.(interface {
+			Latency() (log, batch jog.LatencyStats)
+		}); ok {
+			log, batch := lt.Latency()
+			ls.Latency = &LatencyStatus{log, batch}
+		}
+		if sm, ok := l.(*loggers.Sampler); ok {
+			on, rate := sm.Enabled(), sm.Rate()
+			ls.Sampling, ls.SampleRate = &on, &rate
+		}
+		s.Loggers[name] = ls
+	}
+	return s
+}
+
+// Performs the POSTed action
+func (h *Handler) action(r *http.Request) error {
+	switch action := r.FormValue("action"); action {
+	case "level":
+		return h.level(r.FormValue("logger"), r.FormValue("level"), r.FormValue("for"))
+	case "flush":
+		return h.flush(r.FormValue("logger"))
+	case "sampling":
+		on, err := strconv.ParseBool(r.FormValue("enabled"))
+		if err != nil {
+			return fmt.Errorf("invalid enabled %q", r.FormValue("enabled"))
+		}
+		l, err := h.logger(r.FormValue("logger"))
+		if err != nil {
+			return err
+		}
+		sm, ok := l.(*loggers.Sampler)
+		if !ok {
+			return fmt.Errorf("logger %q doesn't sample", r.FormValue("logger"))
+		}
+		sm.SetEnabled(on)
+		return nil
+	default:
+		return fmt.Errorf("unknown action %q", action)
+	}
+}
+
+// Overrides the level of the named logger, for `dur` when given, or reverts it
+// when `level` is empty
+func (h *Handler) level(name, level, dur string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if o, ok := h.undo[name]; ok {
+		o.revert()
+		delete(h.undo, name)
+	}
+	if level == "" {
+		return nil
+	}
+	l := jog.ParseLevel(level)
+	if l == jog.UNKNOWN {
+		return fmt.Errorf("unknown level %q", level)
+	}
+	j := h.cfg.Jog
+	if name != "" {
+		j = j.Named(name)
+	}
+	o := &levelOverride{undo: j.WithTemporaryLevel(l)}
+	if dur != "" {
+		d, err := time.ParseDuration(dur)
+		if err != nil || d <= 0 {
+			o.undo()
+			return fmt.Errorf("invalid duration %q", dur)
+		}
+		o.timer = time.AfterFunc(d, func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			// Unless it was already reverted, or replaced
+			if h.undo[name] == o {
+				delete(h.undo, name)
+				o.undo()
+			}
+		})
+	}
+	h.undo[name] = o
+	return nil
+}
+
+// Flushes the named logger, or all of them
+func (h *Handler) flush(name string) error {
+	if name != "" {
+		l, err := h.logger(name)
+		if err != nil {
+			return err
+		}
+		f, ok := l.(flusher)
+		if !ok {
+			return fmt.Errorf("logger %q doesn't flush", name)
+		}
+		return f.Flush()
+	}
+	names := make([]string, 0, len(h.cfg.Loggers))
+	for n := range h.cfg.Loggers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if f, ok := h.cfg.Loggers[n].(flusher); ok {
+			if err := f.Flush(); err != nil {
+				return fmt.Errorf("flushing %q: %v", n, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (h *Handler) logger(name string) (jog.Logger, error) {
+	l, ok := h.cfg.Loggers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown logger %q", name)
+	}
+	return l, nil
+}
+
+// New returns a new Handler
+func New(c Config) *Handler {
+	return &Handler{cfg: c, undo: map[string]*levelOverride{}}
+}
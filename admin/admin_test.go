@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+	"code.minty.io/jog/loggers"
+)
+
+type flushLogger struct {
+	flushed int
+}
+
+func (l *flushLogger) Log(m interface{}) (int, error) { return 0, nil }
+func (l *flushLogger) Flush() error                   { l.flushed++; return nil }
+func (l *flushLogger) Len() int                       { return 3 }
+
+func post(h http.Handler, form url.Values) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("POST", "/debug/jog", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	fl := &flushLogger{}
+	sm := loggers.NewSampler(fl, loggers.SampleConfig{Rate: 10})
+	j := jog.New(fl, jog.WithLevel(jog.INFO))
+	h := New(Config{Jog: j, Loggers: map[string]jog.Logger{"buffer": fl, "sampler": sm}})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/debug/jog", nil))
+	var s Status
+	if err := json.NewDecoder(w.Body).Decode(&s); err != nil || s.Level != jog.INFO || *s.Loggers["buffer"].Queue != 3 || *s.Loggers["sampler"].SampleRate != 10 {
+		t.Error("Expected the status, got", s, err)
+	}
+
+	if w := post(h, url.Values{"action": {"level"}, "logger": {"admin"}, "level": {"debug"}}); w.Code != 200 {
+		t.Fatal("Expected the level to be set, got", w.Code, w.Body)
+	}
+	if !j.Named("admin").Enabled(jog.DEBUG) || j.Enabled(jog.DEBUG) {
+		t.Error("Expected DEBUG for the named logger")
+	}
+	post(h, url.Values{"action": {"level"}, "logger": {"admin"}})
+	if j.Named("admin").Enabled(jog.DEBUG) {
+		t.Error("Expected the level to revert")
+	}
+
+	post(h, url.Values{"action": {"flush"}})
+	post(h, url.Values{"action": {"sampling"}, "logger": {"sampler"}, "enabled": {"false"}})
+	if fl.flushed != 1 || sm.Enabled() {
+		t.Error("Expected a flush and sampling to be disabled", fl.flushed, sm.Enabled())
+	}
+
+	for _, form := range []url.Values{
+		{"action": {"level"}, "level": {"loud"}},
+		{"action": {"sampling"}, "logger": {"buffer"}, "enabled": {"true"}},
+		{"action": {"reboot"}},
+	} {
+		if w := post(h, form); w.Code != http.StatusBadRequest {
+			t.Error("Expected", form, "to fail, got", w.Code)
+		}
+	}
+}
+
+func TestHandlerLevelFor(t *testing.T) {
+	j := jog.New(&flushLogger{}, jog.WithLevel(jog.INFO))
+	h := New(Config{Jog: j})
+
+	if w := post(h, url.Values{"action": {"level"}, "logger": {"db"}, "level": {"debug"}, "for": {"1h"}}); w.Code != 200 {
+		t.Fatal("Expected the level to be set, got", w.Code, w.Body)
+	}
+	if !j.Named("db").Enabled(jog.DEBUG) {
+		t.Error("Expected DEBUG for the named logger")
+	}
+	if w := post(h, url.Values{"action": {"level"}, "logger": {"db"}}); w.Code != 200 {
+		t.Fatal("Expected the level to revert, got", w.Code, w.Body)
+	}
+	if j.Named("db").Enabled(jog.DEBUG) || len(h.undo) != 0 {
+		t.Error("Expected the level to revert early")
+	}
+
+	// Reverted by the timer
+	post(h, url.Values{"action": {"level"}, "logger": {"db"}, "level": {"debug"}, "for": {"10ms"}})
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(5 * time.Millisecond) {
+		h.mu.Lock()
+		n := len(h.undo)
+		h.mu.Unlock()
+		if n == 0 && !j.Named("db").Enabled(jog.DEBUG) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the level to revert after 10ms")
+		}
+	}
+}
+
+func TestHandlerLatency(t *testing.T) {
+	timed := loggers.NewTimed(&flushLogger{})
+	timed.Log(&jog.Message{Data: "hello"})
+	h := New(Config{Jog: jog.New(timed), Loggers: map[string]jog.Logger{"timed": timed}})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/debug/jog", nil))
+	var s Status
+	if err := json.NewDecoder(w.Body).Decode(&s); err != nil {
+		t.Fatal(err)
+	}
+	if l := s.Loggers["timed"].Latency; l == nil || l.Log.Count != 1 || l.Batch.Count != 0 {
+		t.Error("Expected the logger's latency, got", l)
+	}
+}
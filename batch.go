@@ -0,0 +1,126 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Batcher is a Logger that collects messages and passes them, in batches, to a
+// flush function. A batch is flushed once it's full, and on an interval.
+// It's intended for building Loggers around backends that prefer bulk writes.
+// Batches are flushed one at a time, in order.
+type Batcher struct {
+	flush   func([]*Message) error
+	size    int
+	mu      sync.Mutex
+	pending []*Message
+	flushMu sync.Mutex
+	latency Latency
+	stop    chan struct{}
+	done    chan struct{}
+	closed  sync.Once
+}
+
+// Log adds the message to the current batch, flushing the batch once it's full
+func (b *Batcher) Log(m interface{}) (int, error) {
+	msg, ok := m.(*Message)
+	if !ok {
+		return 0, fmt.Errorf("jog: unexpected message type %T", m)
+	}
+	b.mu.Lock()
+	b.pending = append(b.pending, msg)
+	full := len(b.pending) >= b.size
+	b.mu.Unlock()
+
+	if full {
+		return 0, b.Flush()
+	}
+	return 0, nil
+}
+
+// LogBatch adds the messages to the current batch, flushing the batch once it's full
+func (b *Batcher) LogBatch(msgs []*Message) (int, error) {
+	b.mu.Lock()
+	b.pending = append(b.pending, msgs...)
+	full := len(b.pending) >= b.size
+	b.mu.Unlock()
+
+	if full {
+		return 0, b.Flush()
+	}
+	return 0, nil
+}
+
+// Flush passes all pending messages to the flush function, waiting for a flush
+// already in progress
+func (b *Batcher) Flush() error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+	b.mu.Lock()
+	msgs := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+	if len(msgs) == 0 {
+		return nil
+	}
+	defer b.latency.Since(time.Now())
+	return b.flush(msgs)
+}
+
+// Latency returns the percentiles of the flush function's durations
+func (b *Batcher) Latency() LatencyStats {
+	return b.latency.Stats()
+}
+
+// Close stops the interval flushing and flushes any pending messages. Calling
+// it again only flushes.
+func (b *Batcher) Close() error {
+	b.closed.Do(func() {
+		close(b.stop)
+		<-b.done
+	})
+	return b.Flush()
+}
+
+func (b *Batcher) run(interval time.Duration) {
+	defer close(b.done)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			b.mu.Lock()
+			n := len(b.pending)
+			b.mu.Unlock()
+			if err := b.Flush(); err != nil {
+				Diagnose(ERROR, "batch_failed", map[string]interface{}{"error": err.Error(), "messages": n})
+			}
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// NewBatcher returns a new Batcher flushing batches of up to `size` messages, and
+// any pending messages every `interval`.
+func NewBatcher(size int, interval time.Duration, flush func([]*Message) error) *Batcher {
+	if size <= 0 {
+		size = 100
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	b := &Batcher{
+		flush: flush,
+		size:  size,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go b.run(interval)
+	return b
+}
@@ -0,0 +1,69 @@
+package jog
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBatcher(t *testing.T) {
+	var batches [][]*Message
+	b := NewBatcher(3, time.Hour, func(msgs []*Message) error {
+		batches = append(batches, msgs)
+		return nil
+	})
+
+	for i := 0; i < 7; i++ {
+		b.Log(&Message{Data: i})
+	}
+	if len(batches) != 2 {
+		t.Error("Expected 2 full batches, got", len(batches))
+	}
+	b.Close()
+	if len(batches) != 3 || len(batches[2]) != 1 {
+		t.Error("Expected remaining message to be flushed on Close, got", len(batches))
+	}
+}
+
+func TestLogBatch(t *testing.T) {
+	l := &countLogger{}
+	msgs := []*Message{{Data: 1}, {Data: 2}, {Data: 3}}
+	if _, err := LogBatch(l, msgs); err != nil || l.count != 3 {
+		t.Error("Expected each message to be logged, got", l.count, err)
+	}
+
+	var flushed [][]*Message
+	b := NewBatcher(10, time.Hour, func(msgs []*Message) error {
+		flushed = append(flushed, msgs)
+		return nil
+	})
+	LogBatch(b, msgs)
+	b.Close()
+	if len(flushed) != 1 || len(flushed[0]) != 3 {
+		t.Error("Expected a single batch of 3, got", flushed)
+	}
+}
+
+func TestBatcherIntervalFailure(t *testing.T) {
+	d := make(chanLogger, 1)
+	SetDiagnostics(d)
+	defer SetDiagnostics(nil)
+
+	b := NewBatcher(10, 10*time.Millisecond, func(msgs []*Message) error {
+		return errors.New("down")
+	})
+	b.Log(&Message{Data: 1})
+	select {
+	case m := <-d:
+		if f := m.Data.(map[string]interface{}); f["event"] != "batch_failed" || f["error"] != "down" || f["messages"] != 1 {
+			t.Errorf("Unexpected diagnostic %+v", f)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the failed interval flush to be reported")
+	}
+
+	b.Close()
+	if err := b.Close(); err != nil {
+		t.Error("Expected closing twice to be harmless, got", err)
+	}
+}
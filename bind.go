@@ -0,0 +1,88 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Contexts bound to goroutines, by goroutine ID
+var bindings = struct {
+	sync.RWMutex
+	n    int32
+	ctxs map[uint64]context.Context
+}{ctxs: map[uint64]context.Context{}}
+
+// Bind binds the context to the calling goroutine until `unbind` is called, so
+// every message logged on it carries the request ID, trace and fields of the
+// context (see ContextWithFields), as with LogContext, without passing the
+// context down through every call. It's meant for migrating code that can't
+// easily be changed to do so; while any context is bound, each message costs a
+// lookup of the goroutine. Contexts aren't inherited by new goroutines, and
+// bindings nest, unbinding restores the previous one.
+//
+//	unbind := jog.Bind(jog.ContextWithFields(ctx, map[string]interface{}{"job_id": id}))
+//	defer unbind()
+func Bind(ctx context.Context) (unbind func()) {
+	id := goid()
+	bindings.Lock()
+	prev, had := bindings.ctxs[id]
+	bindings.ctxs[id] = ctx
+	atomic.StoreInt32(&bindings.n, int32(len(bindings.ctxs)))
+	bindings.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			bindings.Lock()
+			if had {
+				bindings.ctxs[id] = prev
+			} else {
+				delete(bindings.ctxs, id)
+			}
+			atomic.StoreInt32(&bindings.n, int32(len(bindings.ctxs)))
+			bindings.Unlock()
+		})
+	}
+}
+
+// Bound returns the context bound to the calling goroutine, see Bind, or
+// context.Background
+func Bound() context.Context {
+	if ctx, ok := bound(); ok {
+		return ctx
+	}
+	return context.Background()
+}
+
+// Returns the context bound to the calling goroutine, if any
+func bound() (context.Context, bool) {
+	if atomic.LoadInt32(&bindings.n) == 0 {
+		return nil, false
+	}
+	id := goid()
+	bindings.RLock()
+	ctx, ok := bindings.ctxs[id]
+	bindings.RUnlock()
+	return ctx, ok
+}
+
+// Returns the ID of the calling goroutine, from the header of its stack trace,
+// eg. `goroutine 18 [running]:`
+func goid() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
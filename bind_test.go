@@ -0,0 +1,46 @@
+package jog
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestBind(t *testing.T) {
+	l := &testLogger{}
+	j := New(l)
+	ctx := ContextWithFields(ContextWithRequestID(context.Background(), "abc"), map[string]interface{}{"job_id": 7})
+
+	unbind := Bind(ctx)
+	j.Info("bound")
+	if l.message.Meta["request_id"] != "abc" || l.message.Meta["job_id"] != 7 {
+		t.Error("Expected the bound context's values, got", l.message.Meta)
+	}
+
+	// Other goroutines aren't bound
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if Bound() != context.Background() {
+			t.Error("Expected other goroutines to be unbound")
+		}
+	}()
+	wg.Wait()
+
+	inner := Bind(ContextWithFields(ctx, map[string]interface{}{"step": "load"}))
+	j.Info("nested")
+	if l.message.Meta["step"] != "load" || l.message.Meta["job_id"] != 7 {
+		t.Error("Expected the nested fields, got", l.message.Meta)
+	}
+	inner()
+	if Bound() != ctx {
+		t.Error("Expected unbinding to restore the outer context")
+	}
+
+	unbind()
+	j.Info("unbound")
+	if l.message.Meta != nil {
+		t.Error("Expected no values once unbound, got", l.message.Meta)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import "runtime/debug"
+
+// BuildInfo returns the module version, VCS revision, and dirty flag of the running
+// binary, as recorded by the Go toolchain. It returns nil when the binary has no
+// build information.
+func BuildInfo() map[string]interface{} {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	info := map[string]interface{}{
+		"module":  bi.Main.Path,
+		"version": bi.Main.Version,
+		"go":      bi.GoVersion,
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info["revision"] = s.Value
+		case "vcs.time":
+			info["revision_time"] = s.Value
+		case "vcs.modified":
+			info["dirty"] = s.Value == "true"
+		}
+	}
+	return info
+}
+
+// WithBuildInfo adds the binary's build information, see BuildInfo, under a `build`
+// key to the Meta of every message. It's read once, when the option is applied.
+func WithBuildInfo() Option {
+	info := BuildInfo()
+	if info == nil {
+		return func(*Jog) {}
+	}
+	return WithMeta(map[string]interface{}{"build": info})
+}
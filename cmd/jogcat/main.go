@@ -0,0 +1,74 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command jogcat prints jog log files, decrypting them when needed.
+//
+//	jogcat [-decrypt [-mixed]] [-key-env JOG_KEY] [file ...]
+//
+// With no files, it reads from stdin. Lines that aren't encrypted are an error,
+// unless -mixed is given.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"code.minty.io/jog/loggers"
+)
+
+func main() {
+	decrypt := flag.Bool("decrypt", false, "decrypt records encrypted by the file logger")
+	mixed := flag.Bool("mixed", false, "copy lines that aren't encrypted as is, they aren't authenticated")
+	keyEnv := flag.String("key-env", "JOG_KEY", "environment variable holding the hex, or base64, encoded key")
+	flag.Parse()
+
+	var key []byte
+	if *decrypt {
+		var err error
+		if key, err = loggers.KeyFromEnv(*keyEnv)(); err != nil {
+			fatal(err)
+		}
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	cat := func(r io.Reader) error {
+		if *decrypt && *mixed {
+			return loggers.DecryptMixed(key, r, w)
+		} else if *decrypt {
+			return loggers.Decrypt(key, r, w)
+		}
+		_, err := io.Copy(w, r)
+		return err
+	}
+
+	if flag.NArg() == 0 {
+		if err := cat(os.Stdin); err != nil {
+			w.Flush()
+			fatal(err)
+		}
+		return
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			w.Flush()
+			fatal(err)
+		}
+		err = cat(f)
+		f.Close()
+		if err != nil {
+			w.Flush()
+			fatal(fmt.Errorf("%s: %w", name, err))
+		}
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "jogcat:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,216 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ColorMode decides when console output is colored
+type ColorMode int
+
+const (
+	// ColorAuto colors output written to a terminal, unless NO_COLOR is set
+	ColorAuto ColorMode = iota
+	// ColorAlways colors output, eg. when piping to `less -R`
+	ColorAlways
+	// ColorNever writes plain text
+	ColorNever
+)
+
+// DefaultColors are the ANSI SGR parameters used for each level
+var DefaultColors = map[Level]string{
+	DEBUG:    "90",
+	INFO:     "36",
+	WARNING:  "33",
+	ERROR:    "31",
+	CRITICAL: "1;31",
+}
+
+// ConsoleConfig holds the settings of a Console logger
+type ConsoleConfig struct {
+	Color ColorMode
+	// Theme styles the output, defaulting to the one named by the JOG_THEME
+	// environment variable (see Themes), or else the DefaultTheme
+	Theme *Theme
+	// Colors overrides the ANSI SGR parameters of levels, eg. `{jog.INFO: "32"}`
+	Colors map[Level]string
+	// Multiline renders each field on its own line, rather than as `key=value` pairs
+	Multiline bool
+	// TimeFormat of the timestamp (defaults to `15:04:05.000`)
+	TimeFormat string
+}
+
+// Console is a Logger writing human readable messages, intended for development
+type Console struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc Encoder
+}
+
+// Log renders the message and writes it
+func (c *Console) Log(m interface{}) (int, error) {
+	msg, ok := m.(*Message)
+	if !ok {
+		msg = &Message{Data: m, Level: UNKNOWN}
+	}
+	b, err := c.enc.Encode(msg)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.w.Write(b)
+}
+
+// NewConsole returns a Console logger writing to `w`, eg. os.Stderr
+func NewConsole(w io.Writer, c ConsoleConfig) *Console {
+	color := c.Color == ColorAlways || (c.Color == ColorAuto && isTerminal(w))
+	return &Console{w: w, enc: NewConsoleEncoder(c, color)}
+}
+
+// Whether `w` is a terminal that accepts colors
+func isTerminal(w io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// NewConsoleEncoder returns the Encoder used by Console, with or without colors
+func NewConsoleEncoder(c ConsoleConfig, color bool) Encoder {
+	if c.TimeFormat == "" {
+		c.TimeFormat = "15:04:05.000"
+	}
+	theme := envTheme()
+	if c.Theme != nil {
+		theme = *c.Theme
+	}
+	colors := make(map[Level]string, len(theme.Colors))
+	for l, v := range theme.Colors {
+		colors[l] = v
+	}
+	for l, v := range c.Colors {
+		colors[l] = v
+	}
+	muted := theme.Muted
+	paint := func(b *bytes.Buffer, sgr, s string) {
+		if color && sgr != "" {
+			b.WriteString("\x1b[" + sgr + "m" + s + "\x1b[0m")
+		} else {
+			b.WriteString(s)
+		}
+	}
+
+	return EncoderFunc(func(m *Message) ([]byte, error) {
+		msg, fields, err := consoleFields(m)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, sortedFields(m.Meta)...)
+		if len(m.Tags) > 0 {
+			fields = append(fields, [2]string{"tags", strings.Join(m.Tags, ",")})
+		}
+		var b bytes.Buffer
+		paint(&b, muted, m.Time.Local().Format(c.TimeFormat))
+		b.WriteByte(' ')
+		level := fmt.Sprintf("%-8s", strings.ToUpper(string(m.Level)))
+		if mark := theme.Markers[m.Level]; mark != "" {
+			level = mark + " " + level
+		}
+		paint(&b, colors[m.Level], level)
+		paint(&b, muted, filepath.Base(m.File)+":"+strconv.Itoa(m.Line))
+		if msg != "" {
+			b.WriteString(" " + msg)
+		}
+		for _, f := range fields {
+			if c.Multiline {
+				b.WriteString("\n    ")
+				paint(&b, colors[m.Level], f[0])
+				b.WriteString(": " + f[1])
+			} else {
+				b.WriteByte(' ')
+				paint(&b, colors[m.Level], f[0])
+				b.WriteString("=" + f[1])
+			}
+		}
+		b.WriteByte('\n')
+		if m.Stack != "" {
+			paint(&b, muted, m.Stack)
+		} else if len(m.Frames) > 0 {
+			paint(&b, muted, formatFrames(m.Frames))
+		}
+		if theme.ASCII {
+			return asciiOnly(b.Bytes()), nil
+		}
+		return b.Bytes(), nil
+	})
+}
+
+// Splits Data into its message, and its other fields sorted by key
+func consoleFields(msg *Message) (string, [][2]string, error) {
+	b, err := msg.DataJSON()
+	if err != nil {
+		return "", nil, err
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return "", nil, err
+	}
+	if s, ok := v.(string); ok {
+		return s, nil, nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return string(b), nil, nil
+	}
+
+	text, _ := m["message"].(string)
+	if text != "" {
+		delete(m, "message")
+	}
+	return text, sortedFields(m), nil
+}
+
+// Formats the fields, sorted by key, quoting strings when needed
+func sortedFields(m map[string]interface{}) [][2]string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([][2]string, 0, len(keys))
+	for _, k := range keys {
+		var s string
+		switch v := m[k].(type) {
+		case string:
+			s = v
+			if s == "" || strings.ContainsAny(s, " =\"\n\t") {
+				s = strconv.Quote(s)
+			}
+		default:
+			b, _ := json.Marshal(v)
+			s = string(b)
+		}
+		fields = append(fields, [2]string{k, s})
+	}
+	return fields
+}
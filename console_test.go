@@ -0,0 +1,70 @@
+package jog
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestConsole(t *testing.T) {
+	m := &Message{
+		Level: ERROR,
+		File:  "/src/app/main.go",
+		Line:  7,
+		Time:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local),
+		Data:  map[string]interface{}{"message": "failed", "user": "bob smith", "n": 3},
+	}
+
+	var buf bytes.Buffer
+	c := NewConsole(&buf, ConsoleConfig{})
+	c.Log(m)
+	if exp := "03:04:05.000 ERROR   main.go:7 failed n=3 user=\"bob smith\"\n"; buf.String() != exp {
+		t.Errorf("Expected %q, got %q", exp, buf.String())
+	}
+
+	buf.Reset()
+	c = NewConsole(&buf, ConsoleConfig{Color: ColorAlways, Colors: map[Level]string{ERROR: "35"}, Multiline: true, TimeFormat: "15:04"})
+	c.Log(m)
+	exp := "\x1b[90m03:04\x1b[0m \x1b[35mERROR   \x1b[0m\x1b[90mmain.go:7\x1b[0m failed\n    \x1b[35mn\x1b[0m: 3\n    \x1b[35muser\x1b[0m: \"bob smith\"\n"
+	if buf.String() != exp {
+		t.Errorf("Expected %q, got %q", exp, buf.String())
+	}
+
+	// Meta and Tags follow the fields of Data
+	buf.Reset()
+	m.SetMeta("request_id", "abc")
+	m.Tag("db")
+	NewConsole(&buf, ConsoleConfig{}).Log(m)
+	if exp := "03:04:05.000 ERROR   main.go:7 failed n=3 user=\"bob smith\" request_id=abc tags=db\n"; buf.String() != exp {
+		t.Errorf("Expected %q, got %q", exp, buf.String())
+	}
+}
+
+func TestConsoleNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if isTerminal(os.Stdout) {
+		t.Error("Expected NO_COLOR to disable colors")
+	}
+}
+
+func TestConsoleTheme(t *testing.T) {
+	m := &Message{Level: WARNING, File: "main.go", Line: 7, Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local), Data: "café"}
+
+	var buf bytes.Buffer
+	NewConsole(&buf, ConsoleConfig{Theme: &ASCIITheme}).Log(m)
+	if exp := "03:04:05.000 ! WARNING main.go:7 caf\\u00e9\n"; buf.String() != exp {
+		t.Errorf("Expected %q, got %q", exp, buf.String())
+	}
+
+	buf.Reset()
+	t.Setenv(ThemeEnv, "colorblind")
+	NewConsole(&buf, ConsoleConfig{Color: ColorAlways, TimeFormat: "15:04"}).Log(m)
+	if exp := "\x1b[38;5;246m03:04\x1b[0m \x1b[38;5;214m▲ WARNING \x1b[0m\x1b[38;5;246mmain.go:7\x1b[0m café\n"; buf.String() != exp {
+		t.Errorf("Expected %q, got %q", exp, buf.String())
+	}
+
+	if _, err := LookupTheme("neon"); err == nil {
+		t.Error("Expected an unknown theme to fail")
+	}
+}
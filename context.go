@@ -0,0 +1,115 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	traceKey
+	fieldsKey
+)
+
+// Trace identifies the distributed trace, and span, a message is logged within
+type Trace struct {
+	TraceID string
+	SpanID  string
+	Flags   string
+	// State is the vendor specific `tracestate`, passed along as is
+	State string
+}
+
+// NewRequestID returns a new random (version 4) UUID
+func NewRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// ContextWithRequestID returns a copy of the context carrying the request ID
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID carried by the context, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// ContextWithTrace returns a copy of the context carrying the trace
+func ContextWithTrace(ctx context.Context, t Trace) context.Context {
+	return context.WithValue(ctx, traceKey, t)
+}
+
+// TraceFrom returns the trace carried by the context, if any
+func TraceFrom(ctx context.Context) (Trace, bool) {
+	t, ok := ctx.Value(traceKey).(Trace)
+	return t, ok
+}
+
+// ContextWithFields returns a copy of the context carrying the fields, along with
+// those it already carries, eg. a job ID to be added to every message logged for it
+func ContextWithFields(ctx context.Context, fields map[string]interface{}) context.Context {
+	prev := FieldsFrom(ctx)
+	f := make(map[string]interface{}, len(prev)+len(fields))
+	for k, v := range prev {
+		f[k] = v
+	}
+	for k, v := range fields {
+		f[k] = v
+	}
+	return context.WithValue(ctx, fieldsKey, f)
+}
+
+// FieldsFrom returns the fields carried by the context, which mustn't be modified
+func FieldsFrom(ctx context.Context) map[string]interface{} {
+	f, _ := ctx.Value(fieldsKey).(map[string]interface{})
+	return f
+}
+
+// Context returns the context the message was logged with, see LogContext, or
+// context.Background
+func (m *Message) Context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
+// Adds the values carried by the context to the message's Meta
+func fromContext(ctx context.Context, m *Message) {
+	m.MergeMeta(FieldsFrom(ctx))
+	if id := RequestID(ctx); id != "" {
+		m.SetMeta("request_id", id)
+	}
+	if t, ok := TraceFrom(ctx); ok {
+		m.SetMeta("trace_id", t.TraceID)
+		m.SetMeta("span_id", t.SpanID)
+	}
+}
+
+// LogContext logs with a given Level and object, including the request ID,
+// trace and fields carried by the context. The context is available to Loggers
+// through Message.Context, eg. for the deadline of sending the message.
+func (j *Jog) LogContext(ctx context.Context, l Level, o interface{}) (int, error) {
+	if !j.Enabled(l) {
+		return 0, nil
+	}
+	m := j.newMessage(l, o, j.Depth-1)
+	fromContext(ctx, m)
+	m.ctx = ctx
+	return j.write(m)
+}
@@ -0,0 +1,30 @@
+package jog
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	id := NewRequestID()
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Error("Expected a v4 UUID, got", id)
+	}
+	if id == NewRequestID() {
+		t.Error("Expected unique request IDs")
+	}
+
+	l := &testLogger{}
+	j := New(l)
+	ctx := ContextWithRequestID(context.Background(), id)
+	j.LogContext(ctx, WARNING, "slow")
+
+	if l.message.Meta["request_id"] != id || l.message.Data != "slow" {
+		t.Error("Expected request ID in Meta, got", l.message.Meta)
+	}
+	if !strings.HasSuffix(l.message.File, "context_test.go") {
+		t.Error("Expected caller to be the test, got", l.message.File)
+	}
+}
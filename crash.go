@@ -0,0 +1,110 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// Recent is implemented by Loggers keeping the latest messages, such as
+// loggers.RingBuffer, see WithCrashReports
+type Recent interface {
+	Dump() []*Message
+}
+
+// CrashReport is the Data of a `crash_report` message, logged by Fatal and for
+// panics, see WithCrashReports. The message's Stack (or Frames) is of where the
+// crash happened.
+type CrashReport struct {
+	Event   string                 `json:"event"`
+	Reason  string                 `json:"reason"`
+	Panic   string                 `json:"panic,omitempty"`
+	Recent  []*Message             `json:"recent,omitempty"`
+	Build   map[string]interface{} `json:"build,omitempty"`
+	Runtime CrashRuntime           `json:"runtime"`
+}
+
+// CrashRuntime holds the runtime statistics of a CrashReport
+type CrashRuntime struct {
+	Go         string     `json:"go"`
+	OS         string     `json:"os"`
+	Arch       string     `json:"arch"`
+	CPUs       int        `json:"cpus"`
+	MaxProcs   int        `json:"gomaxprocs"`
+	Goroutines int        `json:"goroutines"`
+	Memory     DumpMemory `json:"memory"`
+	Uptime     float64    `json:"uptime_s"`
+}
+
+// WithCrashReports logs a CRITICAL `crash_report` message, after the message of
+// a Fatal, or a panic (see RunMain and Recovered), holding everything a
+// post-mortem needs in one event: the stack, the build information, runtime
+// statistics, and the messages kept by `recent`, eg. a loggers.RingBuffer teed
+// in front of the Logger, which may be nil.
+func WithCrashReports(recent Recent) Option {
+	return func(j *Jog) {
+		j.crashes = true
+		j.recent = recent
+	}
+}
+
+// Recovered logs a value recovered from a panic as CRITICAL, with the stack of
+// where it was raised, followed by a crash report when enabled, see
+// WithCrashReports. It must be called directly by the deferred function, eg.
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			j.Recovered(r)
+//		}
+//	}()
+func (j *Jog) Recovered(r interface{}) {
+	// The message is from where the panic was raised, above runtime.gopanic
+	m := j.newMessage(CRITICAL, map[string]interface{}{"message": "panic", "panic": fmt.Sprint(r)}, j.Depth+1)
+	if f := frames(3, j.stackCfg); j.stackCfg.Structured {
+		m.Frames = f
+	} else {
+		m.Stack = formatFrames(f)
+	}
+	j.write(m)
+	j.crash("panic", r, j.Depth+1)
+}
+
+// Logs a crash report, when enabled, `depth` being the number of frames above
+// the caller of `crash`, as with output
+func (j *Jog) crash(reason string, p interface{}, depth int) {
+	if !j.crashes {
+		return
+	}
+	c := CrashReport{
+		Event:  "crash_report",
+		Reason: reason,
+		Build:  BuildInfo(),
+		Runtime: CrashRuntime{
+			Go:         runtime.Version(),
+			OS:         runtime.GOOS,
+			Arch:       runtime.GOARCH,
+			CPUs:       runtime.NumCPU(),
+			MaxProcs:   runtime.GOMAXPROCS(0),
+			Goroutines: runtime.NumGoroutine(),
+			Memory:     memory(),
+			Uptime:     time.Since(started).Seconds(),
+		},
+	}
+	if p != nil {
+		c.Panic = fmt.Sprint(p)
+	}
+	if j.recent != nil {
+		c.Recent = j.recent.Dump()
+	}
+	m := j.newMessage(CRITICAL, c, depth+1)
+	if f := frames(depth+1, j.stackCfg); j.stackCfg.Structured {
+		m.Frames = f
+	} else {
+		m.Stack = formatFrames(f)
+	}
+	j.write(m)
+}
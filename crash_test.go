@@ -0,0 +1,49 @@
+package jog
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+type recent []*Message
+
+func (r recent) Dump() []*Message {
+	return r
+}
+
+func TestCrashReports(t *testing.T) {
+	osExit = func(int) {}
+	defer func() { osExit = os.Exit }()
+
+	l := &anyLogger{}
+	prev := &Message{Data: "before", Level: INFO}
+	j := New(l, WithCrashReports(recent{prev}))
+	j.Fatal("kaboom")
+	if len(l.logged) != 3 {
+		t.Fatal("Expected the message, crash report and exit to be logged, got", len(l.logged))
+	}
+	m := l.logged[1].(*Message)
+	c, ok := m.Data.(CrashReport)
+	if !ok || m.Level != CRITICAL || c.Event != "crash_report" || c.Reason != "fatal" {
+		t.Fatalf("Unexpected crash report %+v", m)
+	}
+	if len(c.Recent) != 1 || c.Recent[0] != prev || c.Runtime.Goroutines == 0 || c.Runtime.Memory.Sys == 0 {
+		t.Errorf("Unexpected crash report data %+v", c)
+	}
+	if !strings.HasSuffix(m.File, "crash_test.go") || !strings.HasPrefix(m.Stack, "code.minty.io/jog.TestCrashReports") {
+		t.Errorf("Expected the report to be from the caller of Fatal, got %s\n%s", m.File, m.Stack)
+	}
+
+	l.logged = nil
+	RunMain(j, func() int {
+		panic("oops")
+	})
+	if len(l.logged) != 3 {
+		t.Fatal("Expected the panic, crash report and exit to be logged, got", len(l.logged))
+	}
+	m = l.logged[1].(*Message)
+	if c := m.Data.(CrashReport); c.Reason != "panic" || c.Panic != "oops" || !strings.HasSuffix(m.File, "crash_test.go") {
+		t.Errorf("Unexpected crash report %s %+v", m.File, c)
+	}
+}
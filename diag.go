@@ -0,0 +1,65 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+var diagnostics struct {
+	sync.RWMutex
+	logger Logger
+}
+
+// SetDiagnostics subscribes the Logger to jog's own events, such as failed logs,
+// reconnects, retries, and dropped messages, as Messages whose Data holds an
+// `event` and its fields, and whose Meta `logger` is `jog`. With no Logger,
+// events at WARNING and above are written to stderr.
+func SetDiagnostics(l Logger) {
+	diagnostics.Lock()
+	diagnostics.logger = l
+	diagnostics.Unlock()
+}
+
+// Diagnose reports an internal event, eg. from a Logger implementation, see
+// SetDiagnostics
+func Diagnose(l Level, event string, fields map[string]interface{}) {
+	if diagnose(l, event, fields) || !l.AtLeast(WARNING) {
+		return
+	}
+	b, _ := json.Marshal(fields)
+	fmt.Fprintf(os.Stderr, "[JOG %s] %s %s\n", l, event, b)
+}
+
+// Sends the event to the diagnostics Logger, reporting whether there is one
+func diagnose(l Level, event string, fields map[string]interface{}) bool {
+	diagnostics.RLock()
+	logger := diagnostics.logger
+	diagnostics.RUnlock()
+	if logger == nil {
+		return false
+	}
+
+	d := make(map[string]interface{}, len(fields)+1)
+	for k, v := range fields {
+		d[k] = v
+	}
+	d["event"] = event
+	m := &Message{
+		Data:    d,
+		Level:   l,
+		Time:    time.Now().UTC(),
+		Version: SchemaVersion,
+		Meta:    map[string]interface{}{"logger": "jog"},
+	}
+	if _, err := logger.Log(m); err != nil {
+		fmt.Fprintf(os.Stderr, "[LOG FAILURE] - (Diagnostics) %s -> \n%s\n", err, m)
+	}
+	return true
+}
@@ -0,0 +1,24 @@
+package jog
+
+import (
+	"errors"
+	"testing"
+)
+
+type failingLogger struct{}
+
+func (failingLogger) Log(m interface{}) (int, error) {
+	return 0, errors.New("down")
+}
+
+func TestDiagnostics(t *testing.T) {
+	l := &testLogger{}
+	SetDiagnostics(l)
+	defer SetDiagnostics(nil)
+
+	New(failingLogger{}).Info("lost")
+	d, ok := l.message.Data.(map[string]interface{})
+	if !ok || d["event"] != "log_failure" || d["error"] != "down" || l.message.Level != ERROR || l.message.Meta["logger"] != "jog" {
+		t.Errorf("Unexpected diagnostic %v %v", l.message.Data, l.message.Meta)
+	}
+}
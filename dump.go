@@ -0,0 +1,100 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"os"
+	"os/signal"
+	"runtime"
+	"time"
+)
+
+// Dump is the Data of a diagnostic dump, see DumpOnSignal
+type Dump struct {
+	Reason     string     `json:"reason"`
+	Goroutines int        `json:"goroutines"`
+	Stacks     string     `json:"stacks"`
+	Memory     DumpMemory `json:"memory"`
+	Uptime     float64    `json:"uptime_s"`
+}
+
+// DumpMemory holds the memory statistics of a Dump
+type DumpMemory struct {
+	Alloc       uint64  `json:"alloc"`
+	TotalAlloc  uint64  `json:"total_alloc"`
+	Sys         uint64  `json:"sys"`
+	HeapInuse   uint64  `json:"heap_inuse"`
+	HeapObjects uint64  `json:"heap_objects"`
+	NumGC       uint32  `json:"num_gc"`
+	PauseTotal  float64 `json:"gc_pause_total_ms"`
+}
+
+var started = time.Now()
+
+func newDump(reason string) Dump {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+	return Dump{
+		Reason:     reason,
+		Goroutines: runtime.NumGoroutine(),
+		Stacks:     string(buf),
+		Memory:     memory(),
+		Uptime:     time.Since(started).Seconds(),
+	}
+}
+
+func memory() DumpMemory {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return DumpMemory{
+		Alloc:       ms.Alloc,
+		TotalAlloc:  ms.TotalAlloc,
+		Sys:         ms.Sys,
+		HeapInuse:   ms.HeapInuse,
+		HeapObjects: ms.HeapObjects,
+		NumGC:       ms.NumGC,
+		PauseTotal:  float64(ms.PauseTotalNs) / float64(time.Millisecond),
+	}
+}
+
+// Dump logs a CRITICAL message holding the stacks of all goroutines, and memory
+// statistics, see Dump
+func (j *Jog) Dump(reason string) error {
+	_, err := j.output(j.Depth-1, CRITICAL, newDump(reason))
+	return err
+}
+
+// DumpOnSignal logs a Dump whenever one of the signals is received, SIGQUIT and
+// SIGUSR2 when none are given (where supported), instead of the runtime printing
+// stacks to stderr and exiting on SIGQUIT. Calling stop restores the default handling.
+func (j *Jog) DumpOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = dumpSignals
+	}
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, sigs...)
+	go func() {
+		for {
+			select {
+			case s := <-c:
+				j.Dump("signal: " + s.String())
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows || plan9
+// +build windows plan9
+
+package jog
+
+import "os"
+
+// There are no dump signals, so they must be passed to DumpOnSignal
+var dumpSignals []os.Signal
@@ -0,0 +1,33 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package jog
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDumpOnSignal(t *testing.T) {
+	l := make(chanLogger, 1)
+	j := New(l)
+	stop := j.DumpOnSignal(syscall.SIGUSR2)
+	defer stop()
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	var m *Message
+	select {
+	case m = <-l:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a dump to be logged")
+	}
+	d, ok := m.Data.(Dump)
+	if !ok || m.Level != CRITICAL || d.Reason != "signal: user defined signal 2" {
+		t.Fatalf("Unexpected message %s %v", m.Level, m.Data)
+	}
+	if !strings.Contains(d.Stacks, "TestDumpOnSignal") || d.Goroutines == 0 || d.Memory.Sys == 0 {
+		t.Error("Expected stacks and memory stats, got", d.Goroutines, d.Memory)
+	}
+}
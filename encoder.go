@@ -0,0 +1,78 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Encoder encodes a Message into the bytes written by stream based Loggers
+type Encoder interface {
+	Encode(m *Message) ([]byte, error)
+}
+
+// EncoderFunc is an adapter allowing an ordinary function to be used as an Encoder
+type EncoderFunc func(m *Message) ([]byte, error)
+
+// Encode calls f(m)
+func (f EncoderFunc) Encode(m *Message) ([]byte, error) {
+	return f(m)
+}
+
+// JSON encodes messages as newline delimited JSON
+var JSON Encoder = EncoderFunc(func(m *Message) ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+})
+
+// TimeFormat converts a Message timestamp into its wire form, as expected by a backend
+type TimeFormat func(t time.Time) interface{}
+
+var (
+	// RFC3339Nano is a UTC RFC 3339 string with nanoseconds, eg. `2013-06-01T00:00:00.123456789Z`
+	RFC3339Nano TimeFormat = func(t time.Time) interface{} {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	// UnixNanoString is a string of nanoseconds since the epoch, as used by Loki
+	UnixNanoString TimeFormat = func(t time.Time) interface{} {
+		return strconv.FormatInt(t.UnixNano(), 10)
+	}
+	// EpochSeconds is a number of seconds since the epoch, with milliseconds, as used by Splunk HEC
+	EpochSeconds TimeFormat = func(t time.Time) interface{} {
+		return json.Number(strconv.FormatFloat(float64(t.UnixNano()/1e6)/1e3, 'f', 3, 64))
+	}
+	// EpochMillis is a number of milliseconds since the epoch, as used by CloudWatch Logs
+	EpochMillis TimeFormat = func(t time.Time) interface{} {
+		return t.UnixNano() / 1e6
+	}
+)
+
+// Message without its methods, so it can be embedded and have fields overridden
+type message Message
+
+// NewJSON returns an Encoder producing newline delimited JSON, like JSON, but
+// with the timestamp in the given format
+func NewJSON(f TimeFormat) Encoder {
+	return EncoderFunc(func(m *Message) ([]byte, error) {
+		d, err := m.DataJSON()
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(struct {
+			Data json.RawMessage `json:"data"`
+			*message
+			Time interface{} `json:"timestamp"`
+		}{d, (*message)(m), f(m.Time)})
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	})
+}
@@ -0,0 +1,49 @@
+package jog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestJSONEncoder(t *testing.T) {
+	m := &Message{Data: "hi", Level: INFO, File: "a.go", Line: 1, Time: time.Date(2013, 6, 1, 0, 0, 0, 0, time.UTC)}
+	b, err := JSON.Encode(m)
+	expected := `{"data":"hi","level":"info","file":"a.go","line":1,"timestamp":"2013-06-01T00:00:00Z"}` + "\n"
+	if err != nil || string(b) != expected {
+		t.Error("Expected", expected, "got", string(b), err)
+	}
+}
+
+func TestMsgPackEncoder(t *testing.T) {
+	m := &Message{Data: map[string]interface{}{"n": -200, "ok": true}, Level: ERROR, File: "a.go", Line: 300}
+	b, err := MsgPack.Encode(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Map of 5 keys, sorted, starting with "data" -> {"n": -200, "ok": true}
+	prefix := []byte{0x85, 0xa4, 'd', 'a', 't', 'a', 0x82, 0xa1, 'n', 0xd1, 0xff, 0x38, 0xa2, 'o', 'k', 0xc3}
+	if !bytes.HasPrefix(b, prefix) {
+		t.Errorf("Expected prefix % x got % x", prefix, b)
+	}
+	if !bytes.Contains(b, []byte{0xa4, 'l', 'i', 'n', 'e', 0xd1, 0x01, 0x2c}) {
+		t.Errorf("Expected line 300 as int16, got % x", b)
+	}
+}
+
+func TestTimeFormats(t *testing.T) {
+	m := &Message{Data: "hi", Level: INFO, File: "a.go", Line: 1, Time: time.Date(2013, 6, 1, 0, 0, 0, 123456789, time.UTC)}
+	for f, exp := range map[*TimeFormat]string{
+		&RFC3339Nano:    `"2013-06-01T00:00:00.123456789Z"`,
+		&UnixNanoString: `"1370044800123456789"`,
+		&EpochSeconds:   `1370044800.123`,
+		&EpochMillis:    `1370044800123`,
+	} {
+		b, err := NewJSON(*f).Encode(m)
+		expected := `{"data":"hi","level":"info","file":"a.go","line":1,"timestamp":` + exp + "}\n"
+		if err != nil || string(b) != expected {
+			t.Error("Expected", expected, "got", string(b), err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Replaced by tests
+var osExit = os.Exit
+
+// Exit logs a process exit message, with the exit code and uptime, closes the
+// loggers as HandleShutdown does, and exits the program with the code.
+// Deferred calls don't run, as with os.Exit.
+func (j *Jog) Exit(code int) {
+	j.exit(code, j.Depth-1)
+}
+
+// Fatal logs the object as CRITICAL, followed by a crash report when enabled
+// (see WithCrashReports), then exits with code 1, see Exit
+func (j *Jog) Fatal(o interface{}) {
+	j.output(j.Depth-1, CRITICAL, o)
+	j.crash("fatal", nil, j.Depth-1)
+	j.exit(1, j.Depth-1)
+}
+
+func (j *Jog) exit(code, depth int) {
+	l := INFO
+	if code != 0 {
+		l = ERROR
+	}
+	j.output(depth+1, l, map[string]interface{}{
+		"message":   "process exit",
+		"exit_code": code,
+		"uptime_s":  time.Since(started).Seconds(),
+	})
+	closeLoggers(j, fmt.Sprintf("exit: %d", code), ShutdownTimeout)
+	osExit(code)
+}
+
+// RunMain runs the program's main function, exiting with the code it returns,
+// after logging and flushing, see Exit. A panic is logged, see Recovered, and
+// exits with code 2.
+//
+//	func main() {
+//		jog.RunMain(j, run)
+//	}
+func RunMain(j *Jog, main func() int) {
+	code := func() (code int) {
+		defer func() {
+			if r := recover(); r != nil {
+				code = 2
+				j.Recovered(r)
+			}
+		}()
+		return main()
+	}()
+	j.exit(code, j.Depth-1)
+}
@@ -0,0 +1,31 @@
+package jog
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunMain(t *testing.T) {
+	var code int
+	osExit = func(c int) { code = c }
+	defer func() { osExit = os.Exit }()
+
+	l := &anyLogger{}
+	c := &closeLogger{}
+	Register(c)
+	RunMain(New(l), func() int {
+		panic("kaboom")
+	})
+
+	if code != 2 || !c.closed || len(l.logged) != 2 {
+		t.Fatal("Expected the panic and exit to be logged, and the loggers closed, got", code, c.closed, len(l.logged))
+	}
+	p, e := l.logged[0].(*Message), l.logged[1].(*Message)
+	if p.Level != CRITICAL || !strings.Contains(p.Stack, "TestRunMain") || !strings.HasSuffix(p.File, "exit_test.go") {
+		t.Errorf("Unexpected panic message %+v", p)
+	}
+	if d := e.Data.(map[string]interface{}); e.Level != ERROR || d["exit_code"] != 2 || !strings.HasSuffix(e.File, "exit_test.go") {
+		t.Errorf("Unexpected exit message %+v", e)
+	}
+}
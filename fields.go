@@ -0,0 +1,210 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Merge adds the fields to the message's Data.
+// Data that isn't already a map is converted: objects through their JSON form, and
+// anything else is kept under a `message` key. Groups, nested maps present in both,
+// are merged rather than replaced. The caller's maps are never modified.
+func (m *Message) Merge(fields map[string]interface{}) {
+	if len(fields) == 0 {
+		return
+	}
+	d := make(map[string]interface{}, len(fields)+1)
+	switch v := m.Data.(type) {
+	case nil:
+	case map[string]interface{}:
+		for k, e := range v {
+			d[k] = e
+		}
+	case string:
+		d["message"] = v
+	default:
+		var o interface{}
+		b, err := m.DataJSON()
+		if err != nil || json.Unmarshal(b, &o) != nil {
+			d["message"] = v
+		} else if obj, ok := o.(map[string]interface{}); ok {
+			d = obj
+		} else {
+			d["message"] = o
+		}
+	}
+	mergeFields(d, fields)
+	m.Data = d
+}
+
+// Adds the fields to `dst`, merging groups into copies of those already in `dst`
+func mergeFields(dst, fields map[string]interface{}) {
+	for k, v := range fields {
+		g, ok := v.(map[string]interface{})
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		prev, ok := dst[k].(map[string]interface{})
+		if !ok {
+			dst[k] = g
+			continue
+		}
+		merged := make(map[string]interface{}, len(prev)+len(g))
+		for pk, pv := range prev {
+			merged[pk] = pv
+		}
+		mergeFields(merged, g)
+		dst[k] = merged
+	}
+}
+
+// Group nests the fields under `name`, eg. `jog.Group("http", fields)` logs as
+// `{"http": {"method": "GET", "status": 200}}`. Groups with the same name are
+// merged, see Message.Merge, and may be nested.
+func Group(name string, fields map[string]interface{}) map[string]interface{} {
+	g := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		g[k] = v
+	}
+	return map[string]interface{}{name: g}
+}
+
+// Clone returns a deep copy of the message, for a Logger to modify without
+// affecting the other Loggers it's passed to. The maps and slices of Data are
+// copied, other values within it, such as pointers, are shared.
+func (m *Message) Clone() *Message {
+	c := *m
+	c.Data = cloneValue(m.Data)
+	if m.Meta != nil {
+		c.Meta = cloneValue(m.Meta).(map[string]interface{})
+	}
+	if m.Tags != nil {
+		c.Tags = append([]string(nil), m.Tags...)
+	}
+	if m.Frames != nil {
+		c.Frames = append([]Frame(nil), m.Frames...)
+	}
+	return &c
+}
+
+// Copies the maps and slices of a value
+func cloneValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			c[k] = cloneValue(e)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, e := range v {
+			c[i] = cloneValue(e)
+		}
+		return c
+	case json.RawMessage:
+		return append(json.RawMessage(nil), v...)
+	}
+	return v
+}
+
+// Set adds a single field to the message's Data, see Merge
+func (m *Message) Set(key string, v interface{}) {
+	m.Merge(map[string]interface{}{key: v})
+}
+
+// MergeMeta adds the fields to the message's Meta
+func (m *Message) MergeMeta(fields map[string]interface{}) {
+	if len(fields) == 0 {
+		return
+	}
+	if m.Meta == nil {
+		m.Meta = make(map[string]interface{}, len(fields))
+	}
+	for k, v := range fields {
+		m.Meta[k] = v
+	}
+}
+
+// SetMeta adds a single field to the message's Meta
+func (m *Message) SetMeta(key string, v interface{}) {
+	if m.Meta == nil {
+		m.Meta = make(map[string]interface{}, 1)
+	}
+	m.Meta[key] = v
+}
+
+// Tag adds the tags to the message, skipping those it already has
+func (m *Message) Tag(tags ...string) {
+	for _, t := range tags {
+		if !m.HasTag(t) {
+			m.Tags = append(m.Tags, t)
+		}
+	}
+}
+
+// HasTag reports whether the message has the tag
+func (m *Message) HasTag(tag string) bool {
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Fields combines fields, such as those of Duration and Group, into one map
+//
+//	j.Info(jog.Fields(jog.Duration("latency", d), jog.Bytes("size", n)))
+func Fields(fields ...map[string]interface{}) map[string]interface{} {
+	d := map[string]interface{}{}
+	for _, f := range fields {
+		mergeFields(d, f)
+	}
+	return d
+}
+
+// Duration returns a field holding the duration in milliseconds, as a number,
+// eg. `{"latency": 12.5}`, rather than json.Marshal's integer nanoseconds
+func Duration(key string, d time.Duration) map[string]interface{} {
+	return map[string]interface{}{key: float64(d) / float64(time.Millisecond)}
+}
+
+// HumanDuration returns a field holding the duration as text, eg. `{"latency": "1m30s"}`
+func HumanDuration(key string, d time.Duration) map[string]interface{} {
+	return map[string]interface{}{key: d.String()}
+}
+
+// Bytes returns a field holding a size in bytes, as a number
+func Bytes(key string, n int64) map[string]interface{} {
+	return map[string]interface{}{key: n}
+}
+
+// HumanBytes returns a field holding a size in bytes as text, using binary
+// units, eg. `{"size": "1.5 MiB"}`
+func HumanBytes(key string, n int64) map[string]interface{} {
+	return map[string]interface{}{key: humanBytes(n)}
+}
+
+func humanBytes(n int64) string {
+	const unit = 1024
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs < unit {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	f, exp := float64(n), 0
+	for f/unit >= unit || f/unit <= -unit {
+		f /= unit
+		exp++
+	}
+	return strconv.FormatFloat(f/unit, 'f', 1, 64) + " " + string("KMGTPE"[exp]) + "iB"
+}
@@ -0,0 +1,121 @@
+package jog
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMessageSet(t *testing.T) {
+	orig := map[string]interface{}{"a": 1}
+	tests := []struct {
+		data     interface{}
+		expected string
+	}{
+		{nil, "map[pod:web-1]"},
+		{"hello", "map[message:hello pod:web-1]"},
+		{orig, "map[a:1 pod:web-1]"},
+		{struct{ Name string }{"Jack"}, "map[Name:Jack pod:web-1]"},
+		{[]int{1, 2}, "map[message:[1 2] pod:web-1]"},
+	}
+	for _, v := range tests {
+		m := &Message{Data: v.data}
+		m.Set("pod", "web-1")
+		if s := fmt.Sprint(m.Data); s != v.expected {
+			t.Error("Expected", v.expected, "got", s)
+		}
+	}
+	if len(orig) != 1 {
+		t.Error("Expected the original map to be left alone, got", orig)
+	}
+}
+
+func TestWithBuildInfo(t *testing.T) {
+	l := &testLogger{}
+	j := New(l, WithBuildInfo())
+	j.Info("started")
+
+	if l.message.Data != "started" {
+		t.Fatalf("Expected Data to be left alone, got %v", l.message.Data)
+	}
+	if b, ok := l.message.Meta["build"].(map[string]interface{}); !ok || b["go"] == "" {
+		t.Errorf("Expected build info, got %v", l.message.Meta)
+	}
+}
+
+func TestMetaAndTags(t *testing.T) {
+	l := &testLogger{}
+	j := New(l, WithMeta(map[string]interface{}{"env": "prod"}), WithTags("api", "web"))
+	j.Info(map[string]interface{}{"user": "bob"})
+
+	m := l.message
+	if fmt.Sprint(m.Data) != "map[user:bob]" || fmt.Sprint(m.Meta) != "map[env:prod]" {
+		t.Error("Expected Data and Meta kept apart, got", m.Data, m.Meta)
+	}
+	m.Tag("web", "audit")
+	if fmt.Sprint(m.Tags) != "[api web audit]" || !m.HasTag("audit") {
+		t.Error("Expected tags without duplicates, got", m.Tags)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	req := map[string]interface{}{"method": "GET"}
+	l := &testLogger{}
+	j := New(l, WithFields(Group("http", req)))
+	j.Info(Group("http", map[string]interface{}{"status": 200}))
+
+	if s := fmt.Sprint(l.message.Data); s != "map[http:map[method:GET status:200]]" {
+		t.Error("Expected the groups to be merged, got", s)
+	}
+	if len(req) != 1 {
+		t.Error("Expected the group's fields to be left alone, got", req)
+	}
+
+	m := &Message{Data: "hi"}
+	m.Merge(Group("a", Group("b", map[string]interface{}{"c": 1})))
+	m.Merge(Group("a", Group("b", map[string]interface{}{"d": 2})))
+	if s := fmt.Sprint(m.Data); s != "map[a:map[b:map[c:1 d:2]] message:hi]" {
+		t.Error("Expected nested groups to be merged, got", s)
+	}
+}
+
+func TestDurationAndBytes(t *testing.T) {
+	tests := []struct {
+		field    map[string]interface{}
+		expected string
+	}{
+		{Duration("latency", 12500*time.Microsecond), `{"latency":12.5}`},
+		{HumanDuration("latency", 90*time.Second), `{"latency":"1m30s"}`},
+		{Bytes("size", 1536), `{"size":1536}`},
+		{HumanBytes("size", 512), `{"size":"512 B"}`},
+		{HumanBytes("size", 1536), `{"size":"1.5 KiB"}`},
+		{HumanBytes("size", 3<<30), `{"size":"3.0 GiB"}`},
+		{HumanBytes("size", -2048), `{"size":"-2.0 KiB"}`},
+	}
+	for _, v := range tests {
+		if b, _ := json.Marshal(v.field); string(b) != v.expected {
+			t.Error("Expected", v.expected, "got", string(b))
+		}
+	}
+
+	f := Fields(Duration("latency", time.Second), Bytes("size", 1), Group("http", map[string]interface{}{"status": 200}))
+	if b, _ := json.Marshal(f); string(b) != `{"http":{"status":200},"latency":1000,"size":1}` {
+		t.Error("Expected combined fields, got", string(b))
+	}
+}
+
+func TestClone(t *testing.T) {
+	m := &Message{Data: map[string]interface{}{"http": map[string]interface{}{"status": 200}, "ids": []interface{}{1}}, Tags: []string{"a"}}
+	m.SetMeta("host", "web1")
+	c := m.Clone()
+	c.Data.(map[string]interface{})["http"].(map[string]interface{})["status"] = 500
+	c.Data.(map[string]interface{})["ids"].([]interface{})[0] = 2
+	c.SetMeta("host", "web2")
+	c.Tags[0] = "b"
+
+	d := m.Data.(map[string]interface{})
+	if d["http"].(map[string]interface{})["status"] != 200 || d["ids"].([]interface{})[0] != 1 || m.Meta["host"] != "web1" || m.Tags[0] != "a" {
+		t.Error("Expected the original to be unchanged, got", m)
+	}
+}
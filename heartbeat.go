@@ -0,0 +1,79 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Counts the messages a Jog, and its copies, have logged by level
+type counters struct {
+	levels [6]uint64
+	failed uint64
+}
+
+func (c *counters) add(l Level, err error) {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.levels[severity[l]], 1)
+	if err != nil {
+		atomic.AddUint64(&c.failed, 1)
+	}
+}
+
+// Counts returns the number of messages logged by the Jog, and the copies made by
+// Named, Service and the like, per level, along with those that failed
+func (j *Jog) Counts() map[string]uint64 {
+	c := map[string]uint64{}
+	if j.counts == nil {
+		return c
+	}
+	for l, i := range severity {
+		c[string(l)] = atomic.LoadUint64(&j.counts.levels[i])
+	}
+	c["failed"] = atomic.LoadUint64(&j.counts.failed)
+	return c
+}
+
+// Heartbeat logs an INFO message every interval, holding the process's uptime,
+// PID, version (see BuildInfo) and message Counts, so a quiet process can be told
+// apart from a dead one. Calling stop ends the heartbeat.
+func (j *Jog) Heartbeat(interval time.Duration) (stop func()) {
+	version := ""
+	if b := BuildInfo(); b != nil {
+		version, _ = b["version"].(string)
+	}
+	done, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				j.output(j.Depth-1, INFO, heartbeat(version, j.Counts()))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+func heartbeat(version string, counts map[string]uint64) map[string]interface{} {
+	return map[string]interface{}{
+		"message":  "heartbeat",
+		"uptime_s": time.Since(started).Seconds(),
+		"pid":      os.Getpid(),
+		"version":  version,
+		"counts":   counts,
+	}
+}
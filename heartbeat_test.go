@@ -0,0 +1,29 @@
+package jog
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHeartbeat(t *testing.T) {
+	l := &anyLogger{}
+	j := New(l)
+	j.Named("db").Warning("slow")
+	j.Info("one")
+
+	stop := j.Heartbeat(5 * time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	stop()
+
+	if c := j.Counts(); c["info"] < 2 || c["warning"] != 1 || c["failed"] != 0 {
+		t.Error("Unexpected counts", c)
+	}
+	if len(l.logged) < 3 {
+		t.Fatal("Expected a heartbeat to be logged")
+	}
+	d := l.logged[2].(*Message).Data.(map[string]interface{})
+	if d["message"] != "heartbeat" || d["pid"] != os.Getpid() {
+		t.Error("Unexpected heartbeat", d)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httplog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"code.minty.io/jog"
+)
+
+var (
+	// Combined encodes the messages of Handler in the Apache/Nginx Combined Log Format,
+	// eg. `127.0.0.1 - - [01/Jun/2013:00:00:00 +0000] "GET /a?x=1 HTTP/1.1" 200 512 "-" "curl/8.0"`.
+	// Other messages are encoded as JSON, see jog.JSON.
+	Combined jog.Encoder = jog.EncoderFunc(func(m *jog.Message) ([]byte, error) {
+		r, ok := request(m)
+		if !ok {
+			return jog.JSON.Encode(m)
+		}
+		return []byte(combined(m, r) + "\n"), nil
+	})
+
+	// CombinedID encodes the messages of Handler as Combined does, followed by the
+	// quoted request ID, as with Nginx's `$request_id` added to the combined format,
+	// eg. `... "curl/8.0" "abc123"`. Other messages are encoded as JSON, see jog.JSON.
+	CombinedID jog.Encoder = jog.EncoderFunc(func(m *jog.Message) ([]byte, error) {
+		r, ok := request(m)
+		if !ok {
+			return jog.JSON.Encode(m)
+		}
+		return []byte(combined(m, r) + " \"" + clfEscape(dash(r.RequestID)) + "\"\n"), nil
+	})
+
+	// NginxJSON encodes the messages of Handler as the JSON access log commonly
+	// configured in Nginx (`log_format json_combined escape=json`), whose values are
+	// all strings. Other messages are encoded as JSON, see jog.JSON.
+	NginxJSON jog.Encoder = jog.EncoderFunc(func(m *jog.Message) ([]byte, error) {
+		r, ok := request(m)
+		if !ok {
+			return jog.JSON.Encode(m)
+		}
+		b, err := json.Marshal(nginxLine{
+			TimeLocal:     m.Time.Format("02/Jan/2006:15:04:05 -0700"),
+			RemoteAddr:    host(r.Remote),
+			Request:       requestLine(r),
+			Status:        strconv.Itoa(r.Status),
+			BodyBytesSent: strconv.Itoa(r.Bytes),
+			RequestTime:   strconv.FormatFloat(r.Duration/1000, 'f', 3, 64),
+			Referer:       r.Referer,
+			UserAgent:     r.UserAgent,
+			RequestID:     r.RequestID,
+			TraceID:       r.TraceID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	})
+)
+
+type nginxLine struct {
+	TimeLocal     string `json:"time_local"`
+	RemoteAddr    string `json:"remote_addr"`
+	RemoteUser    string `json:"remote_user"`
+	Request       string `json:"request"`
+	Status        string `json:"status"`
+	BodyBytesSent string `json:"body_bytes_sent"`
+	RequestTime   string `json:"request_time"`
+	Referer       string `json:"http_referrer"`
+	UserAgent     string `json:"http_user_agent"`
+	RequestID     string `json:"request_id,omitempty"`
+	TraceID       string `json:"trace_id,omitempty"`
+}
+
+// Formats the Combined Log Format line of the request, without its newline
+func combined(m *jog.Message, r Request) string {
+	return fmt.Sprintf("%s - - [%s] \"%s\" %d %s \"%s\" \"%s\"",
+		host(r.Remote), m.Time.Format("02/Jan/2006:15:04:05 -0700"), clfEscape(requestLine(r)),
+		r.Status, clfBytes(r.Bytes), clfEscape(dash(r.Referer)), clfEscape(dash(r.UserAgent)))
+}
+
+// Returns the Request of the message's Data, which may have been decoded into a map,
+// its request and trace IDs defaulting to those of the message's Meta, see jog.LogContext
+func request(m *jog.Message) (Request, bool) {
+	r, ok := m.Data.(Request)
+	if p, isPtr := m.Data.(*Request); isPtr && p != nil {
+		r, ok = *p, true
+	}
+	if !ok {
+		b, err := m.DataJSON()
+		if err != nil || json.Unmarshal(b, &r) != nil || r.Method == "" || r.Status == 0 {
+			return r, false
+		}
+	}
+	if r.RequestID == "" {
+		r.RequestID, _ = m.Meta["request_id"].(string)
+	}
+	if r.TraceID == "" {
+		r.TraceID, _ = m.Meta["trace_id"].(string)
+	}
+	return r, true
+}
+
+// Returns the request line, eg. `GET /a?x=1 HTTP/1.1`
+func requestLine(r Request) string {
+	u := r.Path
+	if r.Query != "" {
+		u += "?" + r.Query
+	}
+	proto := r.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	return r.Method + " " + u + " " + proto
+}
+
+// Returns the address without its port
+func host(addr string) string {
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		return h
+	}
+	return dash(addr)
+}
+
+func clfBytes(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return strconv.Itoa(n)
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// Escapes quotes, backslashes and control characters as Apache does
+func clfEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, "\\x%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
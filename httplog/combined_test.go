@@ -0,0 +1,52 @@
+package httplog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+func TestCombined(t *testing.T) {
+	r := Request{Method: "GET", Path: "/a", Query: "x=1", Proto: "HTTP/1.1", Status: 200, Bytes: 512,
+		Duration: 12.5, Remote: "127.0.0.1:5000", UserAgent: `curl "8"`, RequestID: "abc"}
+	m := &jog.Message{Data: r, Level: jog.INFO, Time: time.Date(2013, 6, 1, 0, 0, 0, 0, time.UTC)}
+
+	b, err := Combined.Encode(m)
+	expected := `127.0.0.1 - - [01/Jun/2013:00:00:00 +0000] "GET /a?x=1 HTTP/1.1" 200 512 "-" "curl \"8\""` + "\n"
+	if err != nil || string(b) != expected {
+		t.Error("Expected", expected, "got", string(b), err)
+	}
+
+	b, err = CombinedID.Encode(m)
+	if expected = expected[:len(expected)-1] + ` "abc"` + "\n"; err != nil || string(b) != expected {
+		t.Error("Expected", expected, "got", string(b), err)
+	}
+
+	// Decoded messages hold a map
+	d, _ := json.Marshal(r)
+	var data map[string]interface{}
+	json.Unmarshal(d, &data)
+	m.Data = data
+	b, err = NginxJSON.Encode(m)
+	expected = `{"time_local":"01/Jun/2013:00:00:00 +0000","remote_addr":"127.0.0.1","remote_user":"","request":"GET /a?x=1 HTTP/1.1","status":"200","body_bytes_sent":"512","request_time":"0.013","http_referrer":"","http_user_agent":"curl \"8\"","request_id":"abc"}` + "\n"
+	if err != nil || string(b) != expected {
+		t.Error("Expected", expected, "got", string(b), err)
+	}
+
+	// The IDs may be carried by Meta instead
+	r.RequestID = ""
+	m.Data = r
+	m.Meta = map[string]interface{}{"request_id": "def", "trace_id": "0af7"}
+	b, _ = NginxJSON.Encode(m)
+	if !strings.Contains(string(b), `"request_id":"def","trace_id":"0af7"`) {
+		t.Error("Expected the IDs of Meta, got", string(b))
+	}
+
+	m.Data = "not a request"
+	if b, _ := Combined.Encode(m); b[0] != '{' {
+		t.Error("Expected other messages as JSON, got", string(b))
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package httplog contains HTTP middleware that logs requests through jog, and
+// propagates request IDs.
+//
+//	j := jog.New(loggers.NewFromConfig())
+//	http.ListenAndServe(":8080", httplog.Handler(j, mux))
+//
+// The logger may be wrapped to enrich access logs, eg. with
+// `loggers.ParseUserAgents(l, "user_agent")` and `loggers.GeoIP(l, db, "remote")`,
+// and written for legacy tooling with the Combined, or NginxJSON, encoders.
+package httplog
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Header carrying the request ID
+var Header = "X-Request-ID"
+
+// Request is the Data logged for each request
+type Request struct {
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	Query     string  `json:"query,omitempty"`
+	Proto     string  `json:"proto,omitempty"`
+	Status    int     `json:"status"`
+	Bytes     int     `json:"bytes"`
+	Duration  float64 `json:"duration_ms"`
+	Remote    string  `json:"remote"`
+	UserAgent string  `json:"user_agent,omitempty"`
+	Referer   string  `json:"referer,omitempty"`
+	RequestID string  `json:"request_id"`
+	TraceID   string  `json:"trace_id,omitempty"`
+	SpanID    string  `json:"span_id,omitempty"`
+}
+
+// ParseTraceparent parses a W3C `traceparent` header value,
+// eg. `00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01`
+func ParseTraceparent(s string) (jog.Trace, bool) {
+	p := strings.Split(strings.TrimSpace(s), "-")
+	if len(p) < 4 || len(p[0]) != 2 || p[0] == "ff" || (p[0] == "00" && len(p) != 4) {
+		return jog.Trace{}, false
+	}
+	t := jog.Trace{TraceID: p[1], SpanID: p[2], Flags: p[3]}
+	if !isHex(t.TraceID, 32) || !isHex(t.SpanID, 16) || !isHex(t.Flags, 2) || !isHex(p[0], 2) {
+		return jog.Trace{}, false
+	}
+	// All zero IDs are invalid
+	if strings.Trim(t.TraceID, "0") == "" || strings.Trim(t.SpanID, "0") == "" {
+		return jog.Trace{}, false
+	}
+	return t, true
+}
+
+// Whether `s` is `n` lowercase hex characters
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Records the status and size of the response
+type recorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+func (r *recorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Level of a request's message, by its status
+func level(status int) jog.Level {
+	switch {
+	case status >= 500:
+		return jog.ERROR
+	case status >= 400:
+		return jog.WARNING
+	}
+	return jog.INFO
+}
+
+// Handler returns middleware that logs each request handled by `next`.
+// The request ID is taken from the request's header, or generated, then stored in
+// the request's context (see jog.RequestID) and echoed in the response's header.
+// A valid `traceparent` header is stored in the context too (see jog.TraceFrom), so
+// messages logged with jog.LogContext carry the trace and span IDs.
+func Handler(j *jog.Jog, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(Header)
+		if id == "" || len(id) > 128 {
+			id = jog.NewRequestID()
+		}
+		w.Header().Set(Header, id)
+		ctx := jog.ContextWithRequestID(r.Context(), id)
+		trace, traced := ParseTraceparent(r.Header.Get("traceparent"))
+		if traced {
+			trace.State = r.Header.Get("tracestate")
+			ctx = jog.ContextWithTrace(ctx, trace)
+		}
+		r = r.WithContext(ctx)
+
+		rec := &recorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		j.Log(level(rec.status), Request{
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			Query:     r.URL.RawQuery,
+			Proto:     r.Proto,
+			Status:    rec.status,
+			Bytes:     rec.bytes,
+			Duration:  float64(time.Since(start)) / float64(time.Millisecond),
+			Remote:    r.RemoteAddr,
+			UserAgent: r.UserAgent(),
+			Referer:   r.Referer(),
+			RequestID: id,
+			TraceID:   trace.TraceID,
+			SpanID:    trace.SpanID,
+		})
+	})
+}
+
+type transport struct {
+	next http.RoundTripper
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	h := map[string]string{}
+	if id := jog.RequestID(r.Context()); id != "" && r.Header.Get(Header) == "" {
+		h[Header] = id
+	}
+	if tr, ok := jog.TraceFrom(r.Context()); ok && r.Header.Get("traceparent") == "" {
+		h["traceparent"] = "00-" + tr.TraceID + "-" + tr.SpanID + "-" + tr.Flags
+		if tr.State != "" {
+			h["tracestate"] = tr.State
+		}
+	}
+	if len(h) > 0 {
+		r = r.Clone(r.Context())
+		for k, v := range h {
+			r.Header.Set(k, v)
+		}
+	}
+	return t.next.RoundTrip(r)
+}
+
+// Transport returns an http.RoundTripper that adds the request ID, and trace, carried
+// by an outgoing request's context to its header, propagating them to downstream services
+func Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{next}
+}
+
+// Recover returns middleware that recovers panics in `next`, logging them, and a
+// crash report when enabled, with jog.Recovered, and responds with a 500 when
+// nothing was written yet. The panic's message carries the request ID of the
+// request's context. http.ErrAbortHandler is passed on, as it aborts the response.
+//
+//	http.ListenAndServe(":8080", httplog.Handler(j, httplog.Recover(j, mux)))
+func Recover(j *jog.Jog, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &recorder{ResponseWriter: w}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			unbind := jog.Bind(r.Context())
+			j.Recovered(p)
+			unbind()
+			if rec.status == 0 {
+				http.Error(rec, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
@@ -0,0 +1,104 @@
+package httplog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"code.minty.io/jog"
+	"code.minty.io/jog/jogtest"
+)
+
+func TestHandler(t *testing.T) {
+	j, rec := jogtest.New()
+	var ctxID string
+	h := Handler(j, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxID = jog.RequestID(r.Context())
+		http.NotFound(w, r)
+	}))
+
+	r := httptest.NewRequest("GET", "/missing?x=1", nil)
+	r.Header.Set(Header, "abc-123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if ctxID != "abc-123" || w.Header().Get(Header) != "abc-123" {
+		t.Error("Expected request ID to be propagated, got", ctxID, w.Header().Get(Header))
+	}
+	m := rec.Last()
+	req, ok := m.Data.(Request)
+	if !ok || m.Level != jog.WARNING || req.Status != 404 || req.Path != "/missing" || req.RequestID != "abc-123" {
+		t.Errorf("Unexpected message %s %+v", m.Level, m.Data)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(Header)
+	}))
+	defer srv.Close()
+
+	ctx := jog.ContextWithRequestID(t.Context(), "xyz")
+	r, _ := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	c := &http.Client{Transport: Transport(nil)}
+	if _, err := c.Do(r); err != nil {
+		t.Fatal(err)
+	}
+	if got != "xyz" {
+		t.Error("Expected request ID header, got", got)
+	}
+}
+
+func TestTraceparent(t *testing.T) {
+	j, rec := jogtest.New()
+	h := Handler(j, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		j.LogContext(r.Context(), jog.INFO, "handling")
+	}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	d := rec.Entries()[0].Meta
+	if d["trace_id"] != "0af7651916cd43dd8448eb211c80319c" || d["span_id"] != "b7ad6b7169203331" {
+		t.Error("Expected trace IDs in Meta, got", d)
+	}
+	if req := rec.Last().Data.(Request); req.TraceID != "0af7651916cd43dd8448eb211c80319c" {
+		t.Error("Expected trace ID in request message, got", req)
+	}
+
+	for _, s := range []string{"", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331", "00-00000000000000000000000000000000-b7ad6b7169203331-01", "00-0AF7651916CD43DD8448EB211C80319C-b7ad6b7169203331-01"} {
+		if _, ok := ParseTraceparent(s); ok {
+			t.Error("Expected invalid traceparent", s)
+		}
+	}
+}
+
+func TestRecover(t *testing.T) {
+	j, rec := jogtest.New()
+	h := Handler(j, Recover(j, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("kaboom")
+	})))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(Header, "abc-123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Error("Expected a 500, got", w.Code)
+	}
+	msgs := rec.Entries()
+	if len(msgs) != 2 {
+		t.Fatal("Expected the panic and request to be logged, got", len(msgs))
+	}
+	p := msgs[0]
+	if p.Level != jog.CRITICAL || p.Meta["request_id"] != "abc-123" || !strings.HasSuffix(p.File, "httplog_test.go") {
+		t.Errorf("Unexpected panic message %s %s %+v", p.Level, p.File, p.Meta)
+	}
+	if req := msgs[1].Data.(Request); req.Status != 500 {
+		t.Error("Expected the request to be logged as a 500, got", req.Status)
+	}
+}
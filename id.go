@@ -0,0 +1,71 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+)
+
+// WithIDs gives each message an ID, from `fn` or NewRequestID (a random UUID)
+// when nil, eg. `jog.WithIDs(jog.NewULID)`
+func WithIDs(fn func() string) Option {
+	if fn == nil {
+		fn = NewRequestID
+	}
+	return func(j *Jog) {
+		j.id = fn
+	}
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var ulid struct {
+	sync.Mutex
+	ms   uint64
+	last [10]byte
+}
+
+// NewULID returns a new ULID, a 26 character ID that sorts by the time it was
+// created. IDs created within the same millisecond are monotonically increasing.
+func NewULID() string {
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+
+	ulid.Lock()
+	var r [10]byte
+	if ms == ulid.ms {
+		r = ulid.last
+		for i := len(r) - 1; i >= 0; i-- {
+			if r[i]++; r[i] != 0 {
+				break
+			}
+		}
+	} else if _, err := rand.Read(r[:]); err != nil {
+		panic(err)
+	}
+	ulid.ms, ulid.last = ms, r
+	ulid.Unlock()
+
+	// 48 bits of time and 80 of randomness, 5 bits to a character
+	var b [16]byte
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> uint(40-8*i))
+	}
+	copy(b[6:], r[:])
+	var out [26]byte
+	// The 128 bits are left padded to 130, so the first character holds 3 bits
+	out[0] = crockford[b[0]>>5]
+	bit := 3
+	for i := 1; i < 26; i++ {
+		var v byte
+		for k := 0; k < 5; k++ {
+			v = v<<1 | b[bit/8]>>uint(7-bit%8)&1
+			bit++
+		}
+		out[i] = crockford[v]
+	}
+	return string(out[:])
+}
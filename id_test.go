@@ -0,0 +1,35 @@
+package jog
+
+import (
+	"testing"
+)
+
+func TestWithIDs(t *testing.T) {
+	l := &testLogger{}
+	j := New(l, WithIDs(nil))
+	j.Info("one")
+	first := l.message.ID
+	j.Info("two")
+	if len(first) != 36 || l.message.ID == first {
+		t.Error("Expected unique IDs, got", first, l.message.ID)
+	}
+
+	New(l).Info("three")
+	if l.message.ID != "" {
+		t.Error("Expected no ID by default, got", l.message.ID)
+	}
+}
+
+func TestNewULID(t *testing.T) {
+	prev := NewULID()
+	for i := 0; i < 1000; i++ {
+		id := NewULID()
+		if len(id) != 26 || id <= prev {
+			t.Fatal("Expected increasing ULIDs, got", prev, id)
+		}
+		prev = id
+	}
+	if id := NewULID(); id[0] > '7' {
+		t.Error("Unexpected first character", id)
+	}
+}
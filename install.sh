@@ -3,3 +3,4 @@
 go install code.minty.io/jog
 go install code.minty.io/jog/loggers
 
+go install code.minty.io/jog/cmd/jogcat
@@ -8,12 +8,16 @@
 package jog
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 )
 
@@ -29,6 +33,38 @@ const (
 // Level is the level of the data being logged
 type Level string
 
+// Ordering of the levels, from least to most severe
+var severity = map[Level]int{
+	DEBUG:    1,
+	INFO:     2,
+	WARNING:  3,
+	ERROR:    4,
+	CRITICAL: 5,
+}
+
+// AtLeast reports whether the level is as severe, or more, than `min`
+func (l Level) AtLeast(min Level) bool {
+	return severity[l] >= severity[min]
+}
+
+// ParseLevel returns the Level named by `s`, ignoring case and accepting common
+// aliases such as `warn` and `fatal`. Unknown names are UNKNOWN.
+func ParseLevel(s string) Level {
+	switch strings.ToLower(s) {
+	case "critical", "crit", "fatal", "panic", "emerg", "alert":
+		return CRITICAL
+	case "error", "err":
+		return ERROR
+	case "warning", "warn":
+		return WARNING
+	case "info", "notice", "":
+		return INFO
+	case "debug", "trace":
+		return DEBUG
+	}
+	return UNKNOWN
+}
+
 // Message is used to capture basic information to be logged.
 // This message is then passed to the log function of a Logger.
 type Message struct {
@@ -37,24 +73,256 @@ type Message struct {
 	File  string      `json:"file"`
 	Line  int         `json:"line"`
 	Time  time.Time   `json:"timestamp"`
+
+	// ID uniquely identifies the message, so consumers of at-least-once pipelines
+	// can drop duplicates, see WithIDs
+	ID string `json:"id,omitempty"`
+
+	// Func is the name of the calling function, eg. `main.(*Server).handle`
+	Func string `json:"func,omitempty"`
+
+	// Stack of the caller, for messages at or above the level set by WithStacktrace
+	Stack string `json:"stack,omitempty"`
+	// Frames of the caller's stack, instead of Stack, see StackConfig.Structured
+	Frames []Frame `json:"frames,omitempty"`
+
+	// Version of the wire format, see SchemaVersion
+	Version int `json:"schema_version,omitempty"`
+
+	// Set when Data was cut down to fit a size limit, along with its original length
+	Truncated bool `json:"truncated,omitempty"`
+	Length    int  `json:"length,omitempty"`
+
+	// Raw is the line parsed into Data by Write, see WithRawField
+	Raw string `json:"raw,omitempty"`
+
+	// Meta and Tags hold infrastructure fields, such as the host or the request ID,
+	// added by options and middleware, so Data is left as it was logged
+	Meta map[string]interface{} `json:"meta,omitempty"`
+	Tags []string               `json:"tags,omitempty"`
+
+	policy MarshalPolicy
+	loud   bool
+	ctx    context.Context
 }
 
-// Logger is an interface used as the communication means for the log
+// Logger is an interface used as the communication means for the log.
+// A Logger owns the messages passed to it, and may modify them, so wrappers
+// passing a message to several Loggers give each its own copy, see Message.Clone.
 type Logger interface {
 	Log(m interface{}) (int, error)
 }
 
+// BatchLogger is implemented by Loggers that ship whole batches of messages at
+// once, eg. in a single request. Wrappers that queue messages, such as
+// loggers.Async, prefer it to logging them one at a time.
+type BatchLogger interface {
+	LogBatch(msgs []*Message) (int, error)
+}
+
+// LogBatch logs the messages with the Logger's LogBatch, when it's a BatchLogger,
+// or one at a time, stopping at the first error
+func LogBatch(l Logger, msgs []*Message) (int, error) {
+	if b, ok := l.(BatchLogger); ok {
+		return b.LogBatch(msgs)
+	}
+	var total int
+	for _, m := range msgs {
+		n, err := l.Log(m)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+type discard struct{}
+
+func (discard) Log(m interface{}) (int, error) {
+	return 0, nil
+}
+
+// Discard is a Logger that drops everything passed to it.
+// A Jog using Discard skips building messages entirely.
+var Discard Logger = discard{}
+
 // Jog is the core logging type, it contains an instance of a Logger that is passed
 // the log Message.
 // Jog implements io.Writer so it can be used as log.SetOutput(logWriter)
 type Jog struct {
-	logger Logger
-	Depth  int
+	logger   Logger
+	Depth    int
+	level    Level
+	limits   *limiter
+	clock    func() time.Time
+	fields   map[string]interface{}
+	meta     map[string]interface{}
+	tags     []string
+	raw      bool
+	keep     bool
+	policy   MarshalPolicy
+	short    bool
+	stack    Level
+	stackCfg StackConfig
+	name     string
+	override *overrides
+	id       func() string
+	counts   *counters
+	maxWrite int
+	maxDepth int
+	crashes  bool
+	recent   Recent
+	loud     bool
+}
+
+// Option is used to configure a Jog instance
+type Option func(*Jog)
+
+// WithLevel sets the minimum Level of messages that are logged
+func WithLevel(l Level) Option {
+	return func(j *Jog) {
+		j.level = l
+	}
+}
+
+// WithClock sets the source of Message timestamps, which defaults to time.Now
+func WithClock(fn func() time.Time) Option {
+	return func(j *Jog) {
+		j.clock = fn
+	}
+}
+
+// WithFields adds the fields to the Data of every message, see Message.Merge
+func WithFields(fields map[string]interface{}) Option {
+	return func(j *Jog) {
+		if j.fields == nil {
+			j.fields = make(map[string]interface{}, len(fields))
+		}
+		for k, v := range fields {
+			j.fields[k] = v
+		}
+	}
+}
+
+// WithMeta adds the fields to the Meta of every message
+func WithMeta(fields map[string]interface{}) Option {
+	return func(j *Jog) {
+		if j.meta == nil {
+			j.meta = make(map[string]interface{}, len(fields))
+		}
+		for k, v := range fields {
+			j.meta[k] = v
+		}
+	}
+}
+
+// WithTags adds the tags to every message
+func WithTags(tags ...string) Option {
+	return func(j *Jog) {
+		j.tags = append(j.tags, tags...)
+	}
+}
+
+// WithShortFuncs trims the package path from the Func of messages, leaving the
+// package name, eg. `code.minty.io/jog/loggers.(*Async).Log` becomes `loggers.(*Async).Log`
+func WithShortFuncs() Option {
+	return func(j *Jog) {
+		j.short = true
+	}
+}
+
+// Trims the package path from a function name
+func shortFunc(name string) string {
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// WithRaw makes Write forward lines that are already JSON objects to the Logger as
+// is, as a json.RawMessage, rather than wrapping them in a Message. Keys keep their
+// order and numbers their precision, for proxying another service's structured logs.
+// The `level` of a raw line is still used for filtering, but no fields are added.
+func WithRaw() Option {
+	return func(j *Jog) {
+		j.raw = true
+	}
+}
+
+// WithRawField keeps the original line in the Raw field of messages whose Data
+// was parsed from JSON by Write, so nothing is lost when the parsing mangles it
+func WithRawField() Option {
+	return func(j *Jog) {
+		j.keep = true
+	}
+}
+
+// Enabled reports whether messages of the given Level are logged
+func (j *Jog) Enabled(l Level) bool {
+	if j.logger == Discard {
+		return false
+	}
+	min := j.Level()
+	return min == "" || l.AtLeast(min)
+}
+
+// Level returns the minimum Level of messages that are logged, including any
+// override (see WithTemporaryLevel), empty when all are
+func (j *Jog) Level() Level {
+	if o, ok := j.override.level(j.name); ok {
+		return o
+	}
+	return j.level
 }
 
 // Log with a given Level and object
 func (j *Jog) Log(l Level, o interface{}) (int, error) {
-	return j.write(newMessage(l, o, j.Depth))
+	return j.output(j.Depth, l, o)
+}
+
+// Logs the object when the level is enabled, `depth` being the number of
+// frames above the caller of `output`
+func (j *Jog) output(depth int, l Level, o interface{}) (int, error) {
+	if !j.Enabled(l) {
+		return 0, nil
+	}
+	return j.write(j.newMessage(l, o, depth+1))
+}
+
+// Builds a message stamped with the Jog's clock
+func (j *Jog) newMessage(l Level, o interface{}, depth int) *Message {
+	m := newMessage(l, o, depth+1)
+	m.policy = j.policy
+	m.loud = j.loud
+	if j.stack != "" && l.AtLeast(j.stack) {
+		if f := frames(depth+1, j.stackCfg); j.stackCfg.Structured {
+			m.Frames = f
+		} else {
+			m.Stack = formatFrames(f)
+		}
+	}
+	if j.short {
+		m.Func = shortFunc(m.Func)
+	}
+	if j.clock != nil {
+		m.Time = j.clock().UTC()
+	}
+	if j.id != nil {
+		m.ID = j.id()
+	}
+	if ctx, ok := bound(); ok {
+		fromContext(ctx, m)
+	}
+	return m
+}
+
+// The current time according to the Jog's clock
+func (j *Jog) now() time.Time {
+	if j.clock != nil {
+		return j.clock()
+	}
+	return time.Now()
 }
 
 // Log a critical message by the given object
@@ -90,7 +358,10 @@ func (j *Jog) Debug(o interface{}) error {
 // Writes the given bytes to a Logger
 // (implementation of io.Writer)
 func (j *Jog) Write(p []byte) (int, error) {
-	m := newMessage(INFO, nil, j.Depth+1)
+	if j.logger == Discard {
+		return len(p), nil
+	}
+	n := len(p)
 
 	// Remove trailing "\n", added by `log.Output(int, string)`
 	l := len(p) - 1
@@ -99,29 +370,95 @@ func (j *Jog) Write(p []byte) (int, error) {
 		l--
 	}
 
+	// Oversized lines are cut down, and neither they nor deeply nested JSON are parsed
+	if j.maxWrite > 0 && len(p) > j.maxWrite {
+		m := j.newMessage(INFO, string(cutUTF8(p, j.maxWrite)), j.Depth+1)
+		m.Truncated, m.Length = true, len(p)
+		if !j.Enabled(m.Level) {
+			return n, nil
+		}
+		return j.write(m)
+	}
+
 	// Attempt to set JSON value of `p` and log level
-	isJSONLike := l > 1 && p[0] == '{' && p[l] == '}'
-	if isJSONLike && json.Unmarshal(p, &m.Data) == nil {
+	isJSONLike := l > 1 && p[0] == '{' && p[l] == '}' && (j.maxDepth <= 0 || jsonDepth(p) <= j.maxDepth)
+	if j.raw && isJSONLike && json.Valid(p) {
+		return j.writeRaw(p, n)
+	}
+	m := j.newMessage(INFO, nil, j.Depth+1)
+	if isJSONLike && unmarshal(p, &m.Data) == nil {
 		m.Level = levelFrom(m.Data)
+		if j.keep {
+			m.Raw = string(p)
+		}
 	} else {
 		m.Data = string(p)
 	}
+	if !j.Enabled(m.Level) {
+		return n, nil
+	}
 
 	// Send to logger
 	return j.write(m)
 }
 
+// Passes a JSON line to the Logger as is
+func (j *Jog) writeRaw(p []byte, n int) (int, error) {
+	var lvl struct {
+		Level Level `json:"level"`
+	}
+	json.Unmarshal(p, &lvl)
+	if lvl.Level == "" {
+		lvl.Level = INFO
+	}
+	if !j.Enabled(lvl.Level) {
+		return n, nil
+	}
+	// Copy, as the log package reuses its buffer
+	raw := append(json.RawMessage(nil), p...)
+	if _, err := j.logger.Log(raw); err != nil {
+		if !diagnose(ERROR, "log_failure", map[string]interface{}{"error": err.Error(), "raw": string(raw)}) {
+			os.Stderr.Write([]byte(fmt.Sprintf("[LOG FAILURE] - (Logger) %s -> \n%s\n", err, raw)))
+		}
+		return 0, err
+	}
+	return n, nil
+}
+
 // Invoke the Logger with the JSON data
 func (j *Jog) write(m *Message) (int, error) {
+	m.Merge(j.fields)
+	m.MergeMeta(j.meta)
+	m.Tag(j.tags...)
+	if j.name != "" {
+		m.SetMeta("logger", j.name)
+	}
 	n, err := j.logger.Log(m)
+	j.counts.add(m.Level, err)
 	if err != nil {
-		s := fmt.Sprintf("[LOG FAILURE] - (Logger) %s -> \n%s\n", err, m)
-		os.Stderr.Write([]byte(fmt.Sprintf("%v", s)))
+		if !diagnose(ERROR, "log_failure", map[string]interface{}{"error": err.Error(), "message": m}) {
+			s := fmt.Sprintf("[LOG FAILURE] - (Logger) %s -> \n%s\n", err, m)
+			os.Stderr.Write([]byte(fmt.Sprintf("%v", s)))
+		}
 		return 0, err
 	}
 	return n, err
 }
 
+// Unmarshals a single JSON value, keeping numbers as json.Number so large
+// integers, such as 64-bit IDs, aren't corrupted by a float64 conversion
+func unmarshal(p []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(v); err != nil {
+		return err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return errors.New("jog: invalid character after top-level value")
+	}
+	return nil
+}
+
 // Pulls the `level` value from the message to be logged
 func levelFrom(o interface{}) Level {
 	level := INFO
@@ -162,53 +499,67 @@ func (m *Message) String() string {
 
 func newMessage(l Level, d interface{}, depth int) *Message {
 	m := &Message{
-		Data:  d,
-		Level: l,
-		Time:  time.Now().UTC(),
-		File:  "???",
-		Line:  0,
+		Data:    d,
+		Level:   l,
+		Time:    time.Now().UTC(),
+		File:    "???",
+		Line:    0,
+		Version: SchemaVersion,
 	}
 
-	// Set filename/line number of invoker
-	if _, file, line, ok := runtime.Caller(depth); ok {
+	// Set filename/line number, and function, of invoker
+	if pc, file, line, ok := runtime.Caller(depth); ok {
 		m.File, m.Line = file, line
+		if f := runtime.FuncForPC(pc); f != nil {
+			m.Func = f.Name()
+		}
 	}
 
-	if d == nil {
-		return m
-	}
+	return m
+}
 
-	// Set Data/Message fields
-	if s, ok := d.(string); ok {
-		m.Data = s
-	} else if b, err := json.Marshal(d); err != nil || len(b) < 3 {
-		m.Data = fmt.Sprint(d)
+// MarshalJSON encodes the message, its Data being encoded once, by DataJSON
+func (m Message) MarshalJSON() ([]byte, error) {
+	d, err := m.DataJSON()
+	if err != nil {
+		return nil, err
 	}
-
-	return m
+	return json.Marshal(struct {
+		Data json.RawMessage `json:"data"`
+		*message
+	}{d, (*message)(&m)})
 }
 
 // NewWriter returns an io.Writer used to write custom log messages
-func NewWriter(l Logger) io.Writer {
-	return &Jog{l, 3}
+func NewWriter(l Logger, opts ...Option) io.Writer {
+	return NewWithDepth(l, 3, opts...)
 }
 
 // New returns a new Logger using a Jog logger
-func NewLoggerWithDepth(l Logger, depth int) *log.Logger {
-	return log.New(&Jog{l, depth}, "", 0)
+func NewLoggerWithDepth(l Logger, depth int, opts ...Option) *log.Logger {
+	return log.New(NewWithDepth(l, depth, opts...), "", 0)
 }
 
 // New returns a new Logger using a Jog logger
-func NewLogger(l Logger) *log.Logger {
-	return NewLoggerWithDepth(l, 3)
+func NewLogger(l Logger, opts ...Option) *log.Logger {
+	return NewLoggerWithDepth(l, 3, opts...)
 }
 
 // New returns a new Jog instance with a depth value for runtime.Caller
-func NewWithDepth(l Logger, depth int) *Jog {
-	return &Jog{l, depth}
+func NewWithDepth(l Logger, depth int, opts ...Option) *Jog {
+	j := &Jog{logger: l, Depth: depth, limits: newLimiter(), counts: new(counters), override: newOverrides(), maxWrite: MaxWrite, maxDepth: MaxDepth}
+	for _, o := range opts {
+		o(j)
+	}
+	return j
+}
+
+// Nop returns a Jog that discards everything logged to it
+func Nop() *Jog {
+	return New(Discard)
 }
 
 // New returns a new Jog instance
-func New(l Logger) *Jog {
-	return NewWithDepth(l, 3)
+func New(l Logger, opts ...Option) *Jog {
+	return NewWithDepth(l, 3, opts...)
 }
@@ -1,8 +1,10 @@
 package jog
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 )
 
 var (
@@ -56,6 +58,14 @@ type testLogger struct {
 	message *Message
 }
 
+// Passes the messages logged from other goroutines over a channel
+type chanLogger chan *Message
+
+func (c chanLogger) Log(m interface{}) (int, error) {
+	c <- m.(*Message)
+	return 0, nil
+}
+
 type levelTest struct {
 	expected Level
 	value    map[string]interface{}
@@ -99,11 +109,22 @@ func TestLevelFrom(t *testing.T) {
 	}
 }
 
+func TestLevelAtLeast(t *testing.T) {
+	if !ERROR.AtLeast(WARNING) || !ERROR.AtLeast(ERROR) {
+		t.Error("Expected ERROR to be at least WARNING")
+	}
+	if DEBUG.AtLeast(INFO) || UNKNOWN.AtLeast(DEBUG) {
+		t.Error("Expected DEBUG and UNKNOWN to be below INFO")
+	}
+}
+
 func TestNewMessage(t *testing.T) {
 	for _, v := range newMessageTest {
 		m := newMessage(v.level, v.message, v.depth)
-		if m.Data != v.expected {
-			t.Errorf("Expected '%v' got '%v'", v.expected, m.Data)
+		b, err := m.DataJSON()
+		expected, _ := json.Marshal(v.expected)
+		if err != nil || string(b) != string(expected) {
+			t.Errorf("Expected '%s' got '%s'", expected, b)
 		}
 	}
 }
@@ -136,3 +157,152 @@ func TestWrite(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteFiltered(t *testing.T) {
+	l := &anyLogger{}
+	j := New(l, WithLevel(WARNING))
+	p := []byte(`{"level":"debug"}` + "\n")
+	if n, err := j.Write(p); n != len(p) || err != nil || len(l.logged) != 0 {
+		t.Error("Expected a filtered Write to report the full length, got", n, err)
+	}
+}
+
+func TestNop(t *testing.T) {
+	j := Nop()
+	if j.Enabled(CRITICAL) {
+		t.Error("Expected Nop to have no levels enabled")
+	}
+	if n, err := j.Write([]byte("dropped\n")); n != 8 || err != nil {
+		t.Error("Expected Nop Write to report the full length, got", n, err)
+	}
+	if err := j.Critical("dropped"); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+}
+
+func BenchmarkNop(b *testing.B) {
+	j := Nop()
+	for i := 0; i < b.N; i++ {
+		j.Info("dropped")
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	now := time.Date(2013, 6, 1, 12, 0, 0, 0, time.UTC)
+	l := &testLogger{}
+	j := New(l, WithClock(func() time.Time { return now }))
+
+	j.Info("tick")
+	if !l.message.Time.Equal(now) {
+		t.Error("Expected", now, "got", l.message.Time)
+	}
+	j.Write([]byte("tock\n"))
+	if !l.message.Time.Equal(now) {
+		t.Error("Expected", now, "got", l.message.Time)
+	}
+}
+
+type anyLogger struct {
+	logged []interface{}
+}
+
+func (l *anyLogger) Log(m interface{}) (int, error) {
+	l.logged = append(l.logged, m)
+	return 0, nil
+}
+
+func TestWithRaw(t *testing.T) {
+	l := &anyLogger{}
+	log := NewLogger(l, WithRaw(), WithLevel(INFO))
+	log.Println(`{"z":1,"id":12345678901234567890,"level":"warning"}`)
+	log.Println(`{"level":"debug"}`)
+	log.Println("plain text")
+
+	if len(l.logged) != 2 {
+		t.Fatal("Expected 2 messages, got", len(l.logged))
+	}
+	raw, ok := l.logged[0].(json.RawMessage)
+	if !ok || string(raw) != `{"z":1,"id":12345678901234567890,"level":"warning"}` {
+		t.Errorf("Expected the line as is, got %T %s", l.logged[0], l.logged[0])
+	}
+	if m, ok := l.logged[1].(*Message); !ok || m.Data != "plain text" {
+		t.Error("Expected plain text to be wrapped in a Message, got", l.logged[1])
+	}
+}
+
+func TestWritePrecision(t *testing.T) {
+	l := &testLogger{}
+	j := New(l)
+	j.Write([]byte(`{"id":12345678901234567890,"ratio":0.1}` + "\n"))
+	b, _ := json.Marshal(l.message.Data)
+	if string(b) != `{"id":12345678901234567890,"ratio":0.1}` {
+		t.Error("Expected numbers to keep their precision, got", string(b))
+	}
+
+	j.Write([]byte(`{"a":1} {"b":2}`))
+	if _, ok := l.message.Data.(string); !ok {
+		t.Error("Expected trailing data to be logged as text, got", l.message.Data)
+	}
+}
+
+func TestWithRawField(t *testing.T) {
+	l := &testLogger{}
+	j := New(l, WithRawField())
+	j.Write([]byte(`{"level":"error","msg":"x"}` + "\n"))
+	if l.message.Raw != `{"level":"error","msg":"x"}` || l.message.Level != ERROR {
+		t.Error("Expected the original line, got", l.message.Raw)
+	}
+	j.Write([]byte("plain\n"))
+	if l.message.Raw != "" {
+		t.Error("Expected no raw field for text, got", l.message.Raw)
+	}
+}
+
+type bench struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Encodes each message, as a backend would
+type marshalLogger struct{}
+
+func (marshalLogger) Log(m interface{}) (int, error) {
+	b, err := json.Marshal(m)
+	return len(b), err
+}
+
+func benchmarkLog(b *testing.B, o interface{}) {
+	j := New(marshalLogger{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		j.Info(o)
+	}
+}
+
+func BenchmarkLogString(b *testing.B) {
+	benchmarkLog(b, "domo arigato mr roboto")
+}
+
+func BenchmarkLogStruct(b *testing.B) {
+	benchmarkLog(b, bench{"Jack", 42})
+}
+
+func BenchmarkLogMap(b *testing.B) {
+	benchmarkLog(b, map[string]interface{}{"name": "Jack", "count": 42})
+}
+
+func BenchmarkLogStringer(b *testing.B) {
+	benchmarkLog(b, dummy2{dummy1{"blah blah"}})
+}
+
+func TestFunc(t *testing.T) {
+	l := &testLogger{}
+	New(l).Info("x")
+	if l.message.Func != "code.minty.io/jog.TestFunc" {
+		t.Error("Expected the caller's function, got", l.message.Func)
+	}
+	New(l, WithShortFuncs()).Info("x")
+	if l.message.Func != "jog.TestFunc" {
+		t.Error("Expected the short function name, got", l.message.Func)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jogtest provides a recording jog.Logger for testing code that logs
+package jogtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+// Recorder is a jog.Logger that keeps every message logged to it.
+// It's safe for concurrent use.
+type Recorder struct {
+	mu   sync.Mutex
+	msgs []*jog.Message
+}
+
+// Log records the message
+func (r *Recorder) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return 0, fmt.Errorf("jogtest: unexpected message type %T", m)
+	}
+	r.mu.Lock()
+	r.msgs = append(r.msgs, msg)
+	r.mu.Unlock()
+	return 0, nil
+}
+
+// Entries returns the recorded messages, oldest first
+func (r *Recorder) Entries() []*jog.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*jog.Message(nil), r.msgs...)
+}
+
+// Last returns the most recent message, or nil when nothing has been logged
+func (r *Recorder) Last() *jog.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.msgs) == 0 {
+		return nil
+	}
+	return r.msgs[len(r.msgs)-1]
+}
+
+// LastLevel returns the Level of the most recent message, or an empty Level
+func (r *Recorder) LastLevel() jog.Level {
+	if m := r.Last(); m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+// Reset removes all recorded messages
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.msgs = nil
+	r.mu.Unlock()
+}
+
+// Logged reports whether a message with the given Level, whose Data contains
+// `substr`, was recorded
+func (r *Recorder) Logged(level jog.Level, substr string) bool {
+	for _, m := range r.Entries() {
+		if m.Level == level && Contains(m, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertLogged fails the test when no matching message was recorded, see Logged
+func (r *Recorder) AssertLogged(t testing.TB, level jog.Level, substr string) {
+	t.Helper()
+	if !r.Logged(level, substr) {
+		t.Errorf("jogtest: no %s message containing %q, got:\n%s", level, substr, r)
+	}
+}
+
+// AssertNotLogged fails the test when a matching message was recorded, see Logged
+func (r *Recorder) AssertNotLogged(t testing.TB, level jog.Level, substr string) {
+	t.Helper()
+	if r.Logged(level, substr) {
+		t.Errorf("jogtest: unexpected %s message containing %q", level, substr)
+	}
+}
+
+func (r *Recorder) String() string {
+	var s []string
+	for _, m := range r.Entries() {
+		s = append(s, fmt.Sprintf("  %s: %s", m.Level, text(m)))
+	}
+	return strings.Join(s, "\n")
+}
+
+// Contains reports whether the message's Data, as text or JSON, contains `substr`
+func Contains(m *jog.Message, substr string) bool {
+	return strings.Contains(text(m), substr) || strings.Contains(fmt.Sprint(m.Data), substr)
+}
+
+func text(m *jog.Message) string {
+	if s, ok := m.Data.(string); ok {
+		return s
+	}
+	b, err := m.DataJSON()
+	if err != nil {
+		return fmt.Sprint(m.Data)
+	}
+	return string(b)
+}
+
+// New returns a Jog that logs to a new Recorder
+func New(opts ...jog.Option) (*jog.Jog, *Recorder) {
+	r := new(Recorder)
+	return jog.New(r, opts...), r
+}
+
+// tb is a jog.Logger writing through a test's Log
+type tb struct {
+	t    testing.TB
+	mu   sync.Mutex
+	done bool
+}
+
+// Log writes the message with t.Log, dropping those logged after the test
+// completed, which would otherwise panic
+func (l *tb) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return 0, fmt.Errorf("jogtest: unexpected message type %T", m)
+	}
+	s := fmt.Sprintf("%s %s:%d: %s", strings.ToUpper(string(msg.Level)), filepath.Base(msg.File), msg.Line, text(msg))
+	if len(msg.Meta) > 0 {
+		if b, err := json.Marshal(msg.Meta); err == nil {
+			s += " " + string(b)
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.done {
+		l.t.Log(s)
+	}
+	return len(s), nil
+}
+
+// NewTB returns a Jog logging through `t.Log`, so the messages of the code under
+// test are interleaved with the test's output, and shown only when it fails (or
+// with `go test -v`)
+func NewTB(t testing.TB, opts ...jog.Option) *jog.Jog {
+	l := &tb{t: t}
+	t.Cleanup(func() {
+		l.mu.Lock()
+		l.done = true
+		l.mu.Unlock()
+	})
+	return jog.New(l, opts...)
+}
@@ -0,0 +1,54 @@
+package jogtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestRecorder(t *testing.T) {
+	j, r := New()
+	j.Warning("disk almost full")
+	j.Error(map[string]interface{}{"user": "jack", "reason": "bad password"})
+
+	if n := len(r.Entries()); n != 2 {
+		t.Fatal("Expected 2 entries, got", n)
+	}
+	if l := r.LastLevel(); l != jog.ERROR {
+		t.Error("Expected last level to be ERROR, got", l)
+	}
+	r.AssertLogged(t, jog.WARNING, "almost full")
+	r.AssertLogged(t, jog.ERROR, `"user":"jack"`)
+	r.AssertNotLogged(t, jog.INFO, "disk")
+
+	r.Reset()
+	if r.Last() != nil || r.LastLevel() != "" {
+		t.Error("Expected no entries after Reset")
+	}
+}
+
+type fakeTB struct {
+	testing.TB
+	logs    []string
+	cleanup func()
+}
+
+func (f *fakeTB) Log(args ...interface{}) { f.logs = append(f.logs, fmt.Sprint(args...)) }
+func (f *fakeTB) Cleanup(fn func())       { f.cleanup = fn }
+
+func TestNewTB(t *testing.T) {
+	f := &fakeTB{TB: t}
+	j := NewTB(f)
+	j.Warning("disk almost full")
+	if len(f.logs) != 1 || !strings.HasPrefix(f.logs[0], "WARNING jogtest_test.go:") || !strings.HasSuffix(f.logs[0], ": disk almost full") {
+		t.Error("Expected the message through Log, got", f.logs)
+	}
+
+	f.cleanup()
+	j.Info("after the test")
+	if len(f.logs) != 1 {
+		t.Error("Expected messages after the test to be dropped, got", f.logs)
+	}
+}
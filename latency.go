@@ -0,0 +1,86 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Bucket bounds grow by a quarter power of 2 from 10µs, reaching over 5 minutes
+const (
+	latencyMin     = 10 * time.Microsecond
+	latencyBuckets = 100
+)
+
+// Latency is a histogram of durations, such as those of a backend's Log calls,
+// reporting percentiles in fixed memory. Percentiles are the upper bound of their
+// bucket, so are within 19% of the actual value. The zero value is ready to use.
+type Latency struct {
+	mu     sync.Mutex
+	counts [latencyBuckets + 1]uint64
+	n      uint64
+	max    time.Duration
+}
+
+// LatencyStats are the percentiles of a Latency
+type LatencyStats struct {
+	Count uint64        `json:"count"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+// Observe adds a duration to the histogram
+func (l *Latency) Observe(d time.Duration) {
+	i := 0
+	if d > latencyMin {
+		i = int(math.Ceil(4 * math.Log2(float64(d)/float64(latencyMin))))
+		if i > latencyBuckets {
+			i = latencyBuckets
+		}
+	}
+	l.mu.Lock()
+	l.counts[i]++
+	l.n++
+	if d > l.max {
+		l.max = d
+	}
+	l.mu.Unlock()
+}
+
+// Since observes the time since `start`, eg. `defer l.Since(time.Now())`
+func (l *Latency) Since(start time.Time) {
+	l.Observe(time.Since(start))
+}
+
+// Stats returns the percentiles of the observed durations
+func (l *Latency) Stats() LatencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := LatencyStats{Count: l.n, Max: l.max}
+	if l.n == 0 {
+		return s
+	}
+	s.P50, s.P95, s.P99 = l.percentile(0.5), l.percentile(0.95), l.percentile(0.99)
+	return s
+}
+
+func (l *Latency) percentile(p float64) time.Duration {
+	rank := uint64(math.Ceil(p * float64(l.n)))
+	var seen uint64
+	for i, c := range l.counts {
+		if seen += c; seen >= rank {
+			d := time.Duration(float64(latencyMin) * math.Exp2(float64(i)/4))
+			if d > l.max {
+				d = l.max
+			}
+			return d
+		}
+	}
+	return l.max
+}
@@ -0,0 +1,23 @@
+package jog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatency(t *testing.T) {
+	var l Latency
+	if s := l.Stats(); s.Count != 0 || s.P99 != 0 {
+		t.Error("Expected empty stats, got", s)
+	}
+	for i := 1; i <= 100; i++ {
+		l.Observe(time.Duration(i) * time.Millisecond)
+	}
+	s := l.Stats()
+	within := func(d, want time.Duration) bool {
+		return d >= want && float64(d) <= float64(want)*1.19
+	}
+	if s.Count != 100 || s.Max != 100*time.Millisecond || !within(s.P50, 50*time.Millisecond) || !within(s.P95, 95*time.Millisecond) || s.P99 < 99*time.Millisecond {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+}
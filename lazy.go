@@ -0,0 +1,45 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// LazyValue holds a function whose result is only computed when the message
+// containing it is encoded. See Lazy.
+type LazyValue struct {
+	once sync.Once
+	fn   func() interface{}
+	v    interface{}
+}
+
+// Lazy wraps an expensive computation so it's only evaluated if the message
+// passes level filtering, and is actually encoded by a Logger.
+// The function is called at most once.
+//
+//	j.Debug(jog.Lazy(func() interface{} { return dumpState() }))
+func Lazy(fn func() interface{}) *LazyValue {
+	return &LazyValue{fn: fn}
+}
+
+// Value evaluates, and returns, the lazy value
+func (l *LazyValue) Value() interface{} {
+	l.once.Do(func() {
+		l.v = l.fn()
+	})
+	return l.v
+}
+
+// MarshalJSON encodes the evaluated value
+func (l *LazyValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.Value())
+}
+
+func (l *LazyValue) String() string {
+	return fmt.Sprint(l.Value())
+}
@@ -0,0 +1,35 @@
+package jog
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	calls := 0
+	fn := func() interface{} {
+		calls++
+		return map[string]interface{}{"state": "expensive"}
+	}
+	l := &testLogger{}
+	j := New(l, WithLevel(INFO))
+
+	j.Debug(Lazy(fn))
+	if calls != 0 {
+		t.Error("Expected filtered lazy value to not be evaluated, got", calls, "calls")
+	}
+
+	j.Info(Lazy(fn))
+	if calls != 0 {
+		t.Error("Expected lazy value to be evaluated when encoded, got", calls, "calls")
+	}
+	b, _ := json.Marshal(l.message)
+	json.Marshal(l.message)
+	if calls != 1 {
+		t.Error("Expected lazy value to be evaluated once, got", calls, "calls")
+	}
+	var m struct{ Data map[string]string }
+	if json.Unmarshal(b, &m); m.Data["state"] != "expensive" {
+		t.Error("Expected lazy value to be encoded, got", string(b))
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"sync"
+	"time"
+)
+
+// Tracks how often keyed messages have been logged, for the Once/FirstN/Every helpers
+type limiter struct {
+	mu     sync.Mutex
+	counts map[string]int
+	last   map[string]time.Time
+}
+
+// Reports whether the key has been seen fewer than `n` times, counting this call
+func (l *limiter) first(key string, n int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.counts[key] >= n {
+		return false
+	}
+	l.counts[key]++
+	return true
+}
+
+// Reports whether at least `d` has passed since the key was last allowed
+func (l *limiter) every(key string, d time.Duration, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t, ok := l.last[key]; ok && now.Sub(t) < d {
+		return false
+	}
+	l.last[key] = now
+	return true
+}
+
+func newLimiter() *limiter {
+	return &limiter{
+		counts: make(map[string]int),
+		last:   make(map[string]time.Time),
+	}
+}
+
+// LogOnce logs the object only the first time the key is used.
+// Keys are shared with LogFirstN.
+func (j *Jog) LogOnce(l Level, key string, o interface{}) (int, error) {
+	return j.firstN(l, 1, key, o)
+}
+
+// LogFirstN logs the object only for the first `n` uses of the key
+func (j *Jog) LogFirstN(l Level, n int, key string, o interface{}) (int, error) {
+	return j.firstN(l, n, key, o)
+}
+
+// LogEvery logs the object at most once per duration `d` for the key
+func (j *Jog) LogEvery(l Level, d time.Duration, key string, o interface{}) (int, error) {
+	return j.every(l, d, key, o)
+}
+
+// Disabled levels don't count against the key
+func (j *Jog) firstN(l Level, n int, key string, o interface{}) (int, error) {
+	if !j.Enabled(l) || !j.limits.first(key, n) {
+		return 0, nil
+	}
+	return j.output(j.Depth, l, o)
+}
+
+func (j *Jog) every(l Level, d time.Duration, key string, o interface{}) (int, error) {
+	if !j.Enabled(l) || !j.limits.every(key, d, j.now()) {
+		return 0, nil
+	}
+	return j.output(j.Depth, l, o)
+}
+
+// Log a critical message by the given object, only the first time the key is used
+func (j *Jog) CriticalOnce(key string, o interface{}) error {
+	_, err := j.firstN(CRITICAL, 1, key, o)
+	return err
+}
+
+// Log a critical message by the given object, only for the first `n` uses of the key
+func (j *Jog) CriticalFirstN(n int, key string, o interface{}) error {
+	_, err := j.firstN(CRITICAL, n, key, o)
+	return err
+}
+
+// Log a critical message by the given object, at most once per duration `d` for the key
+func (j *Jog) CriticalEvery(d time.Duration, key string, o interface{}) error {
+	_, err := j.every(CRITICAL, d, key, o)
+	return err
+}
+
+// Log a error message by the given object, only the first time the key is used
+func (j *Jog) ErrorOnce(key string, o interface{}) error {
+	_, err := j.firstN(ERROR, 1, key, o)
+	return err
+}
+
+// Log a error message by the given object, only for the first `n` uses of the key
+func (j *Jog) ErrorFirstN(n int, key string, o interface{}) error {
+	_, err := j.firstN(ERROR, n, key, o)
+	return err
+}
+
+// Log a error message by the given object, at most once per duration `d` for the key
+func (j *Jog) ErrorEvery(d time.Duration, key string, o interface{}) error {
+	_, err := j.every(ERROR, d, key, o)
+	return err
+}
+
+// Log a warning message by the given object, only the first time the key is used
+func (j *Jog) WarningOnce(key string, o interface{}) error {
+	_, err := j.firstN(WARNING, 1, key, o)
+	return err
+}
+
+// Log a warning message by the given object, only for the first `n` uses of the key
+func (j *Jog) WarningFirstN(n int, key string, o interface{}) error {
+	_, err := j.firstN(WARNING, n, key, o)
+	return err
+}
+
+// Log a warning message by the given object, at most once per duration `d` for the key
+func (j *Jog) WarningEvery(d time.Duration, key string, o interface{}) error {
+	_, err := j.every(WARNING, d, key, o)
+	return err
+}
+
+// Log a info message by the given object, only the first time the key is used
+func (j *Jog) InfoOnce(key string, o interface{}) error {
+	_, err := j.firstN(INFO, 1, key, o)
+	return err
+}
+
+// Log a info message by the given object, only for the first `n` uses of the key
+func (j *Jog) InfoFirstN(n int, key string, o interface{}) error {
+	_, err := j.firstN(INFO, n, key, o)
+	return err
+}
+
+// Log a info message by the given object, at most once per duration `d` for the key
+func (j *Jog) InfoEvery(d time.Duration, key string, o interface{}) error {
+	_, err := j.every(INFO, d, key, o)
+	return err
+}
+
+// Log a debug message by the given object, only the first time the key is used
+func (j *Jog) DebugOnce(key string, o interface{}) error {
+	_, err := j.firstN(DEBUG, 1, key, o)
+	return err
+}
+
+// Log a debug message by the given object, only for the first `n` uses of the key
+func (j *Jog) DebugFirstN(n int, key string, o interface{}) error {
+	_, err := j.firstN(DEBUG, n, key, o)
+	return err
+}
+
+// Log a debug message by the given object, at most once per duration `d` for the key
+func (j *Jog) DebugEvery(d time.Duration, key string, o interface{}) error {
+	_, err := j.every(DEBUG, d, key, o)
+	return err
+}
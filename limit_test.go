@@ -0,0 +1,37 @@
+package jog
+
+import (
+	"testing"
+	"time"
+)
+
+type countLogger struct {
+	count int
+}
+
+func (l *countLogger) Log(m interface{}) (int, error) {
+	l.count++
+	return 0, nil
+}
+
+func TestLimits(t *testing.T) {
+	l := &countLogger{}
+	j := New(l, WithLevel(INFO))
+
+	for i := 0; i < 5; i++ {
+		j.InfoOnce("once", i)
+		j.WarningFirstN(3, "first", i)
+		j.ErrorEvery(time.Hour, "every", i)
+		j.DebugOnce("debug", i)
+	}
+	if l.count != 5 {
+		t.Error("Expected 5 messages, got", l.count)
+	}
+
+	// Disabled levels don't use up the key
+	j.level = DEBUG
+	j.DebugOnce("debug", "now")
+	if l.count != 6 {
+		t.Error("Expected disabled level to not count against the key, got", l.count)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Summary is the Data of a message emitted by Aggregate for repeated messages
+type Summary struct {
+	Count int       `json:"count"`
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+	// Sample is the first message's Data, as encoded by its DataJSON
+	Sample json.RawMessage `json:"sample"`
+}
+
+type group struct {
+	count       int
+	first, last time.Time
+	sample      *jog.Message
+	data        json.RawMessage
+}
+
+// Aggregate is a jog.Logger that groups identical messages over a window.
+// The first occurrence is logged as is, any repeats are logged as a single
+// Summary message when the window ends.
+type Aggregate struct {
+	logger jog.Logger
+	level  jog.Level
+	mu     sync.Mutex
+	groups map[string]*group
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// Log passes the message on, unless it's a repeat within the current window
+func (a *Aggregate) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok || !msg.Level.AtLeast(a.level) {
+		return a.logger.Log(m)
+	}
+
+	// DataJSON, unlike json.Marshal, tells errors apart
+	b, err := msg.DataJSON()
+	if err != nil {
+		return a.logger.Log(m)
+	}
+	key := fmt.Sprintf("%s:%s:%d:%s", msg.Level, msg.File, msg.Line, b)
+
+	a.mu.Lock()
+	g, ok := a.groups[key]
+	if !ok {
+		a.groups[key] = &group{1, msg.Time, msg.Time, msg.Clone(), b}
+		a.mu.Unlock()
+		return a.logger.Log(m)
+	}
+	g.count++
+	g.last = msg.Time
+	a.mu.Unlock()
+	return 0, nil
+}
+
+// Flush logs a summary for every group with repeats, and starts a new window
+func (a *Aggregate) Flush() error {
+	a.mu.Lock()
+	groups := a.groups
+	a.groups = make(map[string]*group)
+	a.mu.Unlock()
+
+	var err error
+	for _, g := range groups {
+		if g.count < 2 {
+			continue
+		}
+		s := *g.sample
+		s.Time = g.last
+		s.Data = Summary{g.count, g.first, g.last, g.data}
+		if _, e := a.logger.Log(&s); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Close stops the window timer and logs any pending summaries
+func (a *Aggregate) Close() error {
+	close(a.stop)
+	<-a.done
+	return a.Flush()
+}
+
+func (a *Aggregate) run(window time.Duration) {
+	defer close(a.done)
+	t := time.NewTicker(window)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			a.Flush()
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+// NewAggregate returns a new Aggregate that groups messages at, or above, `level`
+// over each `window` before logging to `l`.
+func NewAggregate(l jog.Logger, level jog.Level, window time.Duration) *Aggregate {
+	a := &Aggregate{
+		logger: l,
+		level:  level,
+		groups: make(map[string]*group),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go a.run(window)
+	return a
+}
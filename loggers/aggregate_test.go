@@ -0,0 +1,73 @@
+package loggers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+func TestAggregate(t *testing.T) {
+	l := &testLogger{}
+	a := NewAggregate(l, jog.ERROR, time.Hour)
+
+	for i := 0; i < 10; i++ {
+		m := msg("db down")
+		m.Level = jog.ERROR
+		a.Log(m)
+		a.Log(msg("info is passed through"))
+	}
+	if n := l.count(); n != 11 {
+		t.Error("Expected the first error and all info messages, got", n)
+	}
+
+	a.Close()
+	if n := l.count(); n != 12 {
+		t.Fatal("Expected a summary message, got", n)
+	}
+	s, ok := l.messages[11].(*jog.Message).Data.(Summary)
+	if !ok || s.Count != 10 || string(s.Sample) != `"db down"` {
+		t.Errorf("Expected summary of 10 messages, got %+v", s)
+	}
+}
+
+func TestAggregateSampleOwned(t *testing.T) {
+	l := &testLogger{}
+	a := NewAggregate(l, jog.ERROR, time.Hour)
+
+	m := msg(map[string]interface{}{"err": "db down"})
+	m.Level = jog.ERROR
+	a.Log(m)
+	a.Log(m.Clone())
+	// Downstream loggers, or a pool, may reuse the message once it's logged
+	m.Data.(map[string]interface{})["err"] = "reused"
+
+	a.Close()
+	s := l.messages[1].(*jog.Message).Data.(Summary)
+	if d := string(s.Sample); d != `{"err":"db down"}` {
+		t.Error("Expected the sample to be unaffected by the logged message, got", d)
+	}
+}
+
+func TestAggregateErrors(t *testing.T) {
+	l := &testLogger{}
+	a := NewAggregate(l, jog.ERROR, time.Hour)
+
+	// Logged from the same call site
+	for _, err := range []error{errors.New("disk full"), errors.New("conn refused"), errors.New("conn refused")} {
+		m := msg(err)
+		m.Level = jog.ERROR
+		a.Log(m)
+	}
+	if n := l.count(); n != 2 {
+		t.Error("Expected each error to be logged, got", n)
+	}
+
+	a.Close()
+	s := l.messages[2].(*jog.Message).Data.(Summary)
+	if s.Count != 2 || !strings.Contains(string(s.Sample), "conn refused") {
+		t.Errorf("Expected a summary of the repeated error, got %d %s", s.Count, s.Sample)
+	}
+}
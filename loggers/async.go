@@ -0,0 +1,319 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Policy decides what an Async logger does with a message when its queue is full
+type Policy int
+
+const (
+	// Block the caller until there is room in the queue
+	Block Policy = iota
+	// DropNewest discards the message being logged
+	DropNewest
+	// DropOldest discards the oldest queued message to make room
+	DropOldest
+	// Spill writes the message to a disk spool, which is drained once the queue empties
+	Spill
+)
+
+func (p Policy) String() string {
+	switch p {
+	case DropNewest:
+		return "drop_newest"
+	case DropOldest:
+		return "drop_oldest"
+	case Spill:
+		return "spill"
+	}
+	return "block"
+}
+
+// ErrClosed is returned when logging to a closed logger
+var ErrClosed = errors.New("jog: logger is closed")
+
+// AsyncConfig holds the settings for an Async logger
+type AsyncConfig struct {
+	// Size is the capacity of the queue (defaults to 1024)
+	Size int
+	// Policy is applied when the queue is full
+	Policy Policy
+	// Spool is the file path used by the Spill policy
+	Spool string
+	// Bypass, when set, is the level at which messages skip the queue and are
+	// logged synchronously by the caller (eg. jog.ERROR)
+	Bypass jog.Level
+	// DrainRate, when set, ramps up the logging of spooled messages once the queue
+	// empties, so a backend recovering from an outage isn't sent its backlog at
+	// once. It starts at DrainRate messages a second, doubling every second up to
+	// DrainMax (unlimited when zero).
+	DrainRate int
+	DrainMax  int
+	// SpoolTTL is how long spilled messages of each level are kept, see Spool.SetTTL
+	SpoolTTL map[jog.Level]time.Duration
+}
+
+// AsyncStats counts the messages affected by the queue's Policy
+type AsyncStats struct {
+	Blocked       uint64
+	DroppedNewest uint64
+	DroppedOldest uint64
+	Spilled       uint64
+	Failed        uint64
+}
+
+// Async is a jog.Logger that queues messages and logs them to another Logger
+// from a background goroutine.
+type Async struct {
+	stats  AsyncStats
+	full   int32
+	logger jog.Logger
+	policy Policy
+	bypass jog.Level
+	rate   int
+	max    int
+	queue  chan interface{}
+	spool  *Spool
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// Log queues the message, applying the Policy when the queue is full
+func (a *Async) Log(m interface{}) (int, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return 0, ErrClosed
+	}
+	if a.urgent(m) {
+		return a.logger.Log(m)
+	}
+
+	select {
+	case a.queue <- m:
+		return 0, nil
+	default:
+	}
+
+	if atomic.CompareAndSwapInt32(&a.full, 0, 1) {
+		jog.Diagnose(jog.WARNING, "queue_full", map[string]interface{}{"size": cap(a.queue), "policy": a.policy.String()})
+	}
+	switch a.policy {
+	case DropNewest:
+		atomic.AddUint64(&a.stats.DroppedNewest, 1)
+	case DropOldest:
+		for {
+			select {
+			case a.queue <- m:
+				return 0, nil
+			default:
+			}
+			select {
+			case <-a.queue:
+				atomic.AddUint64(&a.stats.DroppedOldest, 1)
+			default:
+			}
+		}
+	case Spill:
+		if err := a.spool.Append(m); err != nil {
+			return 0, err
+		}
+		atomic.AddUint64(&a.stats.Spilled, 1)
+	default:
+		atomic.AddUint64(&a.stats.Blocked, 1)
+		a.queue <- m
+	}
+	return 0, nil
+}
+
+// Stats returns a snapshot of the policy counters
+func (a *Async) Stats() AsyncStats {
+	return AsyncStats{
+		Blocked:       atomic.LoadUint64(&a.stats.Blocked),
+		DroppedNewest: atomic.LoadUint64(&a.stats.DroppedNewest),
+		DroppedOldest: atomic.LoadUint64(&a.stats.DroppedOldest),
+		Spilled:       atomic.LoadUint64(&a.stats.Spilled),
+		Failed:        atomic.LoadUint64(&a.stats.Failed),
+	}
+}
+
+// Len returns the number of queued messages
+func (a *Async) Len() int {
+	return len(a.queue)
+}
+
+// Dropped returns the number of messages discarded by the Policy, or that failed
+// to be logged, see jog.Dropper
+func (a *Async) Dropped() uint64 {
+	s := a.Stats()
+	return s.DroppedNewest + s.DroppedOldest + s.Failed
+}
+
+// Close stops accepting messages and waits for the queue, and spool, to be logged
+func (a *Async) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	close(a.queue)
+	a.mu.Unlock()
+
+	<-a.done
+	if n := a.Dropped(); n > 0 {
+		jog.Diagnose(jog.WARNING, "dropped", map[string]interface{}{"count": n})
+	}
+	if a.spool != nil {
+		return a.spool.Close()
+	}
+	return nil
+}
+
+// Whether the message should bypass the queue
+func (a *Async) urgent(m interface{}) bool {
+	if a.bypass == "" {
+		return false
+	}
+	msg, ok := m.(*jog.Message)
+	return ok && msg.Level.AtLeast(a.bypass)
+}
+
+func (a *Async) run() {
+	defer close(a.done)
+	for m := range a.queue {
+		if _, ok := a.logger.(jog.BatchLogger); ok {
+			a.sendBatch(m)
+		} else {
+			a.send(m)
+		}
+		if len(a.queue) == 0 {
+			atomic.StoreInt32(&a.full, 0)
+			a.drain()
+		}
+	}
+	a.drain()
+}
+
+// The most queued messages passed to a BatchLogger at once
+const asyncBatch = 512
+
+// Sends the message along with those already queued behind it as one batch
+func (a *Async) sendBatch(m interface{}) {
+	var msgs []*jog.Message
+	for {
+		if msg, ok := m.(*jog.Message); ok {
+			msgs = append(msgs, msg)
+		} else {
+			a.send(m)
+		}
+		if len(msgs) == asyncBatch {
+			break
+		}
+		var ok bool
+		select {
+		case m, ok = <-a.queue:
+		default:
+		}
+		if !ok {
+			break
+		}
+	}
+	a.log(msgs)
+}
+
+// Logs the messages as a batch, counting them all as failed on an error
+func (a *Async) log(msgs []*jog.Message) {
+	if len(msgs) == 0 {
+		return
+	}
+	if _, err := jog.LogBatch(a.logger, msgs); err != nil {
+		atomic.AddUint64(&a.stats.Failed, uint64(len(msgs)))
+	}
+}
+
+// Logs everything that spilled over to disk
+func (a *Async) drain() {
+	if a.spool == nil || a.spool.Len() == 0 {
+		return
+	}
+	msgs, err := a.spool.Drain()
+	if err != nil {
+		atomic.AddUint64(&a.stats.Failed, 1)
+		return
+	}
+	_, batching := a.logger.(jog.BatchLogger)
+	rate := a.rate
+	for tick := 1; len(msgs) > 0; tick++ {
+		n := len(msgs)
+		if rate > 0 && n > (rate+9)/10 {
+			n = (rate + 9) / 10
+		}
+		if batching {
+			a.log(msgs[:n])
+		} else {
+			for _, m := range msgs[:n] {
+				a.send(m)
+			}
+		}
+		msgs = msgs[n:]
+		if rate == 0 || len(msgs) == 0 {
+			continue
+		}
+		time.Sleep(drainTick)
+		if tick%10 == 0 {
+			if rate *= 2; a.max > 0 && rate > a.max {
+				rate = a.max
+			}
+		}
+	}
+}
+
+// The interval at which a ramped drain logs a tenth of its rate
+var drainTick = 100 * time.Millisecond
+
+func (a *Async) send(m interface{}) {
+	if _, err := a.logger.Log(m); err != nil {
+		atomic.AddUint64(&a.stats.Failed, 1)
+	}
+}
+
+// NewAsync returns a new Async logger that logs to `l`
+func NewAsync(l jog.Logger, c AsyncConfig) (*Async, error) {
+	if c.Size <= 0 {
+		c.Size = 1024
+	}
+	a := &Async{
+		logger: l,
+		policy: c.Policy,
+		bypass: c.Bypass,
+		rate:   c.DrainRate,
+		max:    c.DrainMax,
+		queue:  make(chan interface{}, c.Size),
+		done:   make(chan struct{}),
+	}
+	if c.Policy == Spill {
+		if c.Spool == "" {
+			return nil, errors.New("jog: the Spill policy requires a spool path")
+		}
+		s, err := OpenSpool(c.Spool)
+		if err != nil {
+			return nil, err
+		}
+		s.SetTTL(c.SpoolTTL)
+		a.spool = s
+	}
+	go a.run()
+	return a, nil
+}
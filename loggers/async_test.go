@@ -0,0 +1,153 @@
+package loggers
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+type testLogger struct {
+	sync.Mutex
+	messages []interface{}
+	gate     chan struct{}
+}
+
+func (l *testLogger) Log(m interface{}) (int, error) {
+	if l.gate != nil {
+		<-l.gate
+	}
+	l.Lock()
+	l.messages = append(l.messages, m)
+	l.Unlock()
+	return 0, nil
+}
+
+func (l *testLogger) count() int {
+	l.Lock()
+	defer l.Unlock()
+	return len(l.messages)
+}
+
+func msg(d interface{}) *jog.Message {
+	return &jog.Message{Data: d, Level: jog.INFO}
+}
+
+func TestAsyncDropNewest(t *testing.T) {
+	l := &testLogger{gate: make(chan struct{})}
+	a, err := NewAsync(l, AsyncConfig{Size: 2, Policy: DropNewest})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The logger is held, so at most three messages fit and the rest drop
+	for i := 0; i < 6; i++ {
+		a.Log(msg(i))
+	}
+	close(l.gate)
+	if s := a.Stats(); s.DroppedNewest == 0 {
+		t.Error("Expected dropped messages, got", s)
+	}
+	a.Close()
+	if n := uint64(l.count()) + a.Stats().DroppedNewest; n != 6 {
+		t.Error("Expected 6 messages accounted for, got", n)
+	}
+}
+
+func TestAsyncSpill(t *testing.T) {
+	dir, err := os.MkdirTemp("", "jog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := &testLogger{}
+	a, err := NewAsync(l, AsyncConfig{Size: 1, Policy: Spill, Spool: filepath.Join(dir, "spool")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		a.Log(msg(i))
+	}
+	a.Close()
+	if n := l.count(); n != 50 {
+		t.Error("Expected 50 messages logged, got", n, a.Stats())
+	}
+}
+
+func TestAsyncBypass(t *testing.T) {
+	l := &testLogger{}
+	a, err := NewAsync(l, AsyncConfig{Size: 1, Bypass: jog.ERROR})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	m := msg("kaboom")
+	m.Level = jog.CRITICAL
+	a.Log(m)
+	if n := l.count(); n != 1 {
+		t.Error("Expected CRITICAL message to be logged synchronously, got", n)
+	}
+}
+
+type batchLogger struct {
+	testLogger
+	batches int
+}
+
+func (l *batchLogger) LogBatch(msgs []*jog.Message) (int, error) {
+	<-l.gate
+	l.Lock()
+	l.batches++
+	for _, m := range msgs {
+		l.messages = append(l.messages, m)
+	}
+	l.Unlock()
+	return 0, nil
+}
+
+func TestAsyncBatch(t *testing.T) {
+	l := &batchLogger{testLogger: testLogger{gate: make(chan struct{})}}
+	a, err := NewAsync(l, AsyncConfig{Size: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first batch is held, so the rest queue up behind it
+	for i := 0; i < 10; i++ {
+		a.Log(msg(i))
+	}
+	close(l.gate)
+	a.Close()
+
+	if l.count() != 10 || l.batches == 0 || l.batches > 2 {
+		t.Errorf("Expected 10 messages in at most 2 batches, got %d in %d", l.count(), l.batches)
+	}
+}
+
+func TestAsyncDrainRate(t *testing.T) {
+	l := &testLogger{gate: make(chan struct{})}
+	a, err := NewAsync(l, AsyncConfig{Size: 1, Policy: Spill, Spool: filepath.Join(t.TempDir(), "spool"), DrainRate: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The logger is held, as though it were down, so messages spill to disk
+	for i := 0; i < 40; i++ {
+		a.Log(msg(i))
+	}
+	start := time.Now()
+	close(l.gate)
+	a.Close()
+	if n := l.count(); n != 40 {
+		t.Error("Expected 40 messages logged, got", n, a.Stats())
+	}
+	// 10 messages a tick, so at least 3 ticks
+	if d := time.Since(start); d < 3*drainTick {
+		t.Error("Expected the spool to be drained gradually, took", d)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"code.minty.io/jog"
+)
+
+// Audit is a jog.Logger that chains messages together for tamper evidence.
+// Each message gets a `prev_hash` Meta field, the SHA-256 of the previous entry, so
+// altering, removing or reordering entries breaks the chain (see VerifyChain).
+type Audit struct {
+	logger jog.Logger
+	mu     sync.Mutex
+	last   string
+}
+
+// ChainError reports the entry, counting from 1, at which a chain is broken
+type ChainError struct {
+	Entry int
+	Err   error
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("jog: audit chain broken at entry %d: %s", e.Entry, e.Err)
+}
+
+// Log links the message to the previous one and logs it.
+// The chain only advances when the message is logged successfully.
+func (a *Audit) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return a.logger.Log(m)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	msg.SetMeta("prev_hash", a.last)
+	h, err := entryHash(msg)
+	if err != nil {
+		return 0, err
+	}
+	n, err := a.logger.Log(msg)
+	if err == nil {
+		a.last = h
+	}
+	return n, err
+}
+
+// Last returns the hash of the last entry, which is persisted to resume the chain
+func (a *Audit) Last() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.last
+}
+
+// Decodes the JSON form of `v` into plain values, keeping numbers as is
+func decodeJSON(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var o interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	err = d.Decode(&o)
+	return o, err
+}
+
+// Canonical JSON of `v`; keys sorted, numbers kept as is
+func canonicalJSON(v interface{}) ([]byte, error) {
+	o, err := decodeJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(o)
+}
+
+// SHA-256 of the canonical JSON of an entry
+func entryHash(v interface{}) (string, error) {
+	b, err := canonicalJSON(v)
+	if err != nil {
+		return "", err
+	}
+	s := sha256.Sum256(b)
+	return hex.EncodeToString(s[:]), nil
+}
+
+// VerifyChain checks the chain of newline delimited JSON entries read from `r`,
+// such as an audit log file, starting from the `prev` hash ("" for a new chain).
+// It returns the hash of the last entry, to verify any following entries.
+func VerifyChain(r io.Reader, prev string) (string, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 16<<20)
+	n := 0
+	for s.Scan() {
+		line := bytes.TrimSpace(s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		n++
+		var e struct {
+			Meta struct {
+				PrevHash *string `json:"prev_hash"`
+			} `json:"meta"`
+		}
+		if err := json.Unmarshal(line, &e); err != nil {
+			return "", &ChainError{n, err}
+		}
+		if e.Meta.PrevHash == nil {
+			return "", &ChainError{n, fmt.Errorf("missing prev_hash")}
+		}
+		if *e.Meta.PrevHash != prev {
+			return "", &ChainError{n, fmt.Errorf("prev_hash %q doesn't match %q", *e.Meta.PrevHash, prev)}
+		}
+		h, err := entryHash(json.RawMessage(line))
+		if err != nil {
+			return "", &ChainError{n, err}
+		}
+		prev = h
+	}
+	return prev, s.Err()
+}
+
+// NewAudit returns a new Audit logger that logs to `l`, continuing the chain
+// from the `last` hash ("" to start a new chain)
+func NewAudit(l jog.Logger, last string) *Audit {
+	return &Audit{logger: l, last: last}
+}
@@ -0,0 +1,46 @@
+package loggers
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestAudit(t *testing.T) {
+	var buf bytes.Buffer
+	l := lineLogger{&buf}
+	a := NewAudit(l, "")
+	for _, d := range []interface{}{"one", map[string]interface{}{"id": 9007199254740993}, "three"} {
+		if _, err := a.Log(msg(d)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	last, err := VerifyChain(strings.NewReader(buf.String()), "")
+	if err != nil || last != a.Last() {
+		t.Error("Expected a valid chain ending at", a.Last(), "got", last, err)
+	}
+
+	// Tamper with the second entry
+	lines := strings.SplitAfter(buf.String(), "\n")
+	lines[1] = strings.Replace(lines[1], "9007199254740993", "1", 1)
+	_, err = VerifyChain(strings.NewReader(strings.Join(lines, "")), "")
+	var ce *ChainError
+	if !errors.As(err, &ce) || ce.Entry != 3 {
+		t.Error("Expected the chain to break at entry 3, got", err)
+	}
+}
+
+// Writes messages as JSON lines
+type lineLogger struct{ w *bytes.Buffer }
+
+func (l lineLogger) Log(m interface{}) (int, error) {
+	b, err := jog.JSON.Encode(m.(*jog.Message))
+	if err != nil {
+		return 0, err
+	}
+	return l.w.Write(b)
+}
@@ -0,0 +1,22 @@
+package loggers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// From the AWS Signature Version 4 test suite, `get-vanilla`
+func TestSignV4(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	c := AWSCredentials{AccessKey: "AKIDEXAMPLE", SecretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, nil, "service", "us-east-1", c, now)
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if a := req.Header.Get("Authorization"); a != expected {
+		t.Error("Expected", expected, "got", a)
+	}
+}
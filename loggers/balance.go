@@ -0,0 +1,195 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"errors"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Strategy decides which endpoint a Balancer sends a message to
+type Strategy int
+
+const (
+	// RoundRobin rotates through the endpoints
+	RoundRobin Strategy = iota
+	// Hash sends messages with the same key, see BalanceConfig.Key, to the same endpoint
+	Hash
+	// Failover sends to the first healthy endpoint, in order
+	Failover
+)
+
+// Endpoint is a named Logger used by a Balancer
+type Endpoint struct {
+	Name   string
+	Logger jog.Logger
+}
+
+// EndpointHealth is the state of an endpoint
+type EndpointHealth struct {
+	Name      string
+	Healthy   bool
+	Failures  uint64
+	LastError string
+	// Latency of the endpoint's Log calls
+	Latency jog.LatencyStats
+}
+
+// BalanceConfig holds the settings for a Balancer
+type BalanceConfig struct {
+	Strategy Strategy
+	// Key returns the value messages are hashed by, for the Hash strategy
+	Key func(*jog.Message) string
+	// Cooldown is how long a failed endpoint is skipped for (defaults to 30s)
+	Cooldown time.Duration
+}
+
+type endpoint struct {
+	Endpoint
+	mu       sync.Mutex
+	failures uint64
+	retryAt  time.Time
+	lastErr  string
+	latency  jog.Latency
+}
+
+func (e *endpoint) healthy(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.retryAt)
+}
+
+func (e *endpoint) result(err error, cooldown time.Duration) {
+	e.mu.Lock()
+	down := !e.retryAt.IsZero()
+	if err == nil {
+		e.retryAt = time.Time{}
+		e.mu.Unlock()
+		if down {
+			jog.Diagnose(jog.INFO, "endpoint_up", map[string]interface{}{"endpoint": e.Name})
+		}
+		return
+	}
+	e.failures++
+	e.lastErr = err.Error()
+	e.retryAt = time.Now().Add(cooldown)
+	e.mu.Unlock()
+	if !down {
+		jog.Diagnose(jog.WARNING, "endpoint_down", map[string]interface{}{"endpoint": e.Name, "error": err.Error()})
+	}
+}
+
+// Balancer is a jog.Logger that distributes messages across several endpoints.
+// A message that fails on one endpoint is tried on the next, and the failed endpoint
+// is skipped until its cooldown passes, so one endpoint's outage doesn't stall logging.
+type Balancer struct {
+	cfg       BalanceConfig
+	mu        sync.RWMutex
+	endpoints []*endpoint
+	next      uint32
+}
+
+// Log sends the message to the chosen endpoint, falling back to the others on failure
+func (b *Balancer) Log(m interface{}) (int, error) {
+	b.mu.RLock()
+	endpoints := b.endpoints
+	b.mu.RUnlock()
+	n := len(endpoints)
+	if n == 0 {
+		return 0, errors.New("jog: no endpoints to balance across")
+	}
+	start := b.start(m, n)
+
+	// Healthy endpoints are tried first, then those cooling down as a last resort
+	now := time.Now()
+	order := make([]*endpoint, 0, n)
+	var cooling []*endpoint
+	for i := 0; i < n; i++ {
+		e := endpoints[(start+i)%n]
+		if e.healthy(now) {
+			order = append(order, e)
+		} else {
+			cooling = append(cooling, e)
+		}
+	}
+
+	var err error
+	for _, e := range append(order, cooling...) {
+		var c int
+		start := time.Now()
+		c, err = e.Logger.Log(m)
+		e.latency.Since(start)
+		e.result(err, b.cfg.Cooldown)
+		if err == nil {
+			return c, nil
+		}
+	}
+	return 0, err
+}
+
+// Index of the first endpoint to try
+func (b *Balancer) start(m interface{}, n int) int {
+	switch b.cfg.Strategy {
+	case Failover:
+		return 0
+	case Hash:
+		if msg, ok := m.(*jog.Message); ok && b.cfg.Key != nil {
+			h := fnv.New32a()
+			h.Write([]byte(b.cfg.Key(msg)))
+			return int(h.Sum32() % uint32(n))
+		}
+	}
+	return int((atomic.AddUint32(&b.next, 1) - 1) % uint32(n))
+}
+
+// Health returns the state of each endpoint
+func (b *Balancer) Health() []EndpointHealth {
+	b.mu.RLock()
+	endpoints := b.endpoints
+	b.mu.RUnlock()
+	now := time.Now()
+	h := make([]EndpointHealth, len(endpoints))
+	for i, e := range endpoints {
+		healthy := e.healthy(now)
+		e.mu.Lock()
+		h[i] = EndpointHealth{e.Name, healthy, e.failures, e.lastErr, jog.LatencyStats{}}
+		e.mu.Unlock()
+		h[i].Latency = e.latency.Stats()
+	}
+	return h
+}
+
+// SetEndpoints replaces the endpoints, keeping the health of those with an existing name
+func (b *Balancer) SetEndpoints(endpoints ...Endpoint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	old := make(map[string]*endpoint, len(b.endpoints))
+	for _, e := range b.endpoints {
+		old[e.Name] = e
+	}
+	b.endpoints = make([]*endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		if o, ok := old[e.Name]; ok {
+			b.endpoints = append(b.endpoints, o)
+		} else {
+			b.endpoints = append(b.endpoints, &endpoint{Endpoint: e})
+		}
+	}
+}
+
+// NewBalancer returns a new Balancer across the given endpoints
+func NewBalancer(c BalanceConfig, endpoints ...Endpoint) *Balancer {
+	if c.Cooldown <= 0 {
+		c.Cooldown = 30 * time.Second
+	}
+	b := &Balancer{cfg: c}
+	b.SetEndpoints(endpoints...)
+	return b
+}
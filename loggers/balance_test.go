@@ -0,0 +1,53 @@
+package loggers
+
+import (
+	"errors"
+	"testing"
+)
+
+type failLogger struct {
+	calls int
+}
+
+func (l *failLogger) Log(m interface{}) (int, error) {
+	l.calls++
+	return 0, errors.New("down")
+}
+
+func TestBalancerFailover(t *testing.T) {
+	down, up := &failLogger{}, &testLogger{}
+	b := NewBalancer(BalanceConfig{Strategy: Failover}, Endpoint{"primary", down}, Endpoint{"secondary", up})
+
+	for i := 0; i < 5; i++ {
+		if _, err := b.Log(msg(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if down.calls != 1 || up.count() != 5 {
+		t.Error("Expected primary to be skipped after failing, got", down.calls, up.count())
+	}
+	if h := b.Health(); h[0].Healthy || h[0].Failures != 1 || !h[1].Healthy {
+		t.Errorf("Unexpected health %+v", h)
+	}
+}
+
+func TestBalancerRoundRobin(t *testing.T) {
+	a, c := &testLogger{}, &testLogger{}
+	b := NewBalancer(BalanceConfig{}, Endpoint{"a", a}, Endpoint{"c", c})
+	for i := 0; i < 6; i++ {
+		b.Log(msg(i))
+	}
+	if a.count() != 3 || c.count() != 3 {
+		t.Error("Expected messages split evenly, got", a.count(), c.count())
+	}
+}
+
+func TestBalancerLatency(t *testing.T) {
+	b := NewBalancer(BalanceConfig{}, Endpoint{"primary", &testLogger{}})
+	for i := 0; i < 10; i++ {
+		b.Log(msg(i))
+	}
+	if h := b.Health(); h[0].Latency.Count != 10 {
+		t.Errorf("Expected 10 timed calls, got %+v", h[0].Latency)
+	}
+}
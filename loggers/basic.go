@@ -7,10 +7,12 @@ package loggers
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
@@ -21,36 +23,116 @@ import (
 	"code.minty.io/jog"
 )
 
+// Lookups of `config.json` values, replaced by tests
+var (
+	groupInt            = config.GroupInt
+	groupBool           = config.GroupBool
+	groupString         = config.GroupString
+	requiredGroupString = config.RequiredGroupString
+)
+
 type basic struct {
 	client    *http.Client
 	url, name string
+	codec     Codec
+}
+
+// TransportConfig holds the connection settings of the HTTP loggers
+type TransportConfig struct {
+	TLS         *tls.Config
+	DialTimeout time.Duration
+	// MaxIdleConnsPerHost kept open for reuse (defaults to 8)
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout after which idle connections are closed (defaults to 90s)
+	IdleConnTimeout time.Duration
+	// ForceAttemptHTTP2 negotiates HTTP/2 with servers that support it
+	ForceAttemptHTTP2 bool
 }
 
-func timeoutFn(seconds int) func(string, string) (net.Conn, error) {
-	d := time.Duration(seconds)
-	timeout := time.Duration(d * time.Second)
-	return func(network, addr string) (net.Conn, error) {
-		return net.DialTimeout(network, addr, timeout)
+// NewTransport returns an http.Transport that pools connections, so high volume
+// senders reuse connections rather than opening one per message
+func NewTransport(c TransportConfig) *http.Transport {
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = 3 * time.Second
 	}
+	if c.MaxIdleConnsPerHost <= 0 {
+		c.MaxIdleConnsPerHost = 8
+	}
+	if c.IdleConnTimeout <= 0 {
+		c.IdleConnTimeout = 90 * time.Second
+	}
+	d := &net.Dialer{Timeout: c.DialTimeout, KeepAlive: 30 * time.Second}
+	return &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         d.DialContext,
+		TLSClientConfig:     c.TLS,
+		TLSHandshakeTimeout: c.DialTimeout,
+		MaxIdleConns:        c.MaxIdleConnsPerHost * 4,
+		MaxIdleConnsPerHost: c.MaxIdleConnsPerHost,
+		IdleConnTimeout:     c.IdleConnTimeout,
+		ForceAttemptHTTP2:   c.ForceAttemptHTTP2,
+	}
+}
+
+// NewClient returns an http.Client using a pooled transport, see NewTransport.
+// The timeout covers the whole request, from dialing and the TLS handshake through
+// to reading the response, so a hung collector can't block the caller indefinitely.
+func NewClient(c TransportConfig, timeout time.Duration) *http.Client {
+	return &http.Client{Transport: NewTransport(c), Timeout: timeout}
 }
 
 func cfg() (client *http.Client, name, url string) {
-	tr := &http.Transport{}
-	if b, ok := config.GroupBool("jog", "verifySSL"); ok {
-		tr = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: b}}
+	t, err := tlsCfg().Load()
+	if err != nil {
+		log.Fatal(err)
 	}
-	timeout, ok := config.GroupInt("job", "timeout")
+	timeout, ok := groupInt("jog", "timeout")
 	if !ok {
 		timeout = 3
 	}
-	tr.Dial = timeoutFn(timeout)
-	client = &http.Client{Transport: tr}
-	name = config.RequiredGroupString("jog", "name")
-	url = config.RequiredGroupString("jog", "url")
+	tc := TransportConfig{TLS: t, DialTimeout: time.Duration(timeout) * time.Second}
+	tc.MaxIdleConnsPerHost, _ = groupInt("jog", "maxIdleConnsPerHost")
+	if n, ok := groupInt("jog", "idleConnTimeout"); ok {
+		tc.IdleConnTimeout = time.Duration(n) * time.Second
+	}
+	tc.ForceAttemptHTTP2, _ = groupBool("jog", "http2")
+	requestTimeout, ok := groupInt("jog", "requestTimeout")
+	if !ok {
+		requestTimeout = 10
+	}
+	client = NewClient(tc, time.Duration(requestTimeout)*time.Second)
+	name = requiredGroupString("jog", "name")
+	url = requiredGroupString("jog", "url")
 	return
 }
 
-// Log sends the data to an HTTP endpoint
+// Compression codec from `config.json`, eg. `"compress": "gzip"`
+func codecCfg() Codec {
+	name, ok := groupString("jog", "compress")
+	if !ok || name == "" {
+		return nil
+	}
+	c, err := LookupCodec(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return c
+}
+
+// TLS settings from `config.json`
+func tlsCfg() (c TLSConfig) {
+	if b, ok := groupBool("jog", "verifySSL"); ok {
+		c.InsecureSkipVerify = !b
+	}
+	c.CertFile, _ = groupString("jog", "certFile")
+	c.KeyFile, _ = groupString("jog", "keyFile")
+	c.CAFile, _ = groupString("jog", "caFile")
+	c.ServerName, _ = groupString("jog", "serverName")
+	return
+}
+
+// Log sends the data to an HTTP endpoint, within the deadline of the context of a
+// message logged with jog.LogContext
 func (l *basic) Log(m interface{}) (int, error) {
 	// Marshal to JSON
 	b, err := json.Marshal(m)
@@ -59,11 +141,37 @@ func (l *basic) Log(m interface{}) (int, error) {
 	}
 
 	// Send it on it's way
-	buf := bytes.NewBuffer(b)
-	resp, err := l.client.Post(l.url, "application/json", buf)
+	body := b
+	if l.codec != nil {
+		if body, err = compress(l.codec, b); err != nil {
+			return 0, err
+		}
+	}
+	ctx := context.Background()
+	if msg, ok := m.(*jog.Message); ok {
+		// Only the deadline is honored, queued messages outlive the context's cancellation
+		if d, ok := msg.Context().Deadline(); ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, d)
+			defer cancel()
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", l.url, bytes.NewReader(body))
 	if err != nil {
 		return 0, err
-	} else if resp.StatusCode < 200 || resp.StatusCode > 299 {
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if l.codec != nil {
+		req.Header.Set("Content-Encoding", l.codec.Name())
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	// Drain the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		return 0, errors.New(fmt.Sprintf("received a `%d` from endpoint `%s` with data -> %s", resp.StatusCode, l.url, b))
 	}
 	return len(b), nil
@@ -73,18 +181,25 @@ func (l *basic) Log(m interface{}) (int, error) {
 func SetBasic() {
 	log.SetPrefix("")
 	log.SetFlags(0)
-	log.SetOutput(jog.NewWriter(New(cfg())))
+	log.SetOutput(jog.NewWriter(NewFromConfig()))
 }
 
 // NewFromConfig returns a new basic jog.Logger using `jog` values from `config.json`
 func NewFromConfig() jog.Logger {
-	return New(cfg())
+	client, name, url := cfg()
+	return NewCompressed(client, name, url, codecCfg())
 }
 
 // New returns a new basic jog.Logger
 func New(client *http.Client, name, url string) jog.Logger {
+	return NewCompressed(client, name, url, nil)
+}
+
+// NewCompressed returns a new basic jog.Logger compressing each request's body with
+// the Codec, when not nil, sent as its Content-Encoding
+func NewCompressed(client *http.Client, name, url string, c Codec) jog.Logger {
 	if strings.HasSuffix(url, "/") {
-		return &basic{client, url + name, name}
+		return &basic{client, url + name, name, c}
 	}
-	return &basic{client, fmt.Sprintf("%s/%s", url, name), name}
+	return &basic{client, fmt.Sprintf("%s/%s", url, name), name, c}
 }
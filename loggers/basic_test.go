@@ -0,0 +1,117 @@
+package loggers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Replaces the `jog` values of `config.json` for the test
+func setConfig(t *testing.T, values map[string]interface{}) {
+	gi, gb, gs, rgs := groupInt, groupBool, groupString, requiredGroupString
+	t.Cleanup(func() {
+		groupInt, groupBool, groupString, requiredGroupString = gi, gb, gs, rgs
+	})
+	groupInt = func(g, k string) (int, bool) {
+		v, ok := values[k].(int)
+		return v, ok && g == "jog"
+	}
+	groupBool = func(g, k string) (bool, bool) {
+		v, ok := values[k].(bool)
+		return v, ok && g == "jog"
+	}
+	groupString = func(g, k string) (string, bool) {
+		v, ok := values[k].(string)
+		return v, ok && g == "jog"
+	}
+	requiredGroupString = func(g, k string) string {
+		v, _ := groupString(g, k)
+		return v
+	}
+}
+
+// Serves requests until the client gives up on them
+func hangingServer(t *testing.T) *httptest.Server {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+	return srv
+}
+
+func TestBasicTimeoutConfig(t *testing.T) {
+	setConfig(t, map[string]interface{}{"name": "app", "url": "http://localhost", "timeout": 5, "requestTimeout": 2})
+	client, name, url := cfg()
+	tr := client.Transport.(*http.Transport)
+	if client.Timeout != 2*time.Second || tr.TLSHandshakeTimeout != 5*time.Second || name != "app" || url != "http://localhost" {
+		t.Error("Unexpected client", client.Timeout, tr.TLSHandshakeTimeout, name, url)
+	}
+
+	setConfig(t, map[string]interface{}{"timeout": -1})
+	if err := ValidateConfig(); err == nil {
+		t.Error("Expected a negative timeout to be invalid")
+	}
+}
+
+func TestBasicRequestTimeout(t *testing.T) {
+	srv := hangingServer(t)
+	l := New(NewClient(TransportConfig{}, 50*time.Millisecond), "app", srv.URL)
+
+	start := time.Now()
+	if _, err := l.Log(msg("hello")); err == nil {
+		t.Error("Expected the request to time out")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Error("Expected the request timeout to apply, took", d)
+	}
+}
+
+func TestBasicContextDeadline(t *testing.T) {
+	srv := hangingServer(t)
+	j := jog.New(New(NewClient(TransportConfig{}, time.Minute), "app", srv.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := j.LogContext(ctx, jog.INFO, "hello"); err == nil {
+		t.Error("Expected the request to exceed the context's deadline")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Error("Expected the context's deadline to apply, took", d)
+	}
+}
+
+func TestBasicTransportConfig(t *testing.T) {
+	setConfig(t, map[string]interface{}{
+		"maxIdleConnsPerHost": 16, "idleConnTimeout": 30, "http2": true, "requestTimeout": 4, "verifySSL": false,
+	})
+	client, _, _ := cfg()
+	tr := client.Transport.(*http.Transport)
+	if tr.MaxIdleConnsPerHost != 16 || tr.MaxIdleConns != 64 || tr.IdleConnTimeout != 30*time.Second || !tr.ForceAttemptHTTP2 {
+		t.Error("Unexpected transport", tr.MaxIdleConnsPerHost, tr.MaxIdleConns, tr.IdleConnTimeout, tr.ForceAttemptHTTP2)
+	}
+	if client.Timeout != 4*time.Second {
+		t.Error("Expected a 4s request timeout, got", client.Timeout)
+	}
+	if tr.TLSClientConfig == nil || !tr.TLSClientConfig.InsecureSkipVerify {
+		t.Error("Expected verifySSL to be applied")
+	}
+
+	// Defaults
+	setConfig(t, map[string]interface{}{})
+	client, _, _ = cfg()
+	tr = client.Transport.(*http.Transport)
+	if tr.MaxIdleConnsPerHost != 8 || tr.IdleConnTimeout != 90*time.Second || tr.ForceAttemptHTTP2 ||
+		tr.TLSHandshakeTimeout != 3*time.Second || client.Timeout != 10*time.Second {
+		t.Error("Unexpected defaults", tr.MaxIdleConnsPerHost, tr.IdleConnTimeout, tr.ForceAttemptHTTP2, tr.TLSHandshakeTimeout, client.Timeout)
+	}
+}
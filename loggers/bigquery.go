@@ -0,0 +1,178 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// BigQueryConfig holds the settings for a BigQuery logger
+type BigQueryConfig struct {
+	Project string
+	Dataset string
+	// Table rows are inserted into, see BigQuerySchema
+	Table string
+	// Endpoint of the BigQuery REST API (defaults to `https://bigquery.googleapis.com`)
+	Endpoint string
+	// Client must authorize its requests, eg. `google.DefaultClient(ctx, bigquery.Scope)`
+	Client *http.Client
+	// BatchSize and Interval control how often rows are inserted (default 500, 1s)
+	BatchSize int
+	Interval  time.Duration
+}
+
+// BigQuery is a jog.Logger that streams messages into a BigQuery table, one row per
+// message with the level, time, file and line as columns and Data as a JSON column
+type BigQuery struct {
+	cfg   BigQueryConfig
+	url   string
+	batch *jog.Batcher
+}
+
+type bigQueryRow struct {
+	InsertID string         `json:"insertId,omitempty"`
+	JSON     bigQueryRecord `json:"json"`
+}
+
+type bigQueryRecord struct {
+	Time  string   `json:"time"`
+	Level string   `json:"level"`
+	File  string   `json:"file"`
+	Line  int      `json:"line"`
+	Data  string   `json:"data"`
+	Meta  string   `json:"meta,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// BigQuerySchema returns the fields of the table messages are inserted into, as
+// used in the `schema` of a BigQuery table resource. The table should be
+// partitioned on `time`.
+func BigQuerySchema() []map[string]string {
+	return []map[string]string{
+		{"name": "time", "type": "TIMESTAMP", "mode": "REQUIRED"},
+		{"name": "level", "type": "STRING", "mode": "REQUIRED"},
+		{"name": "file", "type": "STRING"},
+		{"name": "line", "type": "INT64"},
+		{"name": "data", "type": "JSON"},
+		{"name": "meta", "type": "JSON"},
+		{"name": "tags", "type": "STRING", "mode": "REPEATED"},
+	}
+}
+
+// Log queues the message, inserting the batch once it's full
+func (b *BigQuery) Log(m interface{}) (int, error) {
+	return b.batch.Log(m)
+}
+
+// LogBatch inserts the messages now, as a whole batch, see jog.BatchLogger
+func (b *BigQuery) LogBatch(msgs []*jog.Message) (int, error) {
+	return 0, b.write(msgs)
+}
+
+// Flush inserts all pending messages
+func (b *BigQuery) Flush() error {
+	return b.batch.Flush()
+}
+
+// Close inserts any pending messages and stops the background flushing
+func (b *BigQuery) Close() error {
+	return b.batch.Close()
+}
+
+// Streams the messages with `tabledata.insertAll`, their IDs (see jog.WithIDs) being
+// used as insert IDs so retried batches aren't duplicated
+func (b *BigQuery) write(msgs []*jog.Message) error {
+	body := struct {
+		Rows []bigQueryRow `json:"rows"`
+	}{make([]bigQueryRow, 0, len(msgs))}
+
+	for _, m := range msgs {
+		d, err := m.DataJSON()
+		if err != nil {
+			d, _ = json.Marshal(fmt.Sprint(m.Data))
+		}
+		r := bigQueryRecord{
+			Time:  m.Time.UTC().Format(time.RFC3339Nano),
+			Level: string(m.Level),
+			File:  m.File,
+			Line:  m.Line,
+			Data:  string(d),
+			Tags:  m.Tags,
+		}
+		if len(m.Meta) > 0 {
+			meta, err := json.Marshal(m.Meta)
+			if err != nil {
+				meta, _ = json.Marshal(fmt.Sprint(m.Meta))
+			}
+			r.Meta = string(meta)
+		}
+		body.Rows = append(body.Rows, bigQueryRow{InsertID: m.ID, JSON: r})
+	}
+
+	p, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := b.cfg.Client.Post(b.url, "application/json", bytes.NewReader(p))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		e, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received a `%d` inserting into `%s`: %s", resp.StatusCode, b.cfg.Table, e)
+	}
+
+	// Rows may be rejected individually, in a successful response
+	var result struct {
+		InsertErrors []struct {
+			Index  int `json:"index"`
+			Errors []struct {
+				Reason  string `json:"reason"`
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"insertErrors"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil && err != io.EOF {
+		return err
+	}
+	if n := len(result.InsertErrors); n > 0 {
+		e := result.InsertErrors[0]
+		reason := "unknown"
+		if len(e.Errors) > 0 {
+			reason = e.Errors[0].Reason + ": " + e.Errors[0].Message
+		}
+		return fmt.Errorf("%d rows rejected by `%s`, row %d: %s", n, b.cfg.Table, e.Index, reason)
+	}
+	return nil
+}
+
+// NewBigQuery returns a new BigQuery logger
+func NewBigQuery(c BigQueryConfig) *BigQuery {
+	if c.Endpoint == "" {
+		c.Endpoint = "https://bigquery.googleapis.com"
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 500
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	b := &BigQuery{
+		cfg: c,
+		url: fmt.Sprintf("%s/bigquery/v2/projects/%s/datasets/%s/tables/%s/insertAll",
+			strings.TrimSuffix(c.Endpoint, "/"), c.Project, c.Dataset, c.Table),
+	}
+	b.batch = jog.NewBatcher(c.BatchSize, c.Interval, b.write)
+	return b
+}
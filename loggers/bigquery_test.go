@@ -0,0 +1,54 @@
+package loggers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+func TestBigQuery(t *testing.T) {
+	var rows []bigQueryRow
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		var body struct{ Rows []bigQueryRow }
+		json.NewDecoder(r.Body).Decode(&body)
+		rows = body.Rows
+		fmt.Fprint(w, `{"kind": "bigquery#tableDataInsertAllResponse"}`)
+	}))
+	defer srv.Close()
+
+	b := NewBigQuery(BigQueryConfig{Project: "p", Dataset: "d", Table: "logs", Endpoint: srv.URL, Interval: time.Hour})
+	m := msg(map[string]interface{}{"user": "jack"})
+	m.ID = "abc"
+	m.Level = jog.ERROR
+	m.SetMeta("host", "web1")
+	b.Log(m)
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/bigquery/v2/projects/p/datasets/d/tables/logs/insertAll" {
+		t.Error("Expected the insertAll path, got", path)
+	}
+	if len(rows) != 1 || rows[0].InsertID != "abc" || rows[0].JSON.Level != "error" || rows[0].JSON.Data != `{"user":"jack"}` || rows[0].JSON.Meta != `{"host":"web1"}` {
+		t.Error("Expected the message as a row, got", rows)
+	}
+}
+
+func TestBigQueryInsertErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"insertErrors": [{"index": 0, "errors": [{"reason": "invalid", "message": "no such field"}]}]}`)
+	}))
+	defer srv.Close()
+
+	b := NewBigQuery(BigQueryConfig{Table: "logs", Endpoint: srv.URL, Interval: time.Hour})
+	if _, err := b.LogBatch([]*jog.Message{msg("one")}); err == nil {
+		t.Error("Expected rejected rows to fail")
+	}
+	b.Close()
+}
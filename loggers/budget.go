@@ -0,0 +1,245 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Number of slots a Budget's window is divided into
+const budgetSlots = 10
+
+// Quota is the most messages, and bytes of their JSON, a component may log within
+// a Budget's window, zero being unlimited
+type Quota struct {
+	Messages int   `json:"messages,omitempty"`
+	Bytes    int64 `json:"bytes,omitempty"`
+}
+
+// BudgetConfig holds the settings for a Budget
+type BudgetConfig struct {
+	// Quotas by logger name, the `logger` Meta field set by jog.Named. A quota is
+	// shared by the logger's children, eg. the quota of `db` covers `db.pool`,
+	// unless they have their own.
+	Quotas map[string]Quota
+	// Default is the quota of each logger without one, including unnamed messages
+	Default Quota
+	// Window is the rolling window the quotas are enforced over (defaults to 1m)
+	Window time.Duration
+	// Keep is the level at, and above, which messages are always logged, while
+	// still counting against the quota (defaults to none)
+	Keep jog.Level
+	// Clock is the source of time (defaults to time.Now)
+	Clock func() time.Time
+}
+
+// BudgetSummary is the Data of the WARNING message a Budget logs for the
+// messages of a logger it dropped
+type BudgetSummary struct {
+	Logger       string    `json:"logger"`
+	Dropped      int       `json:"dropped"`
+	DroppedBytes int64     `json:"dropped_bytes"`
+	First        time.Time `json:"first"`
+	Last         time.Time `json:"last"`
+	Quota        Quota     `json:"quota"`
+}
+
+// Usage of a quota over the slots of the window
+type budgetUsage struct {
+	quota Quota
+	slot  int64
+	msgs  [budgetSlots]int
+	bytes [budgetSlots]int64
+	over  BudgetSummary
+}
+
+// Moves the window to the slot, clearing those it passed
+func (u *budgetUsage) advance(slot int64) {
+	if slot-u.slot >= budgetSlots {
+		u.msgs, u.bytes = [budgetSlots]int{}, [budgetSlots]int64{}
+	} else {
+		for s := u.slot + 1; s <= slot; s++ {
+			u.msgs[s%budgetSlots], u.bytes[s%budgetSlots] = 0, 0
+		}
+	}
+	if slot > u.slot {
+		u.slot = slot
+	}
+}
+
+// Reports whether a message of `size` bytes fits within the quota
+func (u *budgetUsage) fits(size int64) bool {
+	var msgs int
+	var bytes int64
+	for i := range u.msgs {
+		msgs += u.msgs[i]
+		bytes += u.bytes[i]
+	}
+	return (u.quota.Messages <= 0 || msgs < u.quota.Messages) &&
+		(u.quota.Bytes <= 0 || bytes+size <= u.quota.Bytes)
+}
+
+// Budget is a jog.Logger enforcing quotas on the messages of each named logger,
+// so a misbehaving component can't use up the ingestion budget of the whole
+// service. Messages over a quota are dropped, and summarized by a BudgetSummary
+// logged before the component's next message that's logged, or on Flush.
+type Budget struct {
+	logger jog.Logger
+	cfg    BudgetConfig
+	slot   time.Duration
+	mu     sync.Mutex
+	usage  map[string]*budgetUsage
+}
+
+// Log logs the message when it's within its logger's quota
+func (b *Budget) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return b.logger.Log(m)
+	}
+	name, _ := msg.Meta["logger"].(string)
+	key, q := b.quota(name)
+	if q.Messages <= 0 && q.Bytes <= 0 {
+		return b.logger.Log(m)
+	}
+	var size int64
+	if q.Bytes > 0 {
+		p, err := json.Marshal(msg)
+		if err != nil {
+			return b.logger.Log(m)
+		}
+		size = int64(len(p))
+	}
+
+	now := b.cfg.Clock()
+	b.mu.Lock()
+	u, ok := b.usage[key]
+	if !ok {
+		u = &budgetUsage{quota: q}
+		b.usage[key] = u
+	}
+	u.advance(now.UnixNano() / int64(b.slot))
+	keep := b.cfg.Keep != "" && msg.Level.AtLeast(b.cfg.Keep)
+	if !u.fits(size) && !keep {
+		if u.over.Dropped == 0 {
+			u.over.First = now
+		}
+		u.over.Dropped++
+		u.over.DroppedBytes += size
+		u.over.Last = now
+		b.mu.Unlock()
+		return 0, nil
+	}
+	i := u.slot % budgetSlots
+	u.msgs[i]++
+	u.bytes[i] += size
+	summary := b.summary(key, u)
+	b.mu.Unlock()
+
+	if summary != nil {
+		b.logger.Log(summary)
+	}
+	return b.logger.Log(m)
+}
+
+// Usage returns the messages, and bytes, each quota's logger has logged within
+// the current window, by the name of the quota
+func (b *Budget) Usage() map[string]Quota {
+	slot := b.cfg.Clock().UnixNano() / int64(b.slot)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	usage := make(map[string]Quota, len(b.usage))
+	for k, u := range b.usage {
+		u.advance(slot)
+		var q Quota
+		for i := range u.msgs {
+			q.Messages += u.msgs[i]
+			q.Bytes += u.bytes[i]
+		}
+		usage[k] = q
+	}
+	return usage
+}
+
+// Flush logs the summaries of the messages dropped since the last ones
+func (b *Budget) Flush() error {
+	b.mu.Lock()
+	var summaries []*jog.Message
+	for k, u := range b.usage {
+		if s := b.summary(k, u); s != nil {
+			summaries = append(summaries, s)
+		}
+	}
+	b.mu.Unlock()
+
+	var err error
+	for _, s := range summaries {
+		if _, e := b.logger.Log(s); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Close logs any pending summaries
+func (b *Budget) Close() error {
+	return b.Flush()
+}
+
+// Returns the summary of the logger's dropped messages, if any, resetting them
+func (b *Budget) summary(key string, u *budgetUsage) *jog.Message {
+	if u.over.Dropped == 0 {
+		return nil
+	}
+	s := u.over
+	s.Logger, s.Quota = key, u.quota
+	u.over = BudgetSummary{}
+	m := &jog.Message{
+		Data:    s,
+		Level:   jog.WARNING,
+		Time:    s.Last.UTC(),
+		Version: jog.SchemaVersion,
+	}
+	if key != "" {
+		m.Meta = map[string]interface{}{"logger": key}
+	}
+	return m
+}
+
+// Returns the quota of the logger, along with the name usage is tracked by;
+// the name of its closest configured parent, or its own for the default
+func (b *Budget) quota(name string) (string, Quota) {
+	for n := name; ; {
+		if q, ok := b.cfg.Quotas[n]; ok {
+			return n, q
+		}
+		i := strings.LastIndexByte(n, '.')
+		if i < 0 {
+			break
+		}
+		n = n[:i]
+	}
+	return name, b.cfg.Default
+}
+
+// NewBudget returns a new Budget logging to `l`
+func NewBudget(l jog.Logger, c BudgetConfig) *Budget {
+	if c.Window <= 0 {
+		c.Window = time.Minute
+	}
+	if c.Clock == nil {
+		c.Clock = time.Now
+	}
+	slot := c.Window / budgetSlots
+	if slot <= 0 {
+		slot = 1
+	}
+	return &Budget{logger: l, cfg: c, slot: slot, usage: make(map[string]*budgetUsage)}
+}
@@ -0,0 +1,74 @@
+package loggers
+
+import (
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+func named(name string, d interface{}) *jog.Message {
+	m := msg(d)
+	m.SetMeta("logger", name)
+	return m
+}
+
+func TestBudget(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := &testLogger{}
+	b := NewBudget(l, BudgetConfig{
+		Quotas: map[string]Quota{"db": {Messages: 3}},
+		Window: time.Minute,
+		Keep:   jog.ERROR,
+		Clock:  func() time.Time { return now },
+	})
+
+	for i := 0; i < 10; i++ {
+		b.Log(named("db.pool", i))
+		b.Log(named("http", i))
+	}
+	e := named("db", "kaboom")
+	e.Level = jog.ERROR
+	b.Log(e)
+	// The error is kept, preceded by a summary of the messages dropped
+	if n := l.count(); n != 3+10+2 {
+		t.Fatal("Expected db to be limited to its quota, got", n)
+	}
+	s, ok := l.messages[13].(*jog.Message)
+	if !ok || s.Level != jog.WARNING || s.Meta["logger"] != "db" {
+		t.Fatalf("Unexpected summary %+v", l.messages[13])
+	}
+	if d := s.Data.(BudgetSummary); d.Dropped != 7 || d.Logger != "db" || d.Quota.Messages != 3 {
+		t.Errorf("Unexpected summary %+v", d)
+	}
+	if u := b.Usage()["db"]; u.Messages != 4 {
+		t.Error("Expected the kept error to count against the quota, got", u)
+	}
+
+	b.Log(named("db", "dropped"))
+	if n := l.count(); n != 15 {
+		t.Fatal("Expected the quota to still be used up, got", n)
+	}
+	now = now.Add(time.Minute)
+	b.Log(named("db", "again"))
+	if n := l.count(); n != 17 || l.messages[15].(*jog.Message).Data.(BudgetSummary).Dropped != 1 {
+		t.Error("Expected the rolled window to log the summary and message, got", n)
+	}
+}
+
+func TestBudgetBytes(t *testing.T) {
+	l := &testLogger{}
+	b := NewBudget(l, BudgetConfig{Default: Quota{Bytes: 200}})
+	for i := 0; i < 10; i++ {
+		b.Log(named("noisy", "0123456789012345678901234567890123456789"))
+	}
+	kept := l.count()
+	if kept == 0 || kept == 10 {
+		t.Fatal("Expected the byte quota to drop some messages, got", kept)
+	}
+	b.Flush()
+	s := l.messages[kept].(*jog.Message).Data.(BudgetSummary)
+	if s.Dropped != 10-kept || s.DroppedBytes == 0 || s.Logger != "noisy" {
+		t.Errorf("Unexpected summary %+v", s)
+	}
+}
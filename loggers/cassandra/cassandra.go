@@ -0,0 +1,144 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cassandra contains a jog.Logger that writes messages into a time bucketed
+// Cassandra, or Scylla, table. It's driver agnostic, the application wraps its
+// driver's session in a Session, eg. with gocql
+//
+//	type session struct{ *gocql.Session }
+//
+//	func (s session) Exec(stmt string, values ...interface{}) error {
+//		return s.Query(stmt, values...).Exec()
+//	}
+package cassandra
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Session is implemented by the application, wrapping its Cassandra driver
+type Session interface {
+	Exec(stmt string, values ...interface{}) error
+}
+
+// Config holds the settings for a Logger
+type Config struct {
+	// Table messages are inserted into, see Schema
+	Table string
+	// Bucket is the span of time held by each partition (defaults to an hour),
+	// keeping partitions bounded in size
+	Bucket time.Duration
+	// TTL after which rows expire, none when zero
+	TTL time.Duration
+	// BatchSize and Interval control how messages are batched (default 100, 1s)
+	BatchSize int
+	Interval  time.Duration
+}
+
+// Logger is a jog.Logger that inserts messages, in batches, into a table
+type Logger struct {
+	session Session
+	insert  string
+	bucket  time.Duration
+	batch   *jog.Batcher
+}
+
+// Schema returns the statement that creates the table for messages.
+// Rows are partitioned by bucket, the start of their Config.Bucket in epoch
+// milliseconds, and ordered by time within it, so a time range is read from
+// the buckets covering it. Expiry is set per row, see Config.TTL.
+func Schema(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	bucket bigint,
+	time timestamp,
+	id text,
+	level text,
+	file text,
+	line int,
+	data text,
+	meta map<text, text>,
+	tags list<text>,
+	PRIMARY KEY ((bucket), time, id)
+) WITH CLUSTERING ORDER BY (time DESC, id ASC)
+	AND compaction = {'class': 'TimeWindowCompactionStrategy'}`, table)
+}
+
+// Log queues the message, inserting the batch once it's full
+func (l *Logger) Log(m interface{}) (int, error) {
+	return l.batch.Log(m)
+}
+
+// LogBatch inserts the messages now, as a whole batch, see jog.BatchLogger
+func (l *Logger) LogBatch(msgs []*jog.Message) (int, error) {
+	return 0, l.write(msgs)
+}
+
+// Flush inserts all pending messages
+func (l *Logger) Flush() error {
+	return l.batch.Flush()
+}
+
+// Close inserts any pending messages and stops the background flushing
+func (l *Logger) Close() error {
+	return l.batch.Close()
+}
+
+// Inserts each message, rows of a batch usually span partitions so they aren't
+// sent as a (logged) batch
+func (l *Logger) write(msgs []*jog.Message) error {
+	for _, m := range msgs {
+		if err := l.session.Exec(l.insert, l.values(m)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the insert's values of the message
+func (l *Logger) values(m *jog.Message) []interface{} {
+	d, err := m.DataJSON()
+	if err != nil {
+		d, _ = json.Marshal(fmt.Sprint(m.Data))
+	}
+	meta := make(map[string]string, len(m.Meta))
+	for k, v := range m.Meta {
+		if s, ok := v.(string); ok {
+			meta[k] = s
+		} else if b, err := json.Marshal(v); err == nil {
+			meta[k] = string(b)
+		}
+	}
+	id := m.ID
+	if id == "" {
+		id = jog.NewULID()
+	}
+	bucket := m.Time.Truncate(l.bucket).UnixNano() / int64(time.Millisecond)
+	return []interface{}{bucket, m.Time, id, string(m.Level), m.File, m.Line, string(d), meta, m.Tags}
+}
+
+// New returns a new Logger inserting into the table of `cfg`, which should
+// have been created with Schema
+func New(s Session, cfg Config) (*Logger, error) {
+	if !tableName.MatchString(cfg.Table) {
+		return nil, errors.New("jog: invalid Cassandra table name " + cfg.Table)
+	}
+	if cfg.Bucket <= 0 {
+		cfg.Bucket = time.Hour
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (bucket, time, id, level, file, line, data, meta, tags) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)", cfg.Table)
+	if cfg.TTL > 0 {
+		insert += fmt.Sprintf(" USING TTL %d", int64(cfg.TTL/time.Second))
+	}
+	l := &Logger{session: s, insert: insert, bucket: cfg.Bucket}
+	l.batch = jog.NewBatcher(cfg.BatchSize, cfg.Interval, l.write)
+	return l, nil
+}
@@ -0,0 +1,79 @@
+package cassandra
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+type exec struct {
+	stmt   string
+	values []interface{}
+}
+
+// Records the statements executed
+type testSession struct {
+	execs []exec
+	err   error
+}
+
+func (s *testSession) Exec(stmt string, values ...interface{}) error {
+	s.execs = append(s.execs, exec{stmt, values})
+	return s.err
+}
+
+func TestLogger(t *testing.T) {
+	s := &testSession{}
+	l, err := New(s, Config{Table: "app.logs", Bucket: time.Hour, TTL: 24 * time.Hour, Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	m := &jog.Message{ID: "abc", Data: map[string]interface{}{"user": "jack"}, Level: jog.ERROR, File: "main.go", Line: 12, Time: now}
+	m.SetMeta("host", "web1")
+	m.SetMeta("attempt", 2)
+	m.Tags = []string{"api"}
+	if _, err := l.LogBatch([]*jog.Message{m, {Data: "plain", Level: jog.INFO, Time: now}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.execs) != 2 {
+		t.Fatal("Expected an insert per message, got", len(s.execs))
+	}
+
+	e := s.execs[0]
+	insert := "INSERT INTO app.logs (bucket, time, id, level, file, line, data, meta, tags) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) USING TTL 86400"
+	if e.stmt != insert {
+		t.Error("Unexpected statement", e.stmt)
+	}
+	bucket := time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+	expected := fmt.Sprint([]interface{}{bucket, now, "abc", "error", "main.go", 12, `{"user":"jack"}`, map[string]string{"host": "web1", "attempt": "2"}, []string{"api"}})
+	if v := fmt.Sprint(e.values); v != expected {
+		t.Error("Expected", expected, "got", v)
+	}
+
+	// Messages without an ID get a ULID
+	id, _ := s.execs[1].values[2].(string)
+	if len(id) != 26 {
+		t.Error("Expected a ULID for the empty ID, got", id)
+	}
+	if l.LogBatch([]*jog.Message{{Data: "plain", Time: now}}); s.execs[2].values[2] == id {
+		t.Error("Expected a new ULID per message")
+	}
+}
+
+func TestLoggerErrors(t *testing.T) {
+	s := &testSession{err: errors.New("unavailable")}
+	l, _ := New(s, Config{Table: "logs", Interval: time.Hour})
+	defer l.Close()
+	if _, err := l.LogBatch([]*jog.Message{{Data: "one"}, {Data: "two"}}); err != s.err || len(s.execs) != 1 {
+		t.Error("Expected the batch to stop at the failed insert, got", err, len(s.execs))
+	}
+	if _, err := New(s, Config{Table: "logs; DROP TABLE logs"}); err == nil {
+		t.Error("Expected an invalid table name to fail")
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// ClickHouseConfig holds the settings for a ClickHouse logger
+type ClickHouseConfig struct {
+	// URL of the ClickHouse HTTP interface, eg. `http://localhost:8123`
+	URL string
+	// Table messages are inserted into, see ClickHouseSchema
+	Table          string
+	User, Password string
+	// BatchSize and Interval control how often rows are inserted (default 10000, 5s),
+	// ClickHouse prefers large, infrequent, inserts
+	BatchSize int
+	Interval  time.Duration
+	Client    *http.Client
+}
+
+// ClickHouse is a jog.Logger that bulk inserts messages using ClickHouse's HTTP interface
+type ClickHouse struct {
+	client *http.Client
+	url    string
+	user   string
+	pass   string
+	batch  *jog.Batcher
+}
+
+type clickHouseRow struct {
+	Time  string   `json:"time"`
+	Level string   `json:"level"`
+	File  string   `json:"file"`
+	Line  int      `json:"line"`
+	Data  string   `json:"data"`
+	Meta  string   `json:"meta"`
+	Tags  []string `json:"tags"`
+}
+
+// ClickHouseSchema returns the statement that creates a MergeTree table for messages.
+// Tables created before the `meta` and `tags` columns were added need them added,
+// eg. `ALTER TABLE logs ADD COLUMN meta String, ADD COLUMN tags Array(String)`.
+func ClickHouseSchema(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	time DateTime64(3, 'UTC'),
+	level LowCardinality(String),
+	file String,
+	line UInt32,
+	data String,
+	meta String,
+	tags Array(String)
+) ENGINE = MergeTree
+PARTITION BY toYYYYMMDD(time)
+ORDER BY (level, time)`, table)
+}
+
+// Log queues the message, inserting the batch once it's full
+func (c *ClickHouse) Log(m interface{}) (int, error) {
+	return c.batch.Log(m)
+}
+
+// LogBatch inserts the messages now, as a whole batch, see jog.BatchLogger
+func (c *ClickHouse) LogBatch(msgs []*jog.Message) (int, error) {
+	return 0, c.write(msgs)
+}
+
+// Flush inserts all pending messages
+func (c *ClickHouse) Flush() error {
+	return c.batch.Flush()
+}
+
+// Close inserts any pending messages and stops the background flushing
+func (c *ClickHouse) Close() error {
+	return c.batch.Close()
+}
+
+// Inserts the messages as JSONEachRow
+func (c *ClickHouse) write(msgs []*jog.Message) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, m := range msgs {
+		d, err := m.DataJSON()
+		if err != nil {
+			d, _ = json.Marshal(fmt.Sprint(m.Data))
+		}
+		r := clickHouseRow{
+			Time:  m.Time.UTC().Format("2006-01-02 15:04:05.000"),
+			Level: string(m.Level),
+			File:  m.File,
+			Line:  m.Line,
+			Data:  string(d),
+			Tags:  m.Tags,
+		}
+		if len(m.Meta) > 0 {
+			meta, err := json.Marshal(m.Meta)
+			if err != nil {
+				meta, _ = json.Marshal(fmt.Sprint(m.Meta))
+			}
+			r.Meta = string(meta)
+		}
+		if r.Tags == nil {
+			r.Tags = []string{}
+		}
+		enc.Encode(r)
+	}
+
+	req, err := http.NewRequest("POST", c.url, &buf)
+	if err != nil {
+		return err
+	}
+	if c.user != "" {
+		req.Header.Set("X-ClickHouse-User", c.user)
+		req.Header.Set("X-ClickHouse-Key", c.pass)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received a `%d` from ClickHouse: %s", resp.StatusCode, b)
+	}
+	return nil
+}
+
+// NewClickHouse returns a new ClickHouse logger
+func NewClickHouse(c ClickHouseConfig) (*ClickHouse, error) {
+	if !tableName.MatchString(c.Table) {
+		return nil, errors.New("jog: invalid ClickHouse table name " + c.Table)
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("query", fmt.Sprintf("INSERT INTO %s FORMAT JSONEachRow", c.Table))
+	u.RawQuery = q.Encode()
+
+	if c.BatchSize <= 0 {
+		c.BatchSize = 10000
+	}
+	if c.Interval <= 0 {
+		c.Interval = 5 * time.Second
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	ch := &ClickHouse{
+		client: c.Client,
+		url:    u.String(),
+		user:   c.User,
+		pass:   c.Password,
+	}
+	ch.batch = jog.NewBatcher(c.BatchSize, c.Interval, ch.write)
+	return ch, nil
+}
@@ -0,0 +1,83 @@
+package loggers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+func TestClickHouse(t *testing.T) {
+	var query, user, key string
+	var rows []clickHouseRow
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		user, key = r.Header.Get("X-ClickHouse-User"), r.Header.Get("X-ClickHouse-Key")
+		s := bufio.NewScanner(r.Body)
+		for s.Scan() {
+			var row clickHouseRow
+			if err := json.Unmarshal(s.Bytes(), &row); err != nil {
+				t.Error("Expected a JSON row per line, got", s.Text())
+			}
+			rows = append(rows, row)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClickHouse(ClickHouseConfig{URL: srv.URL, Table: "db.logs", User: "jog", Password: "secret", Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	m := msg(map[string]interface{}{"user": "jack"})
+	m.Level = jog.ERROR
+	m.File, m.Line = "main.go", 12
+	m.Time = time.Date(2020, 1, 2, 3, 4, 5, 6e6, time.FixedZone("EST", -5*3600))
+	m.SetMeta("host", "web1")
+	m.Tags = []string{"api"}
+	if _, err := c.LogBatch([]*jog.Message{m, msg("plain")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if query != "INSERT INTO db.logs FORMAT JSONEachRow" || user != "jog" || key != "secret" {
+		t.Error("Unexpected request", query, user, key)
+	}
+	if len(rows) != 2 {
+		t.Fatal("Expected 2 rows, got", len(rows))
+	}
+	r := rows[0]
+	if r.Time != "2020-01-02 08:04:05.006" || r.Level != "error" || r.File != "main.go" || r.Line != 12 ||
+		r.Data != `{"user":"jack"}` || r.Meta != `{"host":"web1"}` || len(r.Tags) != 1 || r.Tags[0] != "api" {
+		t.Errorf("Unexpected row %+v", r)
+	}
+	if r = rows[1]; r.Data != `"plain"` || r.Meta != "" || r.Tags == nil {
+		t.Errorf("Expected empty meta and tags, got %+v", r)
+	}
+}
+
+func TestClickHouseErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Code: 60. DB::Exception: Table db.logs doesn't exist", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c, err := NewClickHouse(ClickHouseConfig{URL: srv.URL, Table: "db.logs", Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	_, err = c.LogBatch([]*jog.Message{msg("hello")})
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "doesn't exist") {
+		t.Error("Expected the response to be the error, got", err)
+	}
+
+	if _, err := NewClickHouse(ClickHouseConfig{URL: srv.URL, Table: "logs; DROP TABLE logs"}); err == nil {
+		t.Error("Expected an invalid table name to fail")
+	}
+}
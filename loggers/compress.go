@@ -0,0 +1,114 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// CompressWriter is a compressing stream, such as a *gzip.Writer or a zstd Encoder
+type CompressWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// Codec is a compression format, selected per backend, eg. `FileConfig.Compress`,
+// `S3Config.Codec`, `Socket.SetCompressor` and the basic logger's `compress` setting.
+// Gzip and Deflate are provided, others can be plugged in with NewCodec.
+type Codec interface {
+	// Name of the format, as used in HTTP's Content-Encoding, eg. `gzip`
+	Name() string
+	// Extension of compressed files and objects, eg. `.gz`
+	Extension() string
+	// NewWriter wraps `w` with a compressing stream
+	NewWriter(w io.Writer) (CompressWriter, error)
+}
+
+// Compressor wraps a writer with a compressing stream, see NewCodec
+type Compressor func(w io.Writer) (CompressWriter, error)
+
+type codec struct {
+	name, ext string
+	fn        Compressor
+}
+
+func (c *codec) Name() string                                  { return c.name }
+func (c *codec) Extension() string                             { return c.ext }
+func (c *codec) NewWriter(w io.Writer) (CompressWriter, error) { return c.fn(w) }
+
+// NewCodec returns a Codec of the compressor, and registers it by name, see
+// LookupCodec. eg. for zstd, snappy or lz4
+//
+//	loggers.NewCodec("zstd", ".zst", func(w io.Writer) (loggers.CompressWriter, error) {
+//		return zstd.NewWriter(w)
+//	})
+func NewCodec(name, ext string, fn Compressor) Codec {
+	c := &codec{name, ext, fn}
+	codecs.Lock()
+	codecs.m[strings.ToLower(name)] = c
+	codecs.Unlock()
+	return c
+}
+
+var codecs = struct {
+	sync.RWMutex
+	m map[string]Codec
+}{m: map[string]Codec{}}
+
+// LookupCodec returns the Codec registered with the name, eg. `gzip`
+func LookupCodec(name string) (Codec, error) {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	c, ok := codecs.m[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("jog: unknown compression codec %q", name)
+	}
+	return c, nil
+}
+
+var (
+	// Gzip is the gzip Codec
+	Gzip = NewCodec("gzip", ".gz", func(w io.Writer) (CompressWriter, error) {
+		return gzip.NewWriter(w), nil
+	})
+	// Deflate is the zlib Codec, HTTP's `deflate` Content-Encoding
+	Deflate = NewCodec("deflate", ".zz", func(w io.Writer) (CompressWriter, error) {
+		return zlib.NewWriter(w), nil
+	})
+)
+
+// Compresses `b` as a whole
+func compress(c Codec, b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := c.NewWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Writes the record, flushing the compressor so it's readable right away
+func writeRecord(w io.Writer, zw CompressWriter, b []byte) (int, error) {
+	if zw == nil {
+		return w.Write(b)
+	}
+	n, err := zw.Write(b)
+	if err != nil {
+		return n, err
+	}
+	return n, zw.Flush()
+}
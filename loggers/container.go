@@ -0,0 +1,85 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"code.minty.io/jog"
+)
+
+var (
+	containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+	cgroupFile         = "/proc/self/cgroup"
+	mountInfoFile      = "/proc/self/mountinfo"
+)
+
+// Finds the last container ID within cgroup, or mountinfo, contents
+func containerID(s string) string {
+	ids := containerIDPattern.FindAllString(s, -1)
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[len(ids)-1]
+}
+
+// Guesses the container runtime from cgroup contents
+func containerRuntime(s string) string {
+	switch {
+	case strings.Contains(s, "cri-containerd") || strings.Contains(s, "containerd"):
+		return "containerd"
+	case strings.Contains(s, "crio-"):
+		return "cri-o"
+	case strings.Contains(s, "docker"):
+		return "docker"
+	case strings.Contains(s, "libpod"):
+		return "podman"
+	}
+	return ""
+}
+
+// ContainerMetadata returns the ID, and runtime, of the container the process runs in.
+// The ID is detected from the process's cgroup, falling back to its mounts for
+// cgroup v2 hosts. The image is taken from the `CONTAINER_IMAGE` environment
+// variable, as it isn't visible from within the container.
+// It returns nil when not running within a container.
+func ContainerMetadata() map[string]interface{} {
+	cg, _ := os.ReadFile(cgroupFile)
+	id := containerID(string(cg))
+	if id == "" {
+		mi, _ := os.ReadFile(mountInfoFile)
+		id = containerID(string(mi))
+	}
+	if id == "" {
+		return nil
+	}
+
+	md := map[string]interface{}{"id": id}
+	rt := containerRuntime(string(cg))
+	if rt == "" {
+		if _, err := os.Stat("/.dockerenv"); err == nil {
+			rt = "docker"
+		}
+	}
+	if rt != "" {
+		md["runtime"] = rt
+	}
+	if img := os.Getenv("CONTAINER_IMAGE"); img != "" {
+		md["image"] = img
+	}
+	return md
+}
+
+// Container returns a jog.Logger that adds the container's metadata, see
+// ContainerMetadata, under a `container` key to every message before passing it to `l`
+func Container(l jog.Logger) jog.Logger {
+	md := ContainerMetadata()
+	if md == nil {
+		return l
+	}
+	return Enrich(l, map[string]interface{}{"container": md})
+}
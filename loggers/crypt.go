@@ -0,0 +1,138 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Prefix of an encrypted record, which is followed by the base64 of its nonce
+// and ciphertext, and ends with a newline
+const recordPrefix = "jog1:"
+
+// ErrDecrypt is returned when a record can't be decrypted
+var ErrDecrypt = errors.New("jog: unable to decrypt record")
+
+// ErrUnencrypted is returned by Decrypt for a line that isn't an encrypted record
+var ErrUnencrypted = errors.New("jog: unencrypted record")
+
+type recordCipher struct {
+	aead cipher.AEAD
+}
+
+func newRecordCipher(key []byte) (*recordCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &recordCipher{aead}, nil
+}
+
+// Encrypts a record into a single line
+func (c *recordCipher) seal(b []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(b)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := c.aead.Seal(nonce, nonce, b, nil)
+	out := make([]byte, len(recordPrefix)+base64.StdEncoding.EncodedLen(len(sealed))+1)
+	n := copy(out, recordPrefix)
+	base64.StdEncoding.Encode(out[n:], sealed)
+	out[len(out)-1] = '\n'
+	return out, nil
+}
+
+// Decrypts a line produced by seal
+func (c *recordCipher) open(line []byte) ([]byte, error) {
+	line = bytes.TrimSpace(line)
+	if !bytes.HasPrefix(line, []byte(recordPrefix)) {
+		return nil, ErrDecrypt
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(line[len(recordPrefix):]))
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	ns := c.aead.NonceSize()
+	b, err := c.aead.Open(nil, sealed[:ns], sealed[ns:], nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return b, nil
+}
+
+// KeyFromEnv returns a FileConfig.Key reading a hex, or base64, encoded key from
+// the environment variable
+func KeyFromEnv(name string) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		s := os.Getenv(name)
+		if s == "" {
+			return nil, fmt.Errorf("jog: the key variable `%s` isn't set", name)
+		}
+		if b, err := hex.DecodeString(s); err == nil {
+			return b, nil
+		}
+		if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+			return b, nil
+		}
+		return nil, fmt.Errorf("jog: the key in `%s` isn't hex or base64 encoded", name)
+	}
+}
+
+// Decrypt copies the records of an encrypted file from `r` to `w`, decrypted.
+// Lines that aren't encrypted fail with ErrUnencrypted, as they aren't
+// authenticated, see DecryptMixed.
+func Decrypt(key []byte, r io.Reader, w io.Writer) error {
+	return decrypt(key, r, w, false)
+}
+
+// DecryptMixed is Decrypt, except lines that aren't encrypted are copied as is, eg.
+// those written before encryption was turned on. Anyone able to append to the file
+// can add such lines, so they shouldn't be trusted.
+func DecryptMixed(key []byte, r io.Reader, w io.Writer) error {
+	return decrypt(key, r, w, true)
+}
+
+func decrypt(key []byte, r io.Reader, w io.Writer, mixed bool) error {
+	c, err := newRecordCipher(key)
+	if err != nil {
+		return err
+	}
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 16<<20)
+	for n := 1; s.Scan(); n++ {
+		line := s.Bytes()
+		if !bytes.HasPrefix(line, []byte(recordPrefix)) {
+			if !mixed {
+				return fmt.Errorf("%w on line %d", ErrUnencrypted, n)
+			}
+			if _, err := w.Write(append(line, '\n')); err != nil {
+				return err
+			}
+			continue
+		}
+		b, err := c.open(line)
+		if err != nil {
+			return fmt.Errorf("%w on line %d", err, n)
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
@@ -0,0 +1,10 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import "code.minty.io/jog"
+
+// Discard is a jog.Logger that drops everything passed to it
+var Discard = jog.Discard
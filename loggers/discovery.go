@@ -0,0 +1,131 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Resolver returns the current set of endpoint addresses
+type Resolver func() ([]string, error)
+
+// SRV returns a Resolver looking up the `host:port` targets of a DNS SRV record,
+// eg. SRV("jog", "tcp", "collectors.internal") for `_jog._tcp.collectors.internal`.
+// Targets are ordered by priority, then weight.
+func SRV(service, proto, name string) Resolver {
+	return func() ([]string, error) {
+		_, srvs, err := net.LookupSRV(service, proto, name)
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(srvs, func(i, j int) bool {
+			if srvs[i].Priority != srvs[j].Priority {
+				return srvs[i].Priority < srvs[j].Priority
+			}
+			return srvs[i].Weight > srvs[j].Weight
+		})
+		addrs := make([]string, len(srvs))
+		for i, s := range srvs {
+			addrs[i] = net.JoinHostPort(strings.TrimSuffix(s.Target, "."), strconv.Itoa(int(s.Port)))
+		}
+		return addrs, nil
+	}
+}
+
+// DiscoveryConfig holds the settings for a Discovery logger
+type DiscoveryConfig struct {
+	// Resolve returns the endpoint addresses, eg. SRV(...) or a user callback
+	Resolve Resolver
+	// New returns the Logger used for an address
+	New func(addr string) jog.Logger
+	// Interval the addresses are refreshed at (defaults to 30s)
+	Interval time.Duration
+	// Balance configures how messages are spread across the endpoints
+	Balance BalanceConfig
+}
+
+// Discovery is a jog.Logger that balances messages across dynamically resolved
+// endpoints, refreshing them periodically so collector fleets can scale without
+// reconfiguring every service. Failed refreshes keep the previous endpoints.
+type Discovery struct {
+	*Balancer
+	cfg  DiscoveryConfig
+	stop chan struct{}
+	once sync.Once
+}
+
+// Refresh resolves the endpoints now
+func (d *Discovery) Refresh() error {
+	addrs, err := d.cfg.Resolve()
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return errors.New("jog: no endpoints resolved")
+	}
+
+	// Keep the existing loggers of addresses that are still present
+	existing := make(map[string]jog.Logger)
+	d.Balancer.mu.RLock()
+	for _, e := range d.Balancer.endpoints {
+		existing[e.Name] = e.Logger
+	}
+	d.Balancer.mu.RUnlock()
+
+	endpoints := make([]Endpoint, len(addrs))
+	for i, a := range addrs {
+		l, ok := existing[a]
+		if !ok {
+			l = d.cfg.New(a)
+		}
+		endpoints[i] = Endpoint{a, l}
+	}
+	d.SetEndpoints(endpoints...)
+	return nil
+}
+
+// Close stops refreshing the endpoints
+func (d *Discovery) Close() error {
+	d.once.Do(func() { close(d.stop) })
+	return nil
+}
+
+func (d *Discovery) run() {
+	t := time.NewTicker(d.cfg.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			d.Refresh()
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// NewDiscovery returns a new Discovery logger, failing when the endpoints can't be resolved
+func NewDiscovery(c DiscoveryConfig) (*Discovery, error) {
+	if c.Interval <= 0 {
+		c.Interval = 30 * time.Second
+	}
+	d := &Discovery{
+		Balancer: NewBalancer(c.Balance),
+		cfg:      c,
+		stop:     make(chan struct{}),
+	}
+	if err := d.Refresh(); err != nil {
+		return nil, err
+	}
+	go d.run()
+	return d, nil
+}
@@ -0,0 +1,32 @@
+package loggers
+
+import (
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestDiscoveryRefresh(t *testing.T) {
+	addrs := []string{"a:1", "b:1"}
+	created := map[string]*testLogger{}
+	d, err := NewDiscovery(DiscoveryConfig{
+		Resolve: func() ([]string, error) { return addrs, nil },
+		New: func(addr string) jog.Logger {
+			created[addr] = &testLogger{}
+			return created[addr]
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	addrs = []string{"b:1", "c:1"}
+	d.Refresh()
+	for i := 0; i < 4; i++ {
+		d.Log(msg(i))
+	}
+	if len(created) != 3 || created["a:1"].count() != 0 || created["b:1"].count() != 2 {
+		t.Error("Expected endpoints to follow the resolver, got", len(created), d.Health())
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"sync"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// DurableConfig holds the settings for a Durable logger
+type DurableConfig struct {
+	// Spool is the file path messages are kept in until they're delivered
+	Spool string
+	// BatchSize is the most messages sent at once (defaults to 100)
+	BatchSize int
+	// Retry is the delay after a failed delivery, doubling up to a minute
+	// (defaults to 1s)
+	Retry time.Duration
+	// TTL is how long messages of each level are kept, see Spool.SetTTL
+	TTL map[jog.Level]time.Duration
+}
+
+// Durable is a jog.Logger giving at-least-once delivery. Messages are written to
+// a disk spool, and only removed once the backend confirms receipt, ie. its Log,
+// or LogBatch, returns without an error (eg. a 2xx response). Messages that
+// weren't delivered are sent after a restart, so the backend may see duplicates.
+type Durable struct {
+	logger jog.Logger
+	spool  *Spool
+	size   int
+	retry  time.Duration
+	wake   chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Log writes the message to the spool, to be delivered in the background
+func (d *Durable) Log(m interface{}) (int, error) {
+	if err := d.spool.Append(m); err != nil {
+		return 0, err
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return 0, nil
+}
+
+// Pending returns the number of messages waiting to be delivered
+func (d *Durable) Pending() int {
+	return d.spool.Len()
+}
+
+// Close makes a last attempt at delivering the pending messages, leaving any
+// that fail in the spool for the next run
+func (d *Durable) Close() error {
+	d.once.Do(func() { close(d.stop) })
+	<-d.done
+	return d.spool.Close()
+}
+
+func (d *Durable) run() {
+	defer close(d.done)
+	delay := d.retry
+	for {
+		err := d.ship()
+		wake := d.wake
+		if err == nil {
+			delay = d.retry
+		} else {
+			// Backing off, messages logged meanwhile wait for the retry
+			wake = nil
+			jog.Diagnose(jog.WARNING, "delivery_failed", map[string]interface{}{"pending": d.spool.Len(), "retry_ms": delay.Milliseconds(), "error": err.Error()})
+		}
+		select {
+		case <-d.stop:
+			d.ship()
+			return
+		case <-wake:
+			continue
+		case <-time.After(delay):
+		}
+		if err != nil {
+			if delay *= 2; delay > time.Minute {
+				delay = time.Minute
+			}
+		}
+	}
+}
+
+// Sends batches until the spool is empty, acknowledging each once delivered
+func (d *Durable) ship() error {
+	for {
+		msgs, n, err := d.spool.Peek(d.size)
+		if err != nil || n == 0 {
+			return err
+		}
+		if len(msgs) > 0 {
+			if _, err := jog.LogBatch(d.logger, msgs); err != nil {
+				return err
+			}
+		}
+		if err := d.spool.Ack(n); err != nil {
+			return err
+		}
+	}
+}
+
+// NewDurable returns a new Durable logger delivering to `l`, resuming the delivery
+// of messages left in the spool by a previous run
+func NewDurable(l jog.Logger, c DurableConfig) (*Durable, error) {
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.Retry <= 0 {
+		c.Retry = time.Second
+	}
+	s, err := OpenSpool(c.Spool)
+	if err != nil {
+		return nil, err
+	}
+	s.SetTTL(c.TTL)
+	d := &Durable{
+		logger: l,
+		spool:  s,
+		size:   c.BatchSize,
+		retry:  c.Retry,
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go d.run()
+	return d, nil
+}
@@ -0,0 +1,62 @@
+package loggers
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDurableRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool")
+
+	// Nothing is acknowledged while the backend is down
+	down := &failLogger{}
+	d, err := NewDurable(down, DurableConfig{Spool: path, Retry: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		d.Log(msg(i))
+	}
+	d.Close()
+	if down.calls == 0 {
+		t.Fatal("Expected delivery to be attempted")
+	}
+
+	// After a restart the messages are delivered, and removed from the spool
+	up := &testLogger{}
+	if d, err = NewDurable(up, DurableConfig{Spool: path}); err != nil {
+		t.Fatal(err)
+	}
+	d.Log(msg(3))
+	for i := 0; i < 100 && d.Pending() > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	d.Close()
+	if n := up.count(); n != 4 {
+		t.Error("Expected 4 messages delivered, got", n)
+	}
+	s, _ := OpenSpool(path)
+	defer s.Close()
+	if s.Len() != 0 {
+		t.Error("Expected an empty spool, got", s.Len())
+	}
+}
+
+func TestDurableBackoff(t *testing.T) {
+	down := &failLogger{}
+	d, err := NewDurable(down, DurableConfig{Spool: filepath.Join(t.TempDir(), "spool"), Retry: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Messages logged during the outage don't cut the backoff short
+	for i := 0; i < 200; i++ {
+		d.Log(msg(i))
+		time.Sleep(time.Millisecond)
+	}
+	d.Close()
+	// The first attempt, retries after 100ms and 300ms, and the last by Close
+	if down.calls < 2 || down.calls > 5 {
+		t.Error("Expected delivery to back off, got", down.calls, "attempts")
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"code.minty.io/jog"
+)
+
+type enrich struct {
+	logger jog.Logger
+	fields map[string]interface{}
+}
+
+func (e *enrich) Log(m interface{}) (int, error) {
+	if msg, ok := m.(*jog.Message); ok {
+		msg.MergeMeta(e.fields)
+	}
+	return e.logger.Log(m)
+}
+
+// Enrich returns a jog.Logger that adds the fields to every message's Meta
+// before passing it to `l`
+func Enrich(l jog.Logger, fields map[string]interface{}) jog.Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	return &enrich{l, fields}
+}
+
+// Namespace file of the pod's service account
+var k8sNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// KubernetesMetadata returns the pod's metadata, read from the environment variables
+// commonly populated by the Downward API
+//
+//	env:
+//	- name: POD_NAME
+//	  valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	- name: POD_NAMESPACE
+//	  valueFrom: {fieldRef: {fieldPath: metadata.namespace}}
+//	- name: NODE_NAME
+//	  valueFrom: {fieldRef: {fieldPath: spec.nodeName}}
+//	- name: CONTAINER_NAME
+//	  value: app
+//
+// The pod name falls back to the hostname, and the namespace to the service account's.
+// It returns nil when not running within Kubernetes.
+func KubernetesMetadata() map[string]interface{} {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" && os.Getenv("POD_NAME") == "" {
+		return nil
+	}
+	md := map[string]interface{}{}
+	set := func(k, v string) {
+		if v = strings.TrimSpace(v); v != "" {
+			md[k] = v
+		}
+	}
+
+	pod := os.Getenv("POD_NAME")
+	if pod == "" {
+		pod, _ = os.Hostname()
+	}
+	set("pod", pod)
+	ns := os.Getenv("POD_NAMESPACE")
+	if ns == "" {
+		if b, err := os.ReadFile(k8sNamespaceFile); err == nil {
+			ns = string(b)
+		}
+	}
+	set("namespace", ns)
+	set("node", os.Getenv("NODE_NAME"))
+	set("container", os.Getenv("CONTAINER_NAME"))
+	return md
+}
+
+// Kubernetes returns a jog.Logger that adds the pod's metadata, see KubernetesMetadata,
+// under a `kubernetes` key to every message before passing it to `l`
+func Kubernetes(l jog.Logger) jog.Logger {
+	md := KubernetesMetadata()
+	if md == nil {
+		return l
+	}
+	return Enrich(l, map[string]interface{}{"kubernetes": md})
+}
+
+// Returns a copy of the message's Data as a map, converting objects through their
+// JSON form, so fields can be added without changing the caller's value
+func dataMap(msg *jog.Message) (map[string]interface{}, bool) {
+	var d map[string]interface{}
+	switch v := msg.Data.(type) {
+	case nil, string:
+		return nil, false
+	case map[string]interface{}:
+		d = make(map[string]interface{}, len(v)+1)
+		for k, e := range v {
+			d[k] = e
+		}
+	default:
+		b, err := msg.DataJSON()
+		if err != nil || json.Unmarshal(b, &d) != nil || d == nil {
+			return nil, false
+		}
+	}
+	return d, true
+}
@@ -0,0 +1,63 @@
+package loggers
+
+import (
+	"fmt"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestKubernetes(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	t.Setenv("POD_NAME", "web-7d9f")
+	t.Setenv("POD_NAMESPACE", "prod")
+	t.Setenv("NODE_NAME", "node-3")
+	t.Setenv("CONTAINER_NAME", "")
+
+	l := &testLogger{}
+	Kubernetes(l).Log(msg("hello"))
+	m := l.messages[0].(*jog.Message)
+	d := fmt.Sprint(m.Meta)
+	expected := "map[kubernetes:map[namespace:prod node:node-3 pod:web-7d9f]]"
+	if d != expected || m.Data != "hello" {
+		t.Error("Expected", expected, "got", d)
+	}
+}
+
+func TestContainerID(t *testing.T) {
+	id := "3f4e1c0d9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d"
+	tests := map[string]string{
+		"12:cpu,cpuacct:/docker/" + id + "\n":                                        "docker",
+		"0::/system.slice/cri-containerd-" + id + ".scope\n":                         "containerd",
+		"11:memory:/kubepods/burstable/pod1234/crio-" + id + ".scope\n":              "cri-o",
+		"0::/user.slice/user-1000.slice/user@1000.service/libpod-" + id + ".scope\n": "podman",
+	}
+	for cg, rt := range tests {
+		if v := containerID(cg); v != id {
+			t.Error("Expected", id, "got", v)
+		}
+		if v := containerRuntime(cg); v != rt {
+			t.Error("Expected", rt, "got", v)
+		}
+	}
+	if v := containerID("0::/\n"); v != "" {
+		t.Error("Expected no container ID, got", v)
+	}
+}
+
+func TestRuntimeStats(t *testing.T) {
+	l := &testLogger{}
+	r := RuntimeStats(l, jog.ERROR, 0)
+
+	m := msg("failed")
+	m.Level = jog.ERROR
+	r.Log(m)
+	r.Log(msg("fine"))
+
+	if m := l.messages[0].(*jog.Message); m.Meta["runtime"] == nil || m.Data != "failed" {
+		t.Error("Expected runtime stats on the error, got", m.Meta)
+	}
+	if m := l.messages[1].(*jog.Message); m.Meta != nil {
+		t.Error("Expected info message to be left alone, got", m.Meta)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package eventlog contains a jog.Logger that writes to the Windows Event Log.
+//
+// The event source is registered once, typically by the service's installer
+//
+//	eventlog.Install("MyService")
+//
+// then messages are logged as JSON under that source
+//
+//	l, err := eventlog.New("MyService")
+//	j := jog.New(l)
+package eventlog
+
+import (
+	"errors"
+
+	"code.minty.io/jog"
+)
+
+// Severity is the type of an Event Log entry
+type Severity int
+
+const (
+	Information Severity = iota
+	Warning
+	Error
+)
+
+// ErrUnsupported is returned on platforms without an Event Log
+var ErrUnsupported = errors.New("jog/eventlog: the Windows Event Log is only supported on Windows")
+
+// EventID used for all entries, the Event Log requires one but jog has no use for it
+const EventID = 1
+
+// SeverityOf maps a jog Level onto an Event Log severity
+func SeverityOf(l jog.Level) Severity {
+	switch {
+	case l.AtLeast(jog.ERROR):
+		return Error
+	case l.AtLeast(jog.WARNING):
+		return Warning
+	}
+	return Information
+}
@@ -0,0 +1,36 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package eventlog
+
+// Logger is a jog.Logger that writes messages, as JSON, to the Event Log
+type Logger struct{}
+
+// Log always fails on this platform
+func (l *Logger) Log(m interface{}) (int, error) {
+	return 0, ErrUnsupported
+}
+
+// Close does nothing on this platform
+func (l *Logger) Close() error {
+	return nil
+}
+
+// Install always fails on this platform
+func Install(source string) error {
+	return ErrUnsupported
+}
+
+// Remove always fails on this platform
+func Remove(source string) error {
+	return ErrUnsupported
+}
+
+// New always fails on this platform
+func New(source string) (*Logger, error) {
+	return nil, ErrUnsupported
+}
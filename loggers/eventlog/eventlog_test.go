@@ -0,0 +1,23 @@
+package eventlog
+
+import (
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestSeverityOf(t *testing.T) {
+	tests := map[jog.Level]Severity{
+		jog.CRITICAL: Error,
+		jog.ERROR:    Error,
+		jog.WARNING:  Warning,
+		jog.INFO:     Information,
+		jog.DEBUG:    Information,
+		jog.UNKNOWN:  Information,
+	}
+	for l, s := range tests {
+		if v := SeverityOf(l); v != s {
+			t.Errorf("Expected %s to be %d, got %d", l, s, v)
+		}
+	}
+}
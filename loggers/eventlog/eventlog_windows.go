@@ -0,0 +1,69 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package eventlog
+
+import (
+	"encoding/json"
+
+	"code.minty.io/jog"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// Logger is a jog.Logger that writes messages, as JSON, to the Event Log
+type Logger struct {
+	log *eventlog.Log
+}
+
+// Log writes the message with the severity of its level
+func (l *Logger) Log(m interface{}) (int, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return 0, err
+	}
+	level := jog.INFO
+	if msg, ok := m.(*jog.Message); ok {
+		level = msg.Level
+	}
+
+	switch SeverityOf(level) {
+	case Error:
+		err = l.log.Error(EventID, string(b))
+	case Warning:
+		err = l.log.Warning(EventID, string(b))
+	default:
+		err = l.log.Info(EventID, string(b))
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Close closes the event log handle
+func (l *Logger) Close() error {
+	return l.log.Close()
+}
+
+// Install registers the event source in the registry, it requires administrator rights
+func Install(source string) error {
+	return eventlog.InstallAsEventCreate(source, eventlog.Error|eventlog.Warning|eventlog.Info)
+}
+
+// Remove deletes the event source from the registry
+func Remove(source string) error {
+	return eventlog.Remove(source)
+}
+
+// New returns a new Logger writing under the given event source
+func New(source string) (*Logger, error) {
+	l, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	return &Logger{l}, nil
+}
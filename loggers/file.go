@@ -0,0 +1,229 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// FileConfig holds the settings for a File logger
+type FileConfig struct {
+	// Path of the file, which may be a template of the time, eg. `app-%Y%m%d.log`,
+	// see FilePath. A new file is started whenever the templated path changes, so
+	// `%d` rolls at midnight and `%H` on the hour.
+	Path string
+	// UTC selects UTC, rather than local, time for the Path template
+	UTC bool
+	// Clock is the source of time for the Path template (defaults to time.Now)
+	Clock func() time.Time
+	// Encoder of the messages (defaults to jog.JSON)
+	Encoder jog.Encoder
+	// Perm of a newly created file (defaults to 0600)
+	Perm os.FileMode
+	// Key, when set, returns the AES key (16, 24 or 32 bytes) used to encrypt each
+	// record with AES-GCM, eg. KeyFromEnv or a callback fetching a key from a KMS.
+	// Read encrypted files with `jogcat -decrypt`.
+	Key func() ([]byte, error)
+	// Compress, when set, compresses the file as it's written, eg. Gzip for a
+	// `.gz` Path. Each record is flushed, so the file is readable up to the last
+	// message, and a reopened file is continued as a new stream.
+	Compress Codec
+}
+
+// File is a jog.Logger that appends encoded messages to a file
+type File struct {
+	mu     sync.Mutex
+	cfg    FileConfig
+	path   string
+	file   *os.File
+	zw     CompressWriter
+	cipher *recordCipher
+}
+
+// Log encodes the message, encrypting it when configured, and appends it to the file
+func (f *File) Log(m interface{}) (int, error) {
+	b, err := encode(f.cfg.Encoder, m)
+	if err != nil {
+		return 0, err
+	}
+	if f.cipher != nil {
+		if b, err = f.cipher.seal(b); err != nil {
+			return 0, err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.roll(); err != nil {
+		return 0, err
+	}
+	return writeRecord(f.file, f.zw, b)
+}
+
+// Reopen closes the file and opens it again by its path, eg. after it was moved
+// by logrotate, so writing continues to a new file
+func (f *File) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.close()
+	return f.roll()
+}
+
+// ReopenOnSignal calls Reopen whenever one of the signals is received, SIGUSR1 when
+// none are given (where supported). Calling stop restores the default handling.
+func (f *File) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = reopenSignals
+	}
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, sigs...)
+	go func() {
+		for {
+			select {
+			case <-c:
+				f.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+// Path returns the path of the file currently being written
+func (f *File) Path() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.path
+}
+
+// Opens the file for the current time, closing the previous one, when the Path
+// template yields a new path
+func (f *File) roll() error {
+	t := f.cfg.Clock()
+	if f.cfg.UTC {
+		t = t.UTC()
+	}
+	path := FilePath(f.cfg.Path, t)
+	if f.file != nil && path == f.path {
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, f.cfg.Perm)
+	if err != nil {
+		return err
+	}
+	var zw CompressWriter
+	if f.cfg.Compress != nil {
+		if zw, err = f.cfg.Compress.NewWriter(file); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	f.close()
+	f.file, f.zw, f.path = file, zw, path
+	return nil
+}
+
+// Closes the compressor, and the file, if open
+func (f *File) close() error {
+	if f.file == nil {
+		return nil
+	}
+	var err error
+	if f.zw != nil {
+		err = f.zw.Close()
+	}
+	if e := f.file.Close(); err == nil {
+		err = e
+	}
+	f.file, f.zw = nil, nil
+	return err
+}
+
+// FilePath expands the time verbs of a path template: `%Y` year, `%m` month,
+// `%d` day, `%H` hour, `%M` minute, and `%%` a percent sign
+func FilePath(template string, t time.Time) string {
+	if !strings.Contains(template, "%") {
+		return template
+	}
+	pad := func(n int) string {
+		if n < 10 {
+			return "0" + strconv.Itoa(n)
+		}
+		return strconv.Itoa(n)
+	}
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if c != '%' || i == len(template)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch template[i] {
+		case 'Y':
+			b.WriteString(strconv.Itoa(t.Year()))
+		case 'm':
+			b.WriteString(pad(int(t.Month())))
+		case 'd':
+			b.WriteString(pad(t.Day()))
+		case 'H':
+			b.WriteString(pad(t.Hour()))
+		case 'M':
+			b.WriteString(pad(t.Minute()))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(template[i])
+		}
+	}
+	return b.String()
+}
+
+// Close closes the file
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.close()
+}
+
+// NewFile returns a new File logger, opening or creating the file
+func NewFile(c FileConfig) (*File, error) {
+	if c.Encoder == nil {
+		c.Encoder = jog.JSON
+	}
+	if c.Perm == 0 {
+		c.Perm = 0600
+	}
+	if c.Clock == nil {
+		c.Clock = time.Now
+	}
+	f := &File{cfg: c}
+	if c.Key != nil {
+		key, err := c.Key()
+		if err != nil {
+			return nil, err
+		}
+		if f.cipher, err = newRecordCipher(key); err != nil {
+			return nil, err
+		}
+	}
+	if err := f.roll(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,118 @@
+package loggers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("JOG_TEST_KEY", "000102030405060708090a0b0c0d0e0f000102030405060708090a0b0c0d0e0f")
+
+	f, err := NewFile(FileConfig{Path: path, Key: KeyFromEnv("JOG_TEST_KEY")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Log(msg("secret one"))
+	f.Log(msg("secret two"))
+	f.Close()
+
+	b, _ := os.ReadFile(path)
+	if bytes.Contains(b, []byte("secret")) || bytes.Count(b, []byte("\n")) != 2 {
+		t.Fatalf("Expected 2 encrypted lines, got %s", b)
+	}
+
+	key, _ := KeyFromEnv("JOG_TEST_KEY")()
+	var out bytes.Buffer
+	if err := Decrypt(key, bytes.NewReader(b), &out); err != nil {
+		t.Fatal(err)
+	}
+	if s := out.String(); !strings.Contains(s, `"data":"secret one"`) || !strings.Contains(s, `"data":"secret two"`) {
+		t.Error("Unexpected decrypted output", s)
+	}
+
+	// Injected lines aren't passed off as records
+	injected := append([]byte(`{"data":"forged"}`+"\n"), b...)
+	if err := Decrypt(key, bytes.NewReader(injected), io.Discard); !errors.Is(err, ErrUnencrypted) {
+		t.Error("Expected an unencrypted line to fail, got", err)
+	}
+	out.Reset()
+	if err := DecryptMixed(key, bytes.NewReader(injected), &out); err != nil || !strings.HasPrefix(out.String(), `{"data":"forged"}`) {
+		t.Error("Expected an unencrypted line to be copied, got", out.String(), err)
+	}
+
+	key[0] ^= 1
+	if err := Decrypt(key, bytes.NewReader(b), &out); !errors.Is(err, ErrDecrypt) {
+		t.Error("Expected the wrong key to fail, got", err)
+	}
+}
+
+func TestFilePartitioned(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2014, 3, 6, 23, 59, 0, 0, time.UTC)
+	f, err := NewFile(FileConfig{
+		Path:  filepath.Join(dir, "app-%Y%m%d.log"),
+		UTC:   true,
+		Clock: func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Log(msg("one"))
+	now = now.Add(2 * time.Minute)
+	f.Log(msg("two"))
+	f.Close()
+
+	for name, data := range map[string]string{"app-20140306.log": "one", "app-20140307.log": "two"} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || !strings.Contains(string(b), data) || bytes.Count(b, []byte("\n")) != 1 {
+			t.Errorf("Expected %s to hold %q, got %s %v", name, data, b, err)
+		}
+	}
+}
+
+func TestFilePath(t *testing.T) {
+	tm := time.Date(2014, 3, 6, 9, 5, 0, 0, time.UTC)
+	if p := FilePath("app-%Y-%m-%dT%H%M-100%%-%x.log", tm); p != "app-2014-03-06T0905-100%-%x.log" {
+		t.Error("Unexpected path", p)
+	}
+}
+
+func TestFileGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.gz")
+	for _, d := range []string{"one", "two"} {
+		f, err := NewFile(FileConfig{Path: path, Compress: Gzip})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Log(msg(d))
+		f.Close()
+	}
+
+	file, _ := os.Open(path)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(gz)
+	if s := string(b); !strings.Contains(s, `"data":"one"`) || !strings.Contains(s, `"data":"two"`) {
+		t.Error("Unexpected decompressed file", s)
+	}
+}
+
+func TestLookupCodec(t *testing.T) {
+	if c, err := LookupCodec("GZIP"); err != nil || c != Gzip || c.Extension() != ".gz" {
+		t.Error("Expected the gzip codec, got", c, err)
+	}
+	if _, err := LookupCodec("zstd"); err == nil {
+		t.Error("Expected an unregistered codec to fail")
+	}
+}
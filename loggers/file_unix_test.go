@@ -0,0 +1,41 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package loggers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFileReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := NewFile(FileConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	stop := f.ReopenOnSignal(syscall.SIGUSR1)
+	defer stop()
+
+	f.Log(msg("one"))
+	os.Rename(path, path+".1")
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	f.Log(msg("two"))
+
+	old, _ := os.ReadFile(path + ".1")
+	b, _ := os.ReadFile(path)
+	if !strings.Contains(string(old), "one") || !strings.Contains(string(b), "two") || strings.Contains(string(b), "one") {
+		t.Errorf("Expected the file to be reopened, got %s and %s", old, b)
+	}
+}
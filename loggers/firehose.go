@@ -0,0 +1,183 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Limits of a single PutRecordBatch call
+const (
+	firehoseMaxRecords = 500
+	firehoseMaxBytes   = 4 << 20
+	firehoseMaxRecord  = 1000 << 10
+)
+
+// FirehoseConfig holds the settings for a Kinesis Firehose logger
+type FirehoseConfig struct {
+	Stream      string
+	Region      string
+	Credentials AWSCredentials
+	// Endpoint (defaults to `https://firehose.{Region}.amazonaws.com`)
+	Endpoint string
+	// Retries of records that fail within a batch (defaults to 3)
+	Retries int
+	// BatchSize and Interval control how often records are sent (default 500, 1s)
+	BatchSize int
+	Interval  time.Duration
+	Client    *http.Client
+}
+
+// Firehose is a jog.Logger that sends messages, as newline terminated JSON
+// records, to a Kinesis Firehose delivery stream using PutRecordBatch
+type Firehose struct {
+	cfg   FirehoseConfig
+	batch *jog.Batcher
+}
+
+type firehoseRecord struct {
+	Data []byte
+}
+
+type firehoseResponse struct {
+	FailedPutCount   int
+	RequestResponses []struct {
+		ErrorCode    string
+		ErrorMessage string
+	}
+}
+
+// Log queues the message, sending the batch once it's full
+func (f *Firehose) Log(m interface{}) (int, error) {
+	return f.batch.Log(m)
+}
+
+// LogBatch sends the messages now, as a whole batch, see jog.BatchLogger
+func (f *Firehose) LogBatch(msgs []*jog.Message) (int, error) {
+	return 0, f.write(msgs)
+}
+
+// Flush sends all pending messages
+func (f *Firehose) Flush() error {
+	return f.batch.Flush()
+}
+
+// Close sends any pending messages and stops the background flushing
+func (f *Firehose) Close() error {
+	return f.batch.Close()
+}
+
+// Splits the messages into calls within the PutRecordBatch limits, skipping any
+// larger than a record may be
+func (f *Firehose) write(msgs []*jog.Message) error {
+	var records []firehoseRecord
+	size := 0
+	for _, m := range msgs {
+		b, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		b = append(b, '\n')
+		if len(b) > firehoseMaxRecord {
+			// Skipped, rather than failing the rest of the batch
+			jog.Diagnose(jog.WARNING, "oversized", map[string]interface{}{"backend": "firehose", "bytes": len(b), "limit": firehoseMaxRecord})
+			continue
+		}
+		if len(records) == firehoseMaxRecords || size+len(b) > firehoseMaxBytes {
+			if err := f.put(records); err != nil {
+				return err
+			}
+			records, size = nil, 0
+		}
+		records = append(records, firehoseRecord{b})
+		size += len(b)
+	}
+	return f.put(records)
+}
+
+// Sends the records, retrying any that failed
+func (f *Firehose) put(records []firehoseRecord) error {
+	for attempt := 0; len(records) > 0; attempt++ {
+		resp, err := f.call(records)
+		if err != nil {
+			return err
+		}
+		if resp.FailedPutCount == 0 {
+			return nil
+		}
+
+		var failed []firehoseRecord
+		var last string
+		for i, r := range resp.RequestResponses {
+			if r.ErrorCode != "" && i < len(records) {
+				failed = append(failed, records[i])
+				last = r.ErrorCode + ": " + r.ErrorMessage
+			}
+		}
+		if attempt >= f.cfg.Retries {
+			return fmt.Errorf("jog: %d Firehose records failed, last error %s", len(failed), last)
+		}
+		records = failed
+		jog.Diagnose(jog.INFO, "retry", map[string]interface{}{"backend": "firehose", "attempt": attempt + 1, "records": len(failed), "error": last})
+		time.Sleep(time.Duration(100<<uint(attempt)) * time.Millisecond)
+	}
+	return nil
+}
+
+func (f *Firehose) call(records []firehoseRecord) (*firehoseResponse, error) {
+	body, err := json.Marshal(struct {
+		DeliveryStreamName string
+		Records            []firehoseRecord
+	}{f.cfg.Stream, records})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", f.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "Firehose_20150804.PutRecordBatch")
+	signV4(req, body, "firehose", f.cfg.Region, f.cfg.Credentials, time.Now())
+
+	resp, err := f.cfg.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("received a `%d` from Firehose: %s", resp.StatusCode, b)
+	}
+	r := new(firehoseResponse)
+	return r, json.NewDecoder(resp.Body).Decode(r)
+}
+
+// NewFirehose returns a new Firehose logger
+func NewFirehose(c FirehoseConfig) *Firehose {
+	if c.Endpoint == "" {
+		c.Endpoint = fmt.Sprintf("https://firehose.%s.amazonaws.com/", c.Region)
+	}
+	if c.Retries <= 0 {
+		c.Retries = 3
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = firehoseMaxRecords
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	f := &Firehose{cfg: c}
+	f.batch = jog.NewBatcher(c.BatchSize, c.Interval, f.write)
+	return f
+}
@@ -0,0 +1,67 @@
+package loggers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+func TestFirehoseRetry(t *testing.T) {
+	var calls []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Records []firehoseRecord }
+		json.NewDecoder(r.Body).Decode(&body)
+		calls = append(calls, len(body.Records))
+
+		// Fail the first record of the first call
+		if len(calls) == 1 {
+			fmt.Fprint(w, `{"FailedPutCount": 1, "RequestResponses": [{"ErrorCode": "ServiceUnavailable"}, {}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"FailedPutCount": 0}`)
+	}))
+	defer srv.Close()
+
+	f := NewFirehose(FirehoseConfig{Stream: "logs", Region: "us-east-1", Endpoint: srv.URL, Interval: time.Hour})
+	f.Log(msg("one"))
+	f.Log(msg("two"))
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(calls) != "[2 1]" {
+		t.Error("Expected the failed record to be retried alone, got", calls)
+	}
+}
+
+func TestFirehoseOversized(t *testing.T) {
+	var calls []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Records []firehoseRecord }
+		json.NewDecoder(r.Body).Decode(&body)
+		calls = append(calls, len(body.Records))
+		fmt.Fprint(w, `{"FailedPutCount": 0}`)
+	}))
+	defer srv.Close()
+	diag := &testLogger{}
+	jog.SetDiagnostics(diag)
+	defer jog.SetDiagnostics(nil)
+
+	f := NewFirehose(FirehoseConfig{Stream: "logs", Region: "us-east-1", Endpoint: srv.URL, Interval: time.Hour})
+	defer f.Close()
+	msgs := []*jog.Message{msg("one"), msg(strings.Repeat("x", firehoseMaxRecord)), msg("three")}
+	if _, err := f.LogBatch(msgs); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(calls) != "[2]" {
+		t.Error("Expected the valid records to be sent, got", calls)
+	}
+	if d, _ := diag.messages[0].(*jog.Message).Data.(map[string]interface{}); diag.count() != 1 || d["event"] != "oversized" {
+		t.Error("Expected the skipped record to be reported, got", diag.messages)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"encoding/json"
+
+	"code.minty.io/jog"
+)
+
+type flatten struct {
+	logger jog.Logger
+	sep    string
+}
+
+// Log replaces nested objects within the message's Data with top-level keys
+func (f *flatten) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return f.logger.Log(m)
+	}
+
+	var d map[string]interface{}
+	switch v := msg.Data.(type) {
+	case nil, string:
+		return f.logger.Log(m)
+	case map[string]interface{}:
+		d = v
+	default:
+		// Structs, and other maps, are converted through their JSON form
+		b, err := msg.DataJSON()
+		if err != nil || json.Unmarshal(b, &d) != nil || d == nil {
+			return f.logger.Log(m)
+		}
+	}
+
+	flat := make(map[string]interface{}, len(d))
+	f.flatten(flat, "", d)
+	msg.Data = flat
+	return f.logger.Log(msg)
+}
+
+func (f *flatten) flatten(dst map[string]interface{}, prefix string, src map[string]interface{}) {
+	for k, v := range src {
+		if prefix != "" {
+			k = prefix + f.sep + k
+		}
+		if n, ok := v.(map[string]interface{}); ok && len(n) > 0 {
+			f.flatten(dst, k, n)
+		} else {
+			dst[k] = v
+		}
+	}
+}
+
+// Flatten returns a jog.Logger that flattens nested Data into keys joined by `sep`,
+// eg. `{"user": {"id": 1}}` becomes `{"user.id": 1}`, before passing it to `l`.
+func Flatten(l jog.Logger, sep string) jog.Logger {
+	if sep == "" {
+		sep = "."
+	}
+	return &flatten{l, sep}
+}
@@ -0,0 +1,33 @@
+package loggers
+
+import (
+	"fmt"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestFlatten(t *testing.T) {
+	l := &testLogger{}
+	f := Flatten(l, "")
+
+	f.Log(msg(map[string]interface{}{
+		"user":  map[string]interface{}{"address": map[string]interface{}{"city": "Portland"}},
+		"empty": map[string]interface{}{},
+		"id":    1,
+	}))
+	f.Log(msg(struct {
+		Name  string
+		Inner struct{ Age int }
+	}{Name: "Jack"}))
+
+	expected := []string{
+		"map[empty:map[] id:1 user.address.city:Portland]",
+		"map[Inner.Age:0 Name:Jack]",
+	}
+	for i, v := range l.messages {
+		if s := fmt.Sprint(v.(*jog.Message).Data); s != expected[i] {
+			t.Error("Expected", expected[i], "got", s)
+		}
+	}
+}
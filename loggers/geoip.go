@@ -0,0 +1,94 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"net"
+
+	"code.minty.io/jog"
+)
+
+// Geo is the location of an IP address
+type Geo struct {
+	Country string `json:"country,omitempty"`
+	City    string `json:"city,omitempty"`
+	ASN     uint   `json:"asn,omitempty"`
+	Org     string `json:"org,omitempty"`
+}
+
+// GeoDB resolves IP addresses, eg. against a local MaxMind database. jog doesn't
+// read the database format itself, a reader such as geoip2-golang is adapted with
+// GeoFunc:
+//
+//	db, _ := geoip2.Open("GeoLite2-City.mmdb")
+//	loggers.GeoFunc(func(ip net.IP) (loggers.Geo, bool) {
+//		c, err := db.City(ip)
+//		if err != nil {
+//			return loggers.Geo{}, false
+//		}
+//		return loggers.Geo{Country: c.Country.IsoCode, City: c.City.Names["en"]}, true
+//	})
+type GeoDB interface {
+	Lookup(ip net.IP) (Geo, bool)
+}
+
+// GeoFunc is a GeoDB function
+type GeoFunc func(ip net.IP) (Geo, bool)
+
+// Lookup calls the function
+func (f GeoFunc) Lookup(ip net.IP) (Geo, bool) {
+	return f(ip)
+}
+
+type geoIP struct {
+	logger jog.Logger
+	db     GeoDB
+	fields []string
+}
+
+func (g *geoIP) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return g.logger.Log(m)
+	}
+	d, ok := dataMap(msg)
+	if !ok {
+		return g.logger.Log(m)
+	}
+	var found bool
+	for _, f := range g.fields {
+		s, _ := d[f].(string)
+		ip := parseIP(s)
+		if ip == nil {
+			continue
+		}
+		if geo, ok := g.db.Lookup(ip); ok {
+			d[f+"_geo"] = geo
+			found = true
+		}
+	}
+	if found {
+		msg.Data = d
+	}
+	return g.logger.Log(msg)
+}
+
+// Parses an address that may carry a port, eg. an http.Request's RemoteAddr
+func parseIP(s string) net.IP {
+	if ip := net.ParseIP(s); ip != nil {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		return net.ParseIP(host)
+	}
+	return nil
+}
+
+// GeoIP returns a jog.Logger that resolves the IP addresses of the Data fields,
+// eg. `client_ip` or httplog's `remote`, adding the location as `<field>_geo`
+// before passing the message to `l`
+func GeoIP(l jog.Logger, db GeoDB, fields ...string) jog.Logger {
+	return &geoIP{l, db, fields}
+}
@@ -0,0 +1,31 @@
+package loggers
+
+import (
+	"net"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestGeoIP(t *testing.T) {
+	db := GeoFunc(func(ip net.IP) (Geo, bool) {
+		if ip.Equal(net.ParseIP("203.0.113.7")) {
+			return Geo{Country: "NZ", City: "Auckland", ASN: 64500}, true
+		}
+		return Geo{}, false
+	})
+	l := &testLogger{}
+	g := GeoIP(l, db, "remote", "client_ip")
+	g.Log(msg(struct {
+		Remote string `json:"remote"`
+	}{"203.0.113.7:41234"}))
+	g.Log(msg(map[string]interface{}{"client_ip": "198.51.100.1"}))
+
+	d := l.messages[0].(*jog.Message).Data.(map[string]interface{})
+	if geo, ok := d["remote_geo"].(Geo); !ok || geo.City != "Auckland" || geo.ASN != 64500 {
+		t.Error("Expected the address to be located, got", d)
+	}
+	if d := l.messages[1].(*jog.Message).Data.(map[string]interface{}); len(d) != 1 {
+		t.Error("Expected unknown addresses to be left alone, got", d)
+	}
+}
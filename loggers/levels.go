@@ -0,0 +1,317 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// RootLogger is the key naming the unnamed logger in a LevelSource
+const RootLogger = "_"
+
+// LevelSource is a store of the levels of named loggers (see jog.Named), such as
+// the keys below a prefix in Consul or etcd, eg. `jog/api/db` = `debug`
+type LevelSource interface {
+	// Levels returns the levels by logger name once they've changed from those of
+	// `index`, blocking until they have or ctx is done, along with their new index
+	Levels(ctx context.Context, index uint64) (map[string]jog.Level, uint64, error)
+}
+
+// LevelWatcher applies the levels of a LevelSource as they change, so verbosity
+// can be tuned centrally across a fleet
+type LevelWatcher struct {
+	j      *jog.Jog
+	src    LevelSource
+	failed func(error)
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	levels map[string]jog.Level
+	undo   map[string]func()
+}
+
+// WatchLevels watches `src`, overriding the levels of `j`'s named loggers with
+// those found, see jog.WithTemporaryLevel. Loggers removed from the source revert
+// to their own level. Failed, when set, is called with the errors of reading it.
+func WatchLevels(j *jog.Jog, src LevelSource, failed func(error)) *LevelWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &LevelWatcher{
+		j:      j,
+		src:    src,
+		failed: failed,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		levels: map[string]jog.Level{},
+		undo:   map[string]func(){},
+	}
+	go w.run(ctx)
+	return w
+}
+
+// Levels returns the levels currently applied
+func (w *LevelWatcher) Levels() map[string]jog.Level {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	levels := make(map[string]jog.Level, len(w.levels))
+	for k, v := range w.levels {
+		levels[k] = v
+	}
+	return levels
+}
+
+// Close stops watching and reverts the levels applied
+func (w *LevelWatcher) Close() error {
+	w.cancel()
+	<-w.done
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, undo := range w.undo {
+		undo()
+	}
+	w.undo = map[string]func(){}
+	w.levels = map[string]jog.Level{}
+	return nil
+}
+
+func (w *LevelWatcher) run(ctx context.Context) {
+	defer close(w.done)
+	var index uint64
+	backoff := time.Second
+	for {
+		levels, i, err := w.src.Levels(ctx, index)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if w.failed != nil {
+				w.failed(err)
+			}
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			if backoff *= 2; backoff > time.Minute {
+				backoff = time.Minute
+			}
+			continue
+		}
+		backoff = time.Second
+		index = i
+		w.apply(levels)
+	}
+}
+
+// Overrides the levels that changed, and reverts those that were removed
+func (w *LevelWatcher) apply(levels map[string]jog.Level) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var changed int
+	for name := range w.levels {
+		if _, ok := levels[name]; !ok {
+			w.undo[name]()
+			delete(w.undo, name)
+			delete(w.levels, name)
+			changed++
+		}
+	}
+	for name, l := range levels {
+		if l == jog.UNKNOWN {
+			jog.Diagnose(jog.WARNING, "config_invalid", map[string]interface{}{"logger": name})
+			continue
+		}
+		if prev, ok := w.levels[name]; ok {
+			if prev == l {
+				continue
+			}
+			w.undo[name]()
+		}
+		j := w.j
+		if name != "" {
+			j = j.Named(name)
+		}
+		w.undo[name] = j.WithTemporaryLevel(l)
+		w.levels[name] = l
+		changed++
+	}
+	if changed > 0 {
+		jog.Diagnose(jog.INFO, "config_reload", map[string]interface{}{"changed": changed, "loggers": len(w.levels)})
+	}
+}
+
+// Returns the logger name of the key below `prefix`
+func levelName(key, prefix string) string {
+	name := strings.Trim(strings.TrimPrefix(key, prefix), "/")
+	name = strings.Replace(name, "/", ".", -1)
+	if name == RootLogger {
+		return ""
+	}
+	return name
+}
+
+// Consul is a LevelSource of the keys below Prefix in Consul's KV store, using
+// blocking queries so changes are seen as soon as they're made
+type Consul struct {
+	// Address of the Consul agent (defaults to `http://127.0.0.1:8500`)
+	Address string
+	Prefix  string
+	Token   string
+	// Wait is the longest a query blocks for (defaults to 5m)
+	Wait   time.Duration
+	Client *http.Client
+}
+
+// Levels returns the levels once they've changed, see LevelSource
+func (c Consul) Levels(ctx context.Context, index uint64) (map[string]jog.Level, uint64, error) {
+	if c.Address == "" {
+		c.Address = "http://127.0.0.1:8500"
+	}
+	if c.Wait <= 0 {
+		c.Wait = 5 * time.Minute
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	q := url.Values{"recurse": {"true"}, "wait": {c.Wait.String()}}
+	if index > 0 {
+		q.Set("index", strconv.FormatUint(index, 10))
+	}
+	req, err := http.NewRequest("GET", strings.TrimSuffix(c.Address, "/")+"/v1/kv/"+c.Prefix+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if c.Token != "" {
+		req.Header.Set("X-Consul-Token", c.Token)
+	}
+	resp, err := c.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	i, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	levels := map[string]jog.Level{}
+	if resp.StatusCode == http.StatusNotFound {
+		return levels, i, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, 0, fmt.Errorf("received a `%d` from Consul: %s", resp.StatusCode, b)
+	}
+	var kvs []struct {
+		Key   string
+		Value []byte
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&kvs); err != nil {
+		return nil, 0, err
+	}
+	for _, kv := range kvs {
+		if len(kv.Value) > 0 {
+			levels[levelName(kv.Key, c.Prefix)] = jog.ParseLevel(strings.TrimSpace(string(kv.Value)))
+		}
+	}
+	return levels, i, nil
+}
+
+// Etcd is a LevelSource of the keys below Prefix in etcd, read through its v3 JSON
+// gateway every Interval, the store's revision being the index
+type Etcd struct {
+	// Endpoint of an etcd member (defaults to `http://127.0.0.1:2379`)
+	Endpoint string
+	Prefix   string
+	// Interval between reads (defaults to 10s)
+	Interval time.Duration
+	Client   *http.Client
+}
+
+// Levels returns the levels once they've changed, see LevelSource
+func (e Etcd) Levels(ctx context.Context, index uint64) (map[string]jog.Level, uint64, error) {
+	if e.Interval <= 0 {
+		e.Interval = 10 * time.Second
+	}
+	for {
+		levels, rev, err := e.read(ctx)
+		if err != nil || rev != index {
+			return levels, rev, err
+		}
+		select {
+		case <-time.After(e.Interval):
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+}
+
+// Reads the keys below the prefix, returning their levels and the store's revision
+func (e Etcd) read(ctx context.Context) (map[string]jog.Level, uint64, error) {
+	if e.Endpoint == "" {
+		e.Endpoint = "http://127.0.0.1:2379"
+	}
+	if e.Client == nil {
+		e.Client = http.DefaultClient
+	}
+
+	// The range of keys with the prefix ends at the prefix with its last byte incremented
+	end := []byte(e.Prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			end = end[:i+1]
+			break
+		}
+	}
+	body, _ := json.Marshal(map[string]string{
+		"key":       base64.StdEncoding.EncodeToString([]byte(e.Prefix)),
+		"range_end": base64.StdEncoding.EncodeToString(end),
+	})
+	req, err := http.NewRequest("POST", strings.TrimSuffix(e.Endpoint, "/")+"/v3/kv/range", bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := e.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, 0, fmt.Errorf("received a `%d` from etcd: %s", resp.StatusCode, b)
+	}
+
+	// The gateway encodes 64 bit integers as strings, and bytes as base64
+	var r struct {
+		Header struct {
+			Revision uint64 `json:"revision,string"`
+		} `json:"header"`
+		Kvs []struct {
+			Key   []byte `json:"key"`
+			Value []byte `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, 0, err
+	}
+	levels := map[string]jog.Level{}
+	for _, kv := range r.Kvs {
+		if len(kv.Value) > 0 {
+			levels[levelName(string(kv.Key), e.Prefix)] = jog.ParseLevel(strings.TrimSpace(string(kv.Value)))
+		}
+	}
+	return levels, r.Header.Revision, nil
+}
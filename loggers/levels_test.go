@@ -0,0 +1,85 @@
+package loggers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+type levelSource chan map[string]jog.Level
+
+func (s levelSource) Levels(ctx context.Context, index uint64) (map[string]jog.Level, uint64, error) {
+	select {
+	case l := <-s:
+		return l, index + 1, nil
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	}
+}
+
+func TestWatchLevels(t *testing.T) {
+	j := jog.New(&testLogger{}, jog.WithLevel(jog.WARNING))
+	db := j.Named("watch").Named("db")
+	src := make(levelSource)
+	w := WatchLevels(j, src, nil)
+
+	src <- map[string]jog.Level{"watch.db": jog.DEBUG}
+	src <- map[string]jog.Level{"watch.db": jog.DEBUG} // applied once the first is
+	if !db.Enabled(jog.DEBUG) || j.Enabled(jog.INFO) {
+		t.Error("Expected DEBUG to be enabled for watch.db only")
+	}
+
+	src <- map[string]jog.Level{"": jog.INFO}
+	src <- map[string]jog.Level{"": jog.INFO}
+	if db.Enabled(jog.DEBUG) || !db.Enabled(jog.INFO) || !j.Enabled(jog.INFO) {
+		t.Error("Expected the removed logger to revert, and the root to be INFO")
+	}
+
+	w.Close()
+	if j.Enabled(jog.INFO) || len(w.Levels()) != 0 {
+		t.Error("Expected the levels to revert on Close")
+	}
+}
+
+func TestConsulLevels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/jog/api" || r.URL.Query().Get("index") != "7" {
+			t.Error("Unexpected request", r.URL)
+		}
+		w.Header().Set("X-Consul-Index", "8")
+		fmt.Fprintf(w, `[{"Key": "jog/api/", "Value": null}, {"Key": "jog/api/_", "Value": %q}, {"Key": "jog/api/db/pool", "Value": %q}]`,
+			base64.StdEncoding.EncodeToString([]byte("warn")), base64.StdEncoding.EncodeToString([]byte("debug\n")))
+	}))
+	defer srv.Close()
+
+	levels, i, err := Consul{Address: srv.URL, Prefix: "jog/api"}.Levels(context.Background(), 7)
+	if err != nil || i != 8 || len(levels) != 2 || levels[""] != jog.WARNING || levels["db.pool"] != jog.DEBUG {
+		t.Error("Expected the levels of the keys, got", levels, i, err)
+	}
+}
+
+func TestEtcdLevels(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		rev := "3"
+		if calls > 1 {
+			rev = "4"
+		}
+		fmt.Fprintf(w, `{"header": {"revision": %q}, "kvs": [{"key": %q, "value": %q}]}`, rev,
+			base64.StdEncoding.EncodeToString([]byte("jog/api/db")), base64.StdEncoding.EncodeToString([]byte("error")))
+	}))
+	defer srv.Close()
+
+	e := Etcd{Endpoint: srv.URL, Prefix: "jog/api/", Interval: time.Millisecond}
+	levels, rev, err := e.Levels(context.Background(), 3)
+	if err != nil || rev != 4 || calls != 2 || levels["db"] != jog.ERROR {
+		t.Error("Expected to wait for a new revision, got", levels, rev, calls, err)
+	}
+}
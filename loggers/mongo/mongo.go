@@ -0,0 +1,153 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mongo contains a jog.Logger that writes messages into a MongoDB collection,
+// typically a capped collection. It's driver agnostic, the application wraps its
+// driver's collection in a Collection, eg. with the official driver
+//
+//	type collection struct{ *mongo.Collection }
+//
+//	func (c collection) InsertMany(docs []interface{}) error {
+//		_, err := c.Collection.InsertMany(context.Background(), docs)
+//		return err
+//	}
+//
+//	func (c collection) CreateCapped(size, max int64) error {
+//		opts := options.CreateCollection().SetCapped(true).SetSizeInBytes(size).SetMaxDocuments(max)
+//		return c.Database().CreateCollection(context.Background(), c.Name(), opts)
+//	}
+package mongo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Collection is implemented by the application, wrapping its MongoDB driver
+type Collection interface {
+	InsertMany(docs []interface{}) error
+}
+
+// Creator is optionally implemented by a Collection to create itself as a
+// capped collection, see Config.Size
+type Creator interface {
+	CreateCapped(size, max int64) error
+}
+
+// ErrExists may be returned by a Creator when the collection already exists.
+// The official driver's NamespaceExists error (code 48) is recognized as is.
+var ErrExists = errors.New("jog: collection already exists")
+
+// NamespaceExists code of the MongoDB server
+const namespaceExists = 48
+
+// Reports whether a Creator's error is that the collection exists
+func exists(err error) bool {
+	var coded interface{ HasErrorCode(int) bool }
+	return errors.Is(err, ErrExists) || errors.As(err, &coded) && coded.HasErrorCode(namespaceExists)
+}
+
+// Document is the form messages are stored in.
+// Data and Meta are converted to their generic JSON form, so they map directly onto BSON.
+type Document struct {
+	Time     time.Time              `bson:"time" json:"time"`
+	Level    string                 `bson:"level" json:"level"`
+	File     string                 `bson:"file" json:"file"`
+	Line     int                    `bson:"line" json:"line"`
+	Data     interface{}            `bson:"data" json:"data"`
+	Meta     map[string]interface{} `bson:"meta,omitempty" json:"meta,omitempty"`
+	Tags     []string               `bson:"tags,omitempty" json:"tags,omitempty"`
+	ExpireAt *time.Time             `bson:"expireAt,omitempty" json:"expireAt,omitempty"`
+}
+
+// Config holds the settings for a Logger
+type Config struct {
+	// Size, in bytes, and Max documents of the capped collection.
+	// The collection is created when Size is set and it implements Creator, one
+	// that already exists, eg. after a restart, is left as is.
+	Size int64
+	Max  int64
+	// TTL sets an `expireAt` field on each document, for use with a TTL index
+	// (`{expireAt: 1}, {expireAfterSeconds: 0}`) on collections that aren't capped
+	TTL time.Duration
+	// BatchSize and Interval control how documents are batched (default 100, 1s)
+	BatchSize int
+	Interval  time.Duration
+}
+
+// Logger is a jog.Logger that inserts messages, in batches, into a collection
+type Logger struct {
+	coll  Collection
+	ttl   time.Duration
+	batch *jog.Batcher
+}
+
+// Log queues the message, inserting the batch once it's full
+func (l *Logger) Log(m interface{}) (int, error) {
+	return l.batch.Log(m)
+}
+
+// LogBatch inserts the messages now, as a whole batch, see jog.BatchLogger
+func (l *Logger) LogBatch(msgs []*jog.Message) (int, error) {
+	return 0, l.write(msgs)
+}
+
+// Flush inserts all pending messages
+func (l *Logger) Flush() error {
+	return l.batch.Flush()
+}
+
+// Close inserts any pending messages and stops the background flushing
+func (l *Logger) Close() error {
+	return l.batch.Close()
+}
+
+func (l *Logger) write(msgs []*jog.Message) error {
+	docs := make([]interface{}, len(msgs))
+	for i, m := range msgs {
+		docs[i] = l.document(m)
+	}
+	return l.coll.InsertMany(docs)
+}
+
+// Converts the message to a Document
+func (l *Logger) document(m *jog.Message) *Document {
+	d := &Document{
+		Time:  m.Time,
+		Level: string(m.Level),
+		File:  m.File,
+		Line:  m.Line,
+		Tags:  m.Tags,
+	}
+	// Decoded into an empty interface, as decoding into Data itself would fill
+	// a pointer it holds, eg. an error, rather than replace it
+	if b, err := m.DataJSON(); err != nil || json.Unmarshal(b, &d.Data) != nil {
+		d.Data = fmt.Sprint(m.Data)
+	}
+	if b, err := json.Marshal(m.Meta); err == nil {
+		json.Unmarshal(b, &d.Meta)
+	}
+	if l.ttl > 0 {
+		t := m.Time.Add(l.ttl)
+		d.ExpireAt = &t
+	}
+	return d
+}
+
+// New returns a new Logger inserting into `c`, creating it as a capped collection
+// when configured to
+func New(c Collection, cfg Config) (*Logger, error) {
+	if cr, ok := c.(Creator); ok && cfg.Size > 0 {
+		if err := cr.CreateCapped(cfg.Size, cfg.Max); err != nil && !exists(err) {
+			return nil, err
+		}
+	}
+	l := &Logger{coll: c, ttl: cfg.TTL}
+	l.batch = jog.NewBatcher(cfg.BatchSize, cfg.Interval, l.write)
+	return l, nil
+}
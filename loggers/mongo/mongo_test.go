@@ -0,0 +1,89 @@
+package mongo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// A server error, like the driver's mongo.CommandError
+type commandError struct{ code int }
+
+func (e commandError) Error() string              { return "(NamespaceExists) collection already exists" }
+func (e commandError) HasErrorCode(code int) bool { return e.code == code }
+
+// Records the documents inserted, and the capped collections created
+type testCollection struct {
+	docs    []interface{}
+	created int
+}
+
+func (c *testCollection) InsertMany(docs []interface{}) error {
+	c.docs = append(c.docs, docs...)
+	return nil
+}
+
+func (c *testCollection) CreateCapped(size, max int64) error {
+	if c.created++; c.created > 1 {
+		return commandError{namespaceExists}
+	}
+	return nil
+}
+
+func TestLogger(t *testing.T) {
+	c := &testCollection{}
+	l, err := New(c, Config{TTL: time.Hour, Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	m := &jog.Message{Data: map[string]interface{}{"n": 1}, Level: jog.ERROR, File: "main.go", Line: 12, Time: now}
+	m.SetMeta("host", "web1")
+	m.Tags = []string{"api"}
+	if _, err := l.LogBatch([]*jog.Message{m, {Data: errors.New("disk full"), Time: now}}); err != nil {
+		t.Fatal(err)
+	}
+	if c.created != 0 || len(c.docs) != 2 {
+		t.Fatal("Expected 2 documents in an existing collection, got", len(c.docs), c.created)
+	}
+
+	d := c.docs[0].(*Document)
+	if d.Time != now || d.Level != "error" || d.File != "main.go" || d.Line != 12 {
+		t.Errorf("Unexpected document %+v", d)
+	}
+	if data, _ := d.Data.(map[string]interface{}); data["n"] != float64(1) || d.Meta["host"] != "web1" || len(d.Tags) != 1 {
+		t.Errorf("Expected Data, Meta and Tags in their JSON form, got %+v", d)
+	}
+	if d.ExpireAt == nil || !d.ExpireAt.Equal(now.Add(time.Hour)) {
+		t.Error("Expected expireAt an hour on, got", d.ExpireAt)
+	}
+	if data, _ := c.docs[1].(*Document).Data.(map[string]interface{}); data["error"] != "disk full" {
+		t.Error("Expected the error's ErrorData, got", c.docs[1].(*Document).Data)
+	}
+}
+
+func TestLoggerCapped(t *testing.T) {
+	c := &testCollection{}
+	for i := 0; i < 2; i++ {
+		l, err := New(c, Config{Size: 1 << 20, Max: 1000, Interval: time.Hour})
+		if err != nil {
+			t.Fatal("Expected the capped collection to be created, or kept, on restart, got", err)
+		}
+		l.Close()
+	}
+	if c.created != 2 {
+		t.Error("Expected a creation per start, got", c.created)
+	}
+	if _, err := New(failingCreator{}, Config{Size: 1 << 20}); err == nil {
+		t.Error("Expected other errors to fail")
+	}
+}
+
+type failingCreator struct{}
+
+func (failingCreator) InsertMany(docs []interface{}) error { return nil }
+func (failingCreator) CreateCapped(size, max int64) error  { return errors.New("unauthorized") }
@@ -0,0 +1,311 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// MQTT control packet types
+const (
+	mqttConnect    = 0x10
+	mqttConnAck    = 0x20
+	mqttPublish    = 0x30
+	mqttPubAck     = 0x40
+	mqttPingReq    = 0xc0
+	mqttPingResp   = 0xd0
+	mqttDisconnect = 0xe0
+)
+
+// MQTTWill is the message the broker publishes when the logger disconnects unexpectedly
+type MQTTWill struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+}
+
+// MQTTConfig holds the settings for an MQTT logger
+type MQTTConfig struct {
+	// Addr of the broker, eg. `localhost:1883`
+	Addr string
+	// TLS, when set, is used to connect to the broker
+	TLS      *tls.Config
+	ClientID string
+	Username string
+	Password string
+	// Topic messages are published to, `{level}` is replaced with the message's level
+	Topic string
+	// QoS of published messages, 0 (at most once) or 1 (at least once)
+	QoS    byte
+	Retain bool
+	Will   *MQTTWill
+	// KeepAlive interval (defaults to 60s) and network Timeout (defaults to 10s)
+	KeepAlive time.Duration
+	Timeout   time.Duration
+}
+
+// MQTT is a jog.Logger that publishes messages to an MQTT 3.1.1 broker.
+// It connects on first use, and reconnects after a failure.
+type MQTT struct {
+	cfg    MQTTConfig
+	mu     sync.Mutex
+	conn   net.Conn
+	r      *bufio.Reader
+	id     uint16
+	last   time.Time
+	closed bool
+	stop   chan struct{}
+}
+
+// Log publishes the message, waiting for the broker's acknowledgement at QoS 1
+func (m *MQTT) Log(msg interface{}) (int, error) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return 0, err
+	}
+	topic := m.cfg.Topic
+	if jm, ok := msg.(*jog.Message); ok {
+		topic = strings.Replace(topic, "{level}", string(jm.Level), -1)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return 0, ErrClosed
+	}
+	if err := m.connect(); err != nil {
+		return 0, err
+	}
+	if err := m.publish(topic, b); err != nil {
+		m.reset()
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Close disconnects from the broker, without triggering the will message
+func (m *MQTT) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	close(m.stop)
+	if m.conn == nil {
+		return nil
+	}
+	m.conn.Write([]byte{mqttDisconnect, 0})
+	err := m.conn.Close()
+	m.conn = nil
+	return err
+}
+
+func (m *MQTT) reset() {
+	if m.conn != nil {
+		m.conn.Close()
+		m.conn = nil
+	}
+}
+
+// Connects to the broker, when not already connected
+func (m *MQTT) connect() error {
+	if m.conn != nil {
+		return nil
+	}
+	d := &net.Dialer{Timeout: m.cfg.Timeout}
+	var c net.Conn
+	var err error
+	if m.cfg.TLS != nil {
+		c, err = tls.DialWithDialer(d, "tcp", m.cfg.Addr, m.cfg.TLS)
+	} else {
+		c, err = d.Dial("tcp", m.cfg.Addr)
+	}
+	if err != nil {
+		return err
+	}
+	m.conn, m.r = c, bufio.NewReader(c)
+
+	if err := m.write(mqttConnect, m.connectPacket()); err != nil {
+		m.reset()
+		return err
+	}
+	t, p, err := m.read()
+	if err != nil {
+		m.reset()
+		return err
+	}
+	if t != mqttConnAck || len(p) != 2 || p[1] != 0 {
+		m.reset()
+		return fmt.Errorf("jog: MQTT connection refused (%v)", p)
+	}
+	return nil
+}
+
+// Variable header and payload of a CONNECT packet
+func (m *MQTT) connectPacket() []byte {
+	var flags byte = 0x02 // clean session
+	var payload []byte
+	payload = mqttString(payload, m.cfg.ClientID)
+	if w := m.cfg.Will; w != nil {
+		flags |= 0x04 | (w.QoS&0x03)<<3
+		if w.Retain {
+			flags |= 0x20
+		}
+		payload = mqttString(payload, w.Topic)
+		payload = mqttBytes(payload, w.Payload)
+	}
+	if m.cfg.Username != "" {
+		flags |= 0x80
+		payload = mqttString(payload, m.cfg.Username)
+	}
+	if m.cfg.Password != "" {
+		flags |= 0x40
+		payload = mqttString(payload, m.cfg.Password)
+	}
+
+	p := mqttString(nil, "MQTT")
+	p = append(p, 4, flags)
+	p = binary.BigEndian.AppendUint16(p, uint16(m.cfg.KeepAlive/time.Second))
+	return append(p, payload...)
+}
+
+func (m *MQTT) publish(topic string, b []byte) error {
+	flags := byte(mqttPublish) | (m.cfg.QoS&0x01)<<1
+	if m.cfg.Retain {
+		flags |= 0x01
+	}
+	p := mqttString(nil, topic)
+	if m.cfg.QoS > 0 {
+		m.id++
+		if m.id == 0 {
+			m.id = 1
+		}
+		p = binary.BigEndian.AppendUint16(p, m.id)
+	}
+	if err := m.write(flags, append(p, b...)); err != nil {
+		return err
+	}
+	if m.cfg.QoS == 0 {
+		return nil
+	}
+
+	for {
+		t, p, err := m.read()
+		if err != nil {
+			return err
+		}
+		if t == mqttPubAck && len(p) == 2 && binary.BigEndian.Uint16(p) == m.id {
+			return nil
+		}
+	}
+}
+
+// Keeps the connection alive while idle, so the will isn't triggered
+func (m *MQTT) ping() {
+	t := time.NewTicker(m.cfg.KeepAlive / 2)
+	defer t.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-t.C:
+		}
+		m.mu.Lock()
+		if m.conn != nil && time.Since(m.last) >= m.cfg.KeepAlive/2 {
+			if err := m.write(mqttPingReq, nil); err != nil {
+				m.reset()
+			} else if _, _, err := m.read(); err != nil {
+				m.reset()
+			}
+		}
+		m.mu.Unlock()
+	}
+}
+
+// Writes a packet of the given type and flags
+func (m *MQTT) write(header byte, p []byte) error {
+	b := []byte{header}
+	n := len(p)
+	for {
+		d := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			d |= 0x80
+		}
+		b = append(b, d)
+		if n == 0 {
+			break
+		}
+	}
+	m.conn.SetWriteDeadline(time.Now().Add(m.cfg.Timeout))
+	_, err := m.conn.Write(append(b, p...))
+	m.last = time.Now()
+	return err
+}
+
+// Reads a packet, returning its type and the rest of the packet
+func (m *MQTT) read() (byte, []byte, error) {
+	m.conn.SetReadDeadline(time.Now().Add(m.cfg.Timeout))
+	h, err := m.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	n, shift := 0, uint(0)
+	for {
+		d, err := m.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		n |= int(d&0x7f) << shift
+		if d&0x80 == 0 {
+			break
+		}
+		if shift += 7; shift > 21 {
+			return 0, nil, errors.New("jog: malformed MQTT packet length")
+		}
+	}
+	p := make([]byte, n)
+	_, err = io.ReadFull(m.r, p)
+	return h & 0xf0, p, err
+}
+
+func mqttString(b []byte, s string) []byte {
+	return mqttBytes(b, []byte(s))
+}
+
+func mqttBytes(b, s []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// NewMQTT returns a new MQTT logger
+func NewMQTT(c MQTTConfig) *MQTT {
+	if c.KeepAlive <= 0 {
+		c.KeepAlive = 60 * time.Second
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.QoS > 1 {
+		c.QoS = 1
+	}
+	m := &MQTT{cfg: c, stop: make(chan struct{})}
+	go m.ping()
+	return m
+}
@@ -0,0 +1,60 @@
+package loggers
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestMQTT(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	published := make(chan string, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		b := &MQTT{conn: c, r: bufio.NewReader(c), cfg: MQTTConfig{Timeout: 1e9}}
+
+		if typ, p, err := b.read(); err != nil || typ != mqttConnect || !strings.Contains(string(p), "gone") {
+			t.Error("Expected CONNECT with a will, got", typ, err)
+			return
+		}
+		b.write(mqttConnAck, []byte{0, 0})
+
+		typ, p, _ := b.read()
+		if typ != mqttPublish {
+			t.Error("Expected PUBLISH, got", typ)
+			return
+		}
+		n := int(p[0])<<8 | int(p[1])
+		topic, id := string(p[2:2+n]), p[2+n:4+n]
+		b.write(mqttPubAck, id)
+		published <- topic + " " + string(p[4+n:])
+	}()
+
+	m := NewMQTT(MQTTConfig{
+		Addr:     ln.Addr().String(),
+		ClientID: "device-1",
+		Topic:    "logs/{level}",
+		QoS:      1,
+		Will:     &MQTTWill{Topic: "status", Payload: []byte("gone")},
+	})
+	defer m.Close()
+
+	if _, err := m.Log(&jog.Message{Level: jog.ERROR, Data: "overheating"}); err != nil {
+		t.Fatal(err)
+	}
+	if p := <-published; !strings.HasPrefix(p, "logs/error {") || !strings.Contains(p, "overheating") {
+		t.Error("Unexpected publish", p)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"io"
+
+	"code.minty.io/jog"
+)
+
+type minLevel struct {
+	logger jog.Logger
+	level  jog.Level
+}
+
+func (l *minLevel) Log(m interface{}) (int, error) {
+	if msg, ok := m.(*jog.Message); ok && !msg.Level.AtLeast(l.level) {
+		return 0, nil
+	}
+	return l.logger.Log(m)
+}
+
+// MinLevel returns a jog.Logger passing only the messages at, or above, `level`
+// to `l`, so each destination of a Multi, or Router, may have its own verbosity.
+// It applies after the Jog's level, which should be the lowest of them.
+func MinLevel(l jog.Logger, level jog.Level) jog.Logger {
+	if level == "" {
+		return l
+	}
+	return &minLevel{l, level}
+}
+
+// Backend is a destination of a Multi
+type Backend struct {
+	Logger jog.Logger
+	// Level is the minimum level of the messages sent to it, all when empty
+	Level jog.Level
+}
+
+// Multi is a jog.Logger sending each message to all of its backends whose level
+// it's at, eg. a file at DEBUG and an HTTP collector at WARNING
+//
+//	loggers.NewMulti(
+//		loggers.Backend{Logger: file, Level: jog.DEBUG},
+//		loggers.Backend{Logger: loggers.NewFromConfig(), Level: jog.WARNING},
+//	)
+type Multi struct {
+	backends []jog.Logger
+}
+
+// Log sends the message to the backends, returning the first error after all were
+// tried. Each backend but the last is given a copy, as they may modify it.
+func (m *Multi) Log(v interface{}) (int, error) {
+	var n int
+	var err error
+	msg, isMsg := v.(*jog.Message)
+	for i, b := range m.backends {
+		if isMsg && i < len(m.backends)-1 {
+			v = msg.Clone()
+		} else if isMsg {
+			v = msg
+		}
+		written, e := b.Log(v)
+		n += written
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+	return n, err
+}
+
+// Close closes the backends, returning the first error
+func (m *Multi) Close() error {
+	var err error
+	for _, b := range m.backends {
+		if ml, ok := b.(*minLevel); ok {
+			b = ml.logger
+		}
+		if c, ok := b.(io.Closer); ok {
+			if e := c.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// NewMulti returns a new Multi sending to the backends
+func NewMulti(backends ...Backend) *Multi {
+	m := &Multi{backends: make([]jog.Logger, len(backends))}
+	for i, b := range backends {
+		m.backends[i] = MinLevel(b.Logger, b.Level)
+	}
+	return m
+}
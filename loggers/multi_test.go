@@ -0,0 +1,34 @@
+package loggers
+
+import (
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestMulti(t *testing.T) {
+	file, http := &testLogger{}, &testLogger{}
+	j := jog.New(NewMulti(Backend{Logger: file, Level: jog.DEBUG}, Backend{Logger: http, Level: jog.WARNING}), jog.WithLevel(jog.INFO))
+	j.Debug("skipped by the jog")
+	j.Info("file only")
+	j.Error("both")
+	if file.count() != 2 || http.count() != 1 {
+		t.Error("Expected each backend to filter by its level, got", file.count(), http.count())
+	}
+}
+
+type mutateLogger struct{}
+
+func (mutateLogger) Log(m interface{}) (int, error) {
+	m.(*jog.Message).Data.(map[string]interface{})["user"] = "redacted"
+	return 0, nil
+}
+
+func TestMultiCopies(t *testing.T) {
+	l := &testLogger{}
+	m := &jog.Message{Level: jog.INFO, Data: map[string]interface{}{"user": "jack"}}
+	NewMulti(Backend{Logger: mutateLogger{}}, Backend{Logger: l}).Log(m)
+	if d := l.messages[0].(*jog.Message).Data.(map[string]interface{}); d["user"] != "jack" {
+		t.Error("Expected each backend to get its own copy, got", d)
+	}
+}
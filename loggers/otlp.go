@@ -0,0 +1,270 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// OTLPConfig holds the settings for an OpenTelemetry (OTLP/HTTP) logger.
+// Records are sent using the protobuf JSON encoding, which every OTLP/HTTP
+// collector accepts, so no protobuf or gRPC dependencies are needed.
+type OTLPConfig struct {
+	// Endpoint of the collector's logs API (defaults to `http://localhost:4318/v1/logs`)
+	Endpoint string
+	// Service is reported as the `service.name` resource attribute
+	Service string
+	// Resource holds any other resource attributes, eg. `deployment.environment`
+	Resource map[string]string
+	// Headers added to each request, eg. for authentication
+	Headers map[string]string
+	Client  *http.Client
+	// BatchSize and Interval control how often records are exported (default 100, 1s)
+	BatchSize int
+	Interval  time.Duration
+}
+
+// OTLP is a jog.Logger that exports messages as OpenTelemetry LogRecords
+type OTLP struct {
+	cfg      OTLPConfig
+	resource []otlpKeyValue
+	batch    *jog.Batcher
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string        `json:"stringValue,omitempty"`
+	BoolValue   *bool          `json:"boolValue,omitempty"`
+	IntValue    *string        `json:"intValue,omitempty"`
+	DoubleValue *float64       `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArray     `json:"arrayValue,omitempty"`
+	KvlistValue *otlpKeyValues `json:"kvlistValue,omitempty"`
+}
+
+type otlpArray struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+type otlpKeyValues struct {
+	Values []otlpKeyValue `json:"values"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+// OpenTelemetry severity numbers of the jog levels
+var otlpSeverity = map[jog.Level]int{
+	jog.DEBUG:    5,
+	jog.INFO:     9,
+	jog.WARNING:  13,
+	jog.ERROR:    17,
+	jog.CRITICAL: 21,
+}
+
+// Log queues the message, exporting the batch once it's full
+func (o *OTLP) Log(m interface{}) (int, error) {
+	return o.batch.Log(m)
+}
+
+// LogBatch exports the messages now, as a whole batch, see jog.BatchLogger
+func (o *OTLP) LogBatch(msgs []*jog.Message) (int, error) {
+	return 0, o.write(msgs)
+}
+
+// Flush exports all pending messages
+func (o *OTLP) Flush() error {
+	return o.batch.Flush()
+}
+
+// Close exports any pending messages and stops the background flushing
+func (o *OTLP) Close() error {
+	return o.batch.Close()
+}
+
+func (o *OTLP) write(msgs []*jog.Message) error {
+	records := make([]otlpLogRecord, 0, len(msgs))
+	now := strconv.FormatInt(time.Now().UnixNano(), 10)
+	for _, m := range msgs {
+		r, err := otlpRecord(m)
+		if err != nil {
+			return err
+		}
+		r.ObservedTimeUnixNano = now
+		records = append(records, r)
+	}
+
+	body := map[string]interface{}{
+		"resourceLogs": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{"attributes": o.resource},
+			"scopeLogs": []interface{}{map[string]interface{}{
+				"scope":      map[string]string{"name": "code.minty.io/jog"},
+				"logRecords": records,
+			}},
+		}},
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", o.cfg.Endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range o.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := o.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received a `%d` exporting to `%s`: %s", resp.StatusCode, o.cfg.Endpoint, b)
+	}
+	return nil
+}
+
+// Converts a message into a LogRecord.
+// Meta fields become attributes, and a `trace_id` and `span_id`, in Meta or in Data,
+// are lifted into the record.
+func otlpRecord(m *jog.Message) (otlpLogRecord, error) {
+	r := otlpLogRecord{
+		TimeUnixNano:   strconv.FormatInt(m.Time.UnixNano(), 10),
+		SeverityNumber: otlpSeverity[m.Level],
+		SeverityText:   string(m.Level),
+		Attributes: []otlpKeyValue{
+			{"code.filepath", otlpValue(m.File)},
+			{"code.lineno", otlpValue(json.Number(strconv.Itoa(m.Line)))},
+		},
+	}
+
+	// Normalize Data into plain JSON values
+	b, err := m.DataJSON()
+	if err != nil {
+		return r, err
+	}
+	var data interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&data); err != nil {
+		return r, err
+	}
+	if fields, ok := data.(map[string]interface{}); ok {
+		r.TraceID, _ = fields["trace_id"].(string)
+		r.SpanID, _ = fields["span_id"].(string)
+	}
+	r.Body = otlpValue(data)
+
+	if len(m.Meta) > 0 || len(m.Tags) > 0 {
+		b, err := json.Marshal(m.Meta)
+		if err != nil {
+			return r, err
+		}
+		meta := map[string]interface{}{}
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		if err := d.Decode(&meta); err != nil {
+			return r, err
+		}
+		if id, ok := meta["trace_id"].(string); ok {
+			r.TraceID = id
+			r.SpanID, _ = meta["span_id"].(string)
+			delete(meta, "trace_id")
+			delete(meta, "span_id")
+		}
+		if len(m.Tags) > 0 {
+			tags := make([]interface{}, len(m.Tags))
+			for i, t := range m.Tags {
+				tags[i] = t
+			}
+			meta["tags"] = tags
+		}
+		r.Attributes = append(r.Attributes, otlpAttributes(meta)...)
+	}
+	return r, nil
+}
+
+// Converts a decoded JSON value into an AnyValue
+func otlpValue(v interface{}) otlpAnyValue {
+	var a otlpAnyValue
+	switch v := v.(type) {
+	case string:
+		a.StringValue = &v
+	case bool:
+		a.BoolValue = &v
+	case json.Number:
+		if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			s := string(v)
+			a.IntValue = &s
+		} else {
+			f, _ := v.Float64()
+			a.DoubleValue = &f
+		}
+	case []interface{}:
+		a.ArrayValue = &otlpArray{make([]otlpAnyValue, 0, len(v))}
+		for _, e := range v {
+			a.ArrayValue.Values = append(a.ArrayValue.Values, otlpValue(e))
+		}
+	case map[string]interface{}:
+		a.KvlistValue = &otlpKeyValues{otlpAttributes(v)}
+	}
+	return a
+}
+
+// Converts a map into key/values, sorted by key
+func otlpAttributes(m map[string]interface{}) []otlpKeyValue {
+	kvs := make([]otlpKeyValue, 0, len(m))
+	for k, v := range m {
+		kvs = append(kvs, otlpKeyValue{k, otlpValue(v)})
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return kvs
+}
+
+// NewOTLP returns a new OTLP logger
+func NewOTLP(c OTLPConfig) *OTLP {
+	if c.Endpoint == "" {
+		c.Endpoint = "http://localhost:4318/v1/logs"
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	res := map[string]interface{}{}
+	for k, v := range c.Resource {
+		res[k] = v
+	}
+	if c.Service != "" {
+		res["service.name"] = c.Service
+	}
+	o := &OTLP{cfg: c, resource: otlpAttributes(res)}
+	o.batch = jog.NewBatcher(c.BatchSize, c.Interval, o.write)
+	return o
+}
@@ -0,0 +1,60 @@
+package loggers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+func TestOTLP(t *testing.T) {
+	var body struct {
+		ResourceLogs []struct {
+			Resource struct {
+				Attributes []otlpKeyValue `json:"attributes"`
+			} `json:"resource"`
+			ScopeLogs []struct {
+				LogRecords []otlpLogRecord `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	o := NewOTLP(OTLPConfig{Endpoint: srv.URL, Service: "api", Headers: map[string]string{"Authorization": "Bearer x"}, Interval: time.Hour})
+	m := msg(map[string]interface{}{"user": "bob", "n": 2, "trace_id": "0af7651916cd43dd8448eb211c80319c"})
+	m.Level = jog.ERROR
+	m.SetMeta("env", "prod")
+	o.Log(m)
+	if err := o.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if auth != "Bearer x" {
+		t.Error("Expected headers to be sent, got", auth)
+	}
+	res := body.ResourceLogs[0].Resource.Attributes
+	if len(res) != 1 || res[0].Key != "service.name" || *res[0].Value.StringValue != "api" {
+		t.Error("Unexpected resource", res)
+	}
+	r := body.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	if r.SeverityNumber != 17 || r.TraceID != "0af7651916cd43dd8448eb211c80319c" {
+		t.Errorf("Unexpected record %+v", r)
+	}
+	if a := r.Attributes; len(a) != 3 || a[2].Key != "env" || *a[2].Value.StringValue != "prod" {
+		t.Errorf("Expected Meta as attributes, got %+v", a)
+	}
+	kv := r.Body.KvlistValue.Values
+	if len(kv) != 3 || kv[0].Key != "n" || *kv[0].Value.IntValue != "2" {
+		t.Errorf("Unexpected body %+v", kv)
+	}
+}
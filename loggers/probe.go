@@ -0,0 +1,140 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"code.minty.io/jog"
+)
+
+// Prober is implemented by Loggers that can check their backend is reachable,
+// without sending a message
+type Prober interface {
+	Probe(ctx context.Context) error
+}
+
+// Problems lists every configuration, or probe, error found
+type Problems []error
+
+func (p Problems) Error() string {
+	s := make([]string, len(p))
+	for i, e := range p {
+		s[i] = e.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+func (p Problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return p
+}
+
+// ConfigError is a misconfigured value of the `jog` group of `config.json`
+type ConfigError struct {
+	Key string
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("jog: config `jog.%s`: %v", e.Key, e.Err)
+}
+
+// ValidateConfig checks the `jog` values of `config.json`, used by NewFromConfig,
+// returning Problems listing everything that's wrong
+func ValidateConfig() error {
+	var p Problems
+	if name, _ := groupString("jog", "name"); name == "" {
+		p = append(p, &ConfigError{"name", errors.New("is required")})
+	}
+	if s, _ := groupString("jog", "url"); s == "" {
+		p = append(p, &ConfigError{"url", errors.New("is required")})
+	} else if u, err := url.Parse(s); err != nil {
+		p = append(p, &ConfigError{"url", err})
+	} else if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		p = append(p, &ConfigError{"url", fmt.Errorf("%q isn't an absolute http(s) URL", s)})
+	}
+	for _, k := range []string{"timeout", "requestTimeout", "maxIdleConnsPerHost", "idleConnTimeout"} {
+		if n, ok := groupInt("jog", k); ok && n < 0 {
+			p = append(p, &ConfigError{k, fmt.Errorf("%d is negative", n)})
+		}
+	}
+	t := tlsCfg()
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		p = append(p, &ConfigError{"certFile", errors.New("certFile and keyFile must be given together")})
+	} else if _, err := t.Load(); err != nil {
+		p = append(p, &ConfigError{"certFile", err})
+	}
+	return p.err()
+}
+
+// DryRun probes each of the loggers that is a Prober, eg. the basic and Socket
+// loggers, returning Problems listing those that failed
+func DryRun(ctx context.Context, ls ...jog.Logger) error {
+	var p Problems
+	for _, l := range ls {
+		if pr, ok := l.(Prober); ok {
+			if err := pr.Probe(ctx); err != nil {
+				p = append(p, err)
+			}
+		}
+	}
+	return p.err()
+}
+
+// NewFromConfigDryRun validates `config.json`, builds the basic logger, as
+// NewFromConfig does, and probes its endpoint, without sending a message
+func NewFromConfigDryRun(ctx context.Context) (jog.Logger, error) {
+	if err := ValidateConfig(); err != nil {
+		return nil, err
+	}
+	l := NewFromConfig()
+	return l, DryRun(ctx, l)
+}
+
+// Probe resolves the endpoint's host, then makes a HEAD request, which checks
+// the connection, the TLS handshake, and that the credentials are accepted
+func (l *basic) Probe(ctx context.Context) error {
+	u, err := url.Parse(l.url)
+	if err != nil {
+		return fmt.Errorf("jog: %s: invalid URL: %v", l.url, err)
+	}
+	if _, err := net.DefaultResolver.LookupHost(ctx, u.Hostname()); err != nil {
+		return fmt.Errorf("jog: %s: DNS lookup failed: %v", l.url, err)
+	}
+	req, err := http.NewRequest("HEAD", l.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := l.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("jog: %s: connecting failed: %v", l.url, err)
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusProxyAuthRequired:
+		return fmt.Errorf("jog: %s: authentication failed, received a `%d`", l.url, resp.StatusCode)
+	case http.StatusNotFound:
+		return fmt.Errorf("jog: %s: received a `404`, check the url and name", l.url)
+	}
+	return nil
+}
+
+// Probe connects, including the TLS handshake, and disconnects
+func (s *Socket) Probe(ctx context.Context) error {
+	c, err := s.dial()
+	if err != nil {
+		return fmt.Errorf("jog: %s %s: connecting failed: %v", s.network, s.addr, err)
+	}
+	return c.Close()
+}
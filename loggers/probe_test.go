@@ -0,0 +1,36 @@
+package loggers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	var posted bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "HEAD" {
+			posted = true
+		}
+		if r.URL.Path == "/denied" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	ok := New(srv.Client(), "app", srv.URL)
+	denied := New(srv.Client(), "denied", srv.URL)
+	down := NewUnix(filepath.Join(t.TempDir(), "missing.sock"))
+	err := DryRun(context.Background(), ok, denied, down)
+
+	p, _ := err.(Problems)
+	if len(p) != 2 || !strings.Contains(p[0].Error(), "authentication failed") || !strings.Contains(p[1].Error(), "connecting failed") {
+		t.Error("Unexpected problems", err)
+	}
+	if posted {
+		t.Error("Expected nothing to be sent")
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Limits of a single publish request
+const (
+	pubsubMaxMessages = 1000
+	pubsubMaxBytes    = 10 << 20
+	// Size of the request's wrapper around the messages
+	pubsubOverhead = len(`{"messages":[]}`)
+)
+
+// PubSubConfig holds the settings for a Google Pub/Sub logger
+type PubSubConfig struct {
+	Project string
+	Topic   string
+	// Endpoint of the Pub/Sub REST API (defaults to `https://pubsub.googleapis.com`)
+	Endpoint string
+	// Client must authorize its requests, eg. `google.DefaultClient(ctx, pubsub.ScopePubSub)`
+	Client *http.Client
+	// OrderingKey, when set, returns the ordering key of a message
+	OrderingKey func(*jog.Message) string
+	// Attributes, when set, returns extra attributes of a message.
+	// The `level` attribute is always set.
+	Attributes func(*jog.Message) map[string]string
+	// BatchSize and Interval control how often messages are published (default 100, 1s).
+	// A batch is capped at Pub/Sub's limit of 1000 messages, and split into requests
+	// within its limit of 10MB.
+	BatchSize int
+	Interval  time.Duration
+}
+
+// PubSub is a jog.Logger that publishes messages to a Google Pub/Sub topic
+type PubSub struct {
+	cfg   PubSubConfig
+	url   string
+	batch *jog.Batcher
+}
+
+type pubsubMessage struct {
+	Data        []byte            `json:"data"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	OrderingKey string            `json:"orderingKey,omitempty"`
+}
+
+// Log queues the message, publishing the batch once it's full
+func (p *PubSub) Log(m interface{}) (int, error) {
+	return p.batch.Log(m)
+}
+
+// LogBatch publishes the messages now, as a whole batch, see jog.BatchLogger
+func (p *PubSub) LogBatch(msgs []*jog.Message) (int, error) {
+	return 0, p.write(msgs)
+}
+
+// Flush publishes all pending messages
+func (p *PubSub) Flush() error {
+	return p.batch.Flush()
+}
+
+// Close publishes any pending messages and stops the background flushing
+func (p *PubSub) Close() error {
+	return p.batch.Close()
+}
+
+// Splits the messages into requests within the publish limits
+func (p *PubSub) write(msgs []*jog.Message) error {
+	var batch []json.RawMessage
+	size := pubsubOverhead
+	for _, m := range msgs {
+		b, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		attrs := map[string]string{}
+		if p.cfg.Attributes != nil {
+			for k, v := range p.cfg.Attributes(m) {
+				attrs[k] = v
+			}
+		}
+		attrs["level"] = string(m.Level)
+		pm := pubsubMessage{Data: b, Attributes: attrs}
+		if p.cfg.OrderingKey != nil {
+			pm.OrderingKey = p.cfg.OrderingKey(m)
+		}
+		if b, err = json.Marshal(pm); err != nil {
+			return err
+		}
+		if pubsubOverhead+len(b) > pubsubMaxBytes {
+			return fmt.Errorf("jog: message of %d bytes exceeds the Pub/Sub request limit", len(b))
+		}
+		if len(batch) == pubsubMaxMessages || size+len(b)+1 > pubsubMaxBytes {
+			if err := p.publish(batch); err != nil {
+				return err
+			}
+			batch, size = nil, pubsubOverhead
+		}
+		batch = append(batch, b)
+		size += len(b) + 1
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return p.publish(batch)
+}
+
+// Publishes the encoded messages in a single request
+func (p *PubSub) publish(msgs []json.RawMessage) error {
+	b, err := json.Marshal(struct {
+		Messages []json.RawMessage `json:"messages"`
+	}{msgs})
+	if err != nil {
+		return err
+	}
+	resp, err := p.cfg.Client.Post(p.url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received a `%d` publishing to `%s`: %s", resp.StatusCode, p.cfg.Topic, b)
+	}
+	return nil
+}
+
+// NewPubSub returns a new PubSub logger
+func NewPubSub(c PubSubConfig) *PubSub {
+	if c.Endpoint == "" {
+		c.Endpoint = "https://pubsub.googleapis.com"
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	} else if c.BatchSize > pubsubMaxMessages {
+		c.BatchSize = pubsubMaxMessages
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	p := &PubSub{
+		cfg: c,
+		url: fmt.Sprintf("%s/v1/projects/%s/topics/%s:publish", strings.TrimSuffix(c.Endpoint, "/"), c.Project, c.Topic),
+	}
+	p.batch = jog.NewBatcher(c.BatchSize, c.Interval, p.write)
+	return p
+}
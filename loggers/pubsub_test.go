@@ -0,0 +1,79 @@
+package loggers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+func TestPubSub(t *testing.T) {
+	var sizes, counts []int
+	var first pubsubMessage
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		var body struct{ Messages []pubsubMessage }
+		json.Unmarshal(b, &body)
+		if len(sizes) == 0 {
+			path, first = r.URL.Path, body.Messages[0]
+		}
+		sizes, counts = append(sizes, len(b)), append(counts, len(body.Messages))
+		w.Write([]byte(`{"messageIds": []}`))
+	}))
+	defer srv.Close()
+
+	p := NewPubSub(PubSubConfig{
+		Project:     "p",
+		Topic:       "logs",
+		Endpoint:    srv.URL,
+		Interval:    time.Hour,
+		OrderingKey: func(m *jog.Message) string { return "key" },
+	})
+	defer p.Close()
+
+	// Split by count
+	msgs := make([]*jog.Message, 2500)
+	for i := range msgs {
+		msgs[i] = msg("hello")
+	}
+	if _, err := p.LogBatch(msgs); err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 3 || counts[0] != 1000 || counts[1] != 1000 || counts[2] != 500 {
+		t.Error("Expected requests of 1000, 1000 and 500 messages, got", counts)
+	}
+	if path != "/v1/projects/p/topics/logs:publish" || first.Attributes["level"] != "info" || first.OrderingKey != "key" {
+		t.Errorf("Unexpected request %s %+v", path, first)
+	}
+
+	// Split by size
+	sizes, counts = nil, nil
+	msgs = make([]*jog.Message, 20)
+	for i := range msgs {
+		msgs[i] = msg(strings.Repeat("x", 1<<20))
+	}
+	if _, err := p.LogBatch(msgs); err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for i, s := range sizes {
+		if s > pubsubMaxBytes {
+			t.Error("Expected requests within 10MB, got", s)
+		}
+		n += counts[i]
+	}
+	if len(sizes) < 3 || n != 20 {
+		t.Error("Expected the messages to be split by size, got", counts)
+	}
+
+	// A single message over the limit
+	if _, err := p.LogBatch([]*jog.Message{msg(strings.Repeat("x", 8<<20))}); err == nil {
+		t.Error("Expected a message over the request limit to fail")
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"code.minty.io/jog"
+)
+
+// RedactMode decides what a sensitive value is replaced with
+type RedactMode int
+
+const (
+	// Mask replaces the value with `[REDACTED]`
+	Mask RedactMode = iota
+	// Tokenize replaces the value with a stable token, see Token, so lines about
+	// the same value can be correlated. The mapping is kept in a Vault.
+	Tokenize
+)
+
+// Masked is the replacement of values redacted with Mask
+const Masked = "[REDACTED]"
+
+// ErrUnknownToken is returned when a Vault has no value for a token
+var ErrUnknownToken = errors.New("jog: unknown token")
+
+// Vault stores the values behind tokens, so they can be revealed to those allowed
+type Vault interface {
+	Store(token, value string) error
+	Lookup(token string) (string, error)
+}
+
+// MemoryVault is a Vault held in memory, for testing and single process use
+type MemoryVault struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// Store keeps the value of the token
+func (v *MemoryVault) Store(token, value string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.values == nil {
+		v.values = make(map[string]string)
+	}
+	v.values[token] = value
+	return nil
+}
+
+// Lookup returns the value of the token
+func (v *MemoryVault) Lookup(token string) (string, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	s, ok := v.values[token]
+	if !ok {
+		return "", ErrUnknownToken
+	}
+	return s, nil
+}
+
+// RedactConfig holds the settings for a Redact logger
+type RedactConfig struct {
+	// Keys whose values are redacted, matched case-insensitively at any depth of
+	// the message's Data and Meta, eg. `email` or `password`
+	Keys []string
+	Mode RedactMode
+	// Secret keys the HMAC of Tokenize, tokens are only stable for the same secret
+	Secret []byte
+	// Vault, when set, stores the value of each token
+	Vault Vault
+}
+
+type redact struct {
+	logger jog.Logger
+	cfg    RedactConfig
+	keys   map[string]bool
+}
+
+// Token returns the token of a value, `tok_` followed by 16 hex characters of the
+// value's HMAC-SHA256 keyed by the secret
+func Token(secret []byte, value string) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(value))
+	return "tok_" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Log redacts the values of the configured keys before passing the message on
+func (r *redact) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return r.logger.Log(m)
+	}
+
+	var err error
+	switch v := msg.Data.(type) {
+	case nil, string:
+	case map[string]interface{}:
+		msg.Data, err = r.walk(v)
+	default:
+		var d interface{}
+		b, e := msg.DataJSON()
+		if e != nil || json.Unmarshal(b, &d) != nil {
+			break
+		}
+		msg.Data, err = r.walk(d)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if msg.Meta != nil {
+		meta, err := r.walk(msg.Meta)
+		if err != nil {
+			return 0, err
+		}
+		msg.Meta = meta.(map[string]interface{})
+	}
+	return r.logger.Log(msg)
+}
+
+// Returns a copy of the value with those of the sensitive keys replaced
+func (r *redact) walk(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			var err error
+			if r.keys[strings.ToLower(k)] {
+				out[k], err = r.replace(e)
+			} else {
+				out[k], err = r.walk(e)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			var err error
+			if out[i], err = r.walk(e); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+func (r *redact) replace(v interface{}) (interface{}, error) {
+	if r.cfg.Mode != Tokenize || v == nil {
+		return Masked, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		s = string(b)
+	}
+	t := Token(r.cfg.Secret, s)
+	if r.cfg.Vault != nil {
+		if err := r.cfg.Vault.Store(t, s); err != nil {
+			return nil, fmt.Errorf("jog: storing token: %v", err)
+		}
+	}
+	return t, nil
+}
+
+// Redact returns a jog.Logger that masks, or tokenizes, the values of sensitive
+// keys before passing messages to `l`
+func Redact(l jog.Logger, c RedactConfig) jog.Logger {
+	keys := make(map[string]bool, len(c.Keys))
+	for _, k := range c.Keys {
+		keys[strings.ToLower(k)] = true
+	}
+	return &redact{l, c, keys}
+}
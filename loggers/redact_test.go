@@ -0,0 +1,45 @@
+package loggers
+
+import (
+	"strings"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestRedactTokenize(t *testing.T) {
+	l := &testLogger{}
+	v := &MemoryVault{}
+	r := Redact(l, RedactConfig{Keys: []string{"Email", "password"}, Mode: Tokenize, Secret: []byte("s3cret"), Vault: v})
+
+	user := map[string]interface{}{"email": "jack@example.com", "name": "Jack"}
+	r.Log(msg(map[string]interface{}{"user": user, "action": "login"}))
+	r.Log(msg(map[string]interface{}{"email": "jack@example.com"}))
+
+	first := l.messages[0].(*jog.Message).Data.(map[string]interface{})["user"].(map[string]interface{})
+	second := l.messages[1].(*jog.Message).Data.(map[string]interface{})
+	tok, _ := first["email"].(string)
+	if !strings.HasPrefix(tok, "tok_") || second["email"] != tok || first["name"] != "Jack" {
+		t.Error("Expected a stable token, got", first, second)
+	}
+	if user["email"] != "jack@example.com" {
+		t.Error("Expected the caller's map to be left alone")
+	}
+	if s, err := v.Lookup(tok); err != nil || s != "jack@example.com" {
+		t.Error("Expected the vault to reveal the value, got", s, err)
+	}
+}
+
+func TestRedactMask(t *testing.T) {
+	l := &testLogger{}
+	r := Redact(l, RedactConfig{Keys: []string{"password"}})
+	r.Log(msg(struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}{"jack", "hunter2"}))
+
+	d := l.messages[0].(*jog.Message).Data.(map[string]interface{})
+	if d["password"] != Masked || d["user"] != "jack" {
+		t.Error("Unexpected redacted data", d)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Pruner is implemented by backends whose stored logs a Retention manager removes,
+// eg. FileRetention, S3 and the sql Logger
+type Pruner interface {
+	// Prune removes the entries older than `before`, and the oldest entries beyond
+	// a total size of `budget` bytes (when non-zero), returning how many were removed
+	Prune(before time.Time, budget int64) (int, error)
+}
+
+// RetentionConfig holds the settings for a Retention manager
+type RetentionConfig struct {
+	// MaxAge of kept entries, zero keeps entries of any age
+	MaxAge time.Duration
+	// MaxSize is the total size, in bytes, of the kept entries, zero is unlimited
+	MaxSize int64
+	// Interval at which entries are pruned (defaults to 1h)
+	Interval time.Duration
+	// Failed, when set, is called with the errors of pruning in the background
+	Failed func(error)
+}
+
+// Retention prunes old logs from its Pruners on an interval
+type Retention struct {
+	cfg     RetentionConfig
+	pruners []Pruner
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Run prunes all entries outside of the policy now, returning how many were removed
+// and the first error
+func (r *Retention) Run() (int, error) {
+	var before time.Time
+	if r.cfg.MaxAge > 0 {
+		before = time.Now().Add(-r.cfg.MaxAge)
+	}
+	var n int
+	var err error
+	for _, p := range r.pruners {
+		removed, e := p.Prune(before, r.cfg.MaxSize)
+		n += removed
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+	return n, err
+}
+
+// Close stops the background pruning
+func (r *Retention) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	<-r.done
+	return nil
+}
+
+func (r *Retention) run() {
+	defer close(r.done)
+	t := time.NewTicker(r.cfg.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if _, err := r.Run(); err != nil && r.cfg.Failed != nil {
+				r.cfg.Failed(err)
+			}
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// NewRetention returns a new Retention manager, pruning the Pruners every Interval
+func NewRetention(c RetentionConfig, ps ...Pruner) *Retention {
+	if c.Interval <= 0 {
+		c.Interval = time.Hour
+	}
+	r := &Retention{cfg: c, pruners: ps, stop: make(chan struct{}), done: make(chan struct{})}
+	go r.run()
+	return r
+}
+
+// A stored log file or object
+type retained struct {
+	name string
+	time time.Time
+	size int64
+}
+
+// Returns the entries outside of the policy, oldest first. The newest entry, which
+// may still be written to, is always kept.
+func expired(entries []retained, before time.Time, budget int64) []retained {
+	if len(entries) < 2 {
+		return nil
+	}
+	sort.Slice(entries, func(i, k int) bool { return entries[i].time.Before(entries[k].time) })
+	var total int64
+	for _, e := range entries {
+		total += e.size
+	}
+	var out []retained
+	for _, e := range entries[:len(entries)-1] {
+		if e.time.Before(before) || (budget > 0 && total > budget) {
+			out = append(out, e)
+			total -= e.size
+		}
+	}
+	return out
+}
+
+// FileRetention is a Pruner of log files, eg. those of a File logger with a
+// time-partitioned Path
+type FileRetention struct {
+	// Glob matching the log files, eg. `/var/log/app-*.log`
+	Glob string
+	// ArchiveDir, when set, is where removed files are moved to, rather than deleted
+	ArchiveDir string
+}
+
+// Prune deletes, or archives, the files outside of the policy, see Pruner
+func (f FileRetention) Prune(before time.Time, budget int64) (int, error) {
+	paths, err := filepath.Glob(f.Glob)
+	if err != nil {
+		return 0, err
+	}
+	var entries []retained
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		entries = append(entries, retained{p, fi.ModTime(), fi.Size()})
+	}
+	var n int
+	for _, e := range expired(entries, before, budget) {
+		if f.ArchiveDir != "" {
+			err = os.Rename(e.name, filepath.Join(f.ArchiveDir, filepath.Base(e.name)))
+		} else {
+			err = os.Remove(e.name)
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,64 @@
+package loggers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileRetention(t *testing.T) {
+	dir, archive := t.TempDir(), t.TempDir()
+	now := time.Now()
+	for i, age := range []time.Duration{72 * time.Hour, 48 * time.Hour, 24 * time.Hour, 0} {
+		p := filepath.Join(dir, fmt.Sprintf("app-%d.log", i))
+		os.WriteFile(p, make([]byte, 100), 0600)
+		os.Chtimes(p, now.Add(-age), now.Add(-age))
+	}
+
+	r := NewRetention(RetentionConfig{MaxAge: 60 * time.Hour, MaxSize: 250}, FileRetention{Glob: filepath.Join(dir, "*.log"), ArchiveDir: archive})
+	defer r.Close()
+	n, err := r.Run()
+	if err != nil || n != 2 {
+		t.Fatal("Expected 2 files to be removed, got", n, err)
+	}
+	for i, kept := range []bool{false, false, true, true} {
+		name := fmt.Sprintf("app-%d.log", i)
+		if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != kept {
+			t.Errorf("Expected %s kept to be %t", name, kept)
+		}
+		if _, err := os.Stat(filepath.Join(archive, name)); (err == nil) == kept {
+			t.Errorf("Expected %s archived to be %t", name, !kept)
+		}
+	}
+}
+
+func TestS3Prune(t *testing.T) {
+	var deleted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.URL.Query().Get("prefix") != "logs/" {
+			t.Error("Unexpected prefix", r.URL.Query())
+		}
+		fmt.Fprint(w, `<ListBucketResult>
+	<Contents><Key>logs/2014/03/05/a.json</Key><LastModified>2014-03-05T10:00:00.000Z</LastModified><Size>10</Size></Contents>
+	<Contents><Key>logs/2014/03/06/b.json</Key><LastModified>2014-03-06T10:00:00.000Z</LastModified><Size>10</Size></Contents>
+	<IsTruncated>false</IsTruncated>
+</ListBucketResult>`)
+	}))
+	defer srv.Close()
+
+	s := NewS3(S3Config{Endpoint: srv.URL, Bucket: "archive", Region: "us-east-1", Interval: time.Hour})
+	defer s.Close()
+	n, err := s.Prune(time.Date(2014, 3, 6, 0, 0, 0, 0, time.UTC), 0)
+	if err != nil || n != 1 || len(deleted) != 1 || deleted[0] != "/archive/logs/2014/03/05/a.json" {
+		t.Error("Expected the old object to be deleted, got", n, err, deleted)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"code.minty.io/jog"
+)
+
+// RingBuffer is a jog.Logger that keeps the most recent messages in memory.
+// It implements http.Handler, serving the recent messages as JSON.
+type RingBuffer struct {
+	mu   sync.RWMutex
+	msgs []*jog.Message
+	next int
+	full bool
+}
+
+// Log stores the message, replacing the oldest when the buffer is full
+func (r *RingBuffer) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return 0, nil
+	}
+	r.mu.Lock()
+	r.msgs[r.next] = msg
+	r.next = (r.next + 1) % len(r.msgs)
+	if r.next == 0 {
+		r.full = true
+	}
+	r.mu.Unlock()
+	return 0, nil
+}
+
+// Dump returns the buffered messages, oldest first
+func (r *RingBuffer) Dump() []*jog.Message {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.full {
+		return append([]*jog.Message(nil), r.msgs[:r.next]...)
+	}
+	d := make([]*jog.Message, 0, len(r.msgs))
+	d = append(d, r.msgs[r.next:]...)
+	return append(d, r.msgs[:r.next]...)
+}
+
+// ServeHTTP writes the buffered messages as a JSON array
+func (r *RingBuffer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	msgs := r.Dump()
+	if msgs == nil {
+		msgs = []*jog.Message{}
+	}
+	json.NewEncoder(w).Encode(msgs)
+}
+
+type tee struct {
+	ring   *RingBuffer
+	logger jog.Logger
+}
+
+func (t *tee) Log(m interface{}) (int, error) {
+	if msg, ok := m.(*jog.Message); ok {
+		t.ring.Log(msg.Clone())
+	}
+	return t.logger.Log(m)
+}
+
+// Tee returns a jog.Logger that records each message in the buffer before
+// passing it to `l`, so recent messages are available even when `l` is failing.
+func (r *RingBuffer) Tee(l jog.Logger) jog.Logger {
+	return &tee{r, l}
+}
+
+// Ring returns a new RingBuffer holding the last `n` messages
+func Ring(n int) *RingBuffer {
+	if n < 1 {
+		n = 1
+	}
+	return &RingBuffer{msgs: make([]*jog.Message, n)}
+}
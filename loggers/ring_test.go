@@ -0,0 +1,28 @@
+package loggers
+
+import (
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestRing(t *testing.T) {
+	r := Ring(3)
+	if d := r.Dump(); len(d) != 0 {
+		t.Error("Expected empty dump, got", len(d))
+	}
+
+	l := &testLogger{}
+	tee := r.Tee(l)
+	for i := 0; i < 5; i++ {
+		tee.Log(msg(i))
+	}
+	d := r.Dump()
+	if len(d) != 3 || d[0].Data != 2 || d[2].Data != 4 {
+		t.Errorf("Expected the last 3 messages, got %v", d)
+	}
+	if n := l.count(); n != 5 {
+		t.Error("Expected all messages passed through, got", n)
+	}
+	var _ jog.Logger = r
+}
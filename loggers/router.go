@@ -0,0 +1,82 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"sync"
+
+	"code.minty.io/jog"
+)
+
+// Router is a jog.Logger that sends each message to a Logger chosen by one of its
+// labels, eg. jog.ServiceKey, so several services or tenants can share a pipeline
+// while being shipped to their own URL, index, or stream
+type Router struct {
+	key     string
+	def     jog.Logger
+	route   func(label string) jog.Logger
+	mu      sync.RWMutex
+	loggers map[string]jog.Logger
+}
+
+// Log sends the message to the Logger of its label
+func (r *Router) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return r.def.Log(m)
+	}
+	return r.logger(msg.Label(r.key)).Log(m)
+}
+
+// Returns the Logger of the label, creating it on first use
+func (r *Router) logger(label string) jog.Logger {
+	if label == "" {
+		return r.def
+	}
+	r.mu.RLock()
+	l, ok := r.loggers[label]
+	r.mu.RUnlock()
+	if ok {
+		return l
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if l, ok = r.loggers[label]; !ok {
+		if l = r.route(label); l == nil {
+			l = r.def
+		}
+		r.loggers[label] = l
+	}
+	return l
+}
+
+// Close closes the Loggers created for each label
+func (r *Router) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var err error
+	for label, l := range r.loggers {
+		if c, ok := l.(interface{ Close() error }); ok && l != r.def {
+			if e := c.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+		delete(r.loggers, label)
+	}
+	return err
+}
+
+// NewRouter returns a new Router by the label `key`. The Logger of each label is
+// created by `route` when first seen, eg. a basic logger posting to a path per service
+//
+//	loggers.NewRouter(jog.ServiceKey, def, func(s string) jog.Logger {
+//		return loggers.New(client, s, url)
+//	})
+//
+// Messages without the label, or whose route returns nil, are sent to `def`.
+func NewRouter(key string, def jog.Logger, route func(label string) jog.Logger) *Router {
+	return &Router{key: key, def: def, route: route, loggers: map[string]jog.Logger{}}
+}
@@ -0,0 +1,26 @@
+package loggers
+
+import (
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestRouter(t *testing.T) {
+	def := &testLogger{}
+	routes := map[string]*testLogger{}
+	r := NewRouter(jog.ServiceKey, def, func(s string) jog.Logger {
+		routes[s] = &testLogger{}
+		return routes[s]
+	})
+
+	j := jog.New(r)
+	j.Service("billing").Info("one")
+	j.Service("billing").Info("two")
+	j.Service("search").Info("three")
+	j.Info("four")
+
+	if len(routes) != 2 || routes["billing"].count() != 2 || routes["search"].count() != 1 || def.count() != 1 {
+		t.Error("Unexpected routing", routes, def.count())
+	}
+}
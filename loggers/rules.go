@@ -0,0 +1,579 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode"
+
+	"code.minty.io/jog"
+)
+
+// Rules filter and change messages, and are parsed from text so operators can
+// change them without rebuilding the application. Each line holds a rule, `#`
+// starts a comment:
+//
+//	drop when level == "debug" && data.path == "/healthz"
+//	set data.env = "prod"
+//	set meta.team = "payments" when data.path =~ "^/pay/"
+//	delete data.password
+//
+// Rules are applied in order, and a dropped message isn't passed to later rules.
+// Expressions compare (`==`, `!=`, `<`, `<=`, `>`, `>=`, and `=~` with a regular
+// expression) and combine (`&&`, `||`, `!` and parentheses) strings, numbers,
+// `true`, `false`, `null` and the fields of the message: `level`, `file`, `line`,
+// `func`, `id`, and those of `data` and `meta`, eg. `data.user.id`. Fields that
+// don't exist are null.
+type Rules struct {
+	rules []rule
+}
+
+type rule struct {
+	action string
+	path   []string
+	value  ruleExpr
+	when   ruleExpr
+}
+
+// ParseRules parses the rules of `src`, see Rules
+func ParseRules(src string) (*Rules, error) {
+	r := &Rules{}
+	for i, line := range strings.Split(src, "\n") {
+		toks, err := ruleTokens(line)
+		if err != nil {
+			return nil, fmt.Errorf("jog: rule %d: %v", i+1, err)
+		}
+		if len(toks) == 0 {
+			continue
+		}
+		p := &ruleParser{toks: toks}
+		ru, err := p.rule()
+		if err != nil {
+			return nil, fmt.Errorf("jog: rule %d: %v", i+1, err)
+		}
+		r.rules = append(r.rules, ru)
+	}
+	return r, nil
+}
+
+// LoadRules parses the rules of a file, see Rules
+func LoadRules(path string) (*Rules, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRules(string(b))
+}
+
+// RulesFromConfig loads the rules of the file named by `jog.rules` in `config.json`,
+// returning nil when none is set
+func RulesFromConfig() (*Rules, error) {
+	path, ok := groupString("jog", "rules")
+	if !ok || path == "" {
+		return nil, nil
+	}
+	return LoadRules(path)
+}
+
+// Apply applies the rules to the message, reporting whether it's kept
+func (r *Rules) Apply(m *jog.Message) bool {
+	e := &ruleEnv{msg: m}
+	for _, ru := range r.rules {
+		if ru.when != nil && !truthy(ru.when.eval(e)) {
+			continue
+		}
+		switch ru.action {
+		case "drop":
+			return false
+		case "set":
+			e.set(ru.path, ru.value.eval(e))
+		case "delete":
+			e.remove(ru.path)
+		}
+	}
+	return true
+}
+
+// RuleLogger is a jog.Logger applying Rules to messages before passing them on
+type RuleLogger struct {
+	logger jog.Logger
+	rules  atomic.Value
+}
+
+// Log applies the rules, logging the message unless it's dropped
+func (l *RuleLogger) Log(m interface{}) (int, error) {
+	if msg, ok := m.(*jog.Message); ok {
+		if r, _ := l.rules.Load().(*Rules); r != nil && !r.Apply(msg) {
+			return 0, nil
+		}
+	}
+	return l.logger.Log(m)
+}
+
+// SetRules replaces the rules, eg. after the rules file was edited
+func (l *RuleLogger) SetRules(r *Rules) {
+	if r == nil {
+		r = &Rules{}
+	}
+	l.rules.Store(r)
+}
+
+// NewRuleLogger returns a new RuleLogger applying `r` to the messages logged to `l`
+func NewRuleLogger(l jog.Logger, r *Rules) *RuleLogger {
+	rl := &RuleLogger{logger: l}
+	rl.SetRules(r)
+	return rl
+}
+
+// The message being evaluated, its Data decoded on first use
+type ruleEnv struct {
+	msg     *jog.Message
+	data    interface{}
+	decoded bool
+}
+
+func (e *ruleEnv) field(path []string) interface{} {
+	m := e.msg
+	var v interface{}
+	switch path[0] {
+	case "level":
+		v = string(m.Level)
+	case "file":
+		v = m.File
+	case "line":
+		v = float64(m.Line)
+	case "func":
+		v = m.Func
+	case "id":
+		v = m.ID
+	case "meta":
+		if m.Meta == nil {
+			return nil
+		}
+		v = map[string]interface{}(m.Meta)
+	case "data":
+		if !e.decoded {
+			e.decoded = true
+			if b, err := m.DataJSON(); err == nil {
+				d := json.NewDecoder(bytes.NewReader(b))
+				d.UseNumber()
+				d.Decode(&e.data)
+			}
+		}
+		v = e.data
+	}
+	for _, k := range path[1:] {
+		o, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = o[k]
+	}
+	return ruleNumber(v)
+}
+
+// Sets a field of Data or Meta
+func (e *ruleEnv) set(path []string, v interface{}) {
+	e.decoded = false
+	if path[0] == "meta" {
+		e.msg.SetMeta(path[1], v)
+		return
+	}
+	for i := len(path) - 1; i > 1; i-- {
+		v = map[string]interface{}{path[i]: v}
+	}
+	e.msg.Set(path[1], v)
+}
+
+// Removes a field of Data or Meta, copying the maps along its path
+func (e *ruleEnv) remove(path []string) {
+	e.decoded = false
+	if path[0] == "meta" {
+		delete(e.msg.Meta, path[1])
+		return
+	}
+	d, ok := dataMap(e.msg)
+	if !ok {
+		return
+	}
+	o := d
+	for _, k := range path[1 : len(path)-1] {
+		next, ok := o[k].(map[string]interface{})
+		if !ok {
+			return
+		}
+		c := make(map[string]interface{}, len(next))
+		for nk, nv := range next {
+			c[nk] = nv
+		}
+		o[k] = c
+		o = c
+	}
+	delete(o, path[len(path)-1])
+	e.msg.Data = d
+}
+
+// Converts numbers to float64, so they compare with literals
+func ruleNumber(v interface{}) interface{} {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			return n.String()
+		}
+		return f
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	}
+	return v
+}
+
+func truthy(v interface{}) bool {
+	switch b := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return b
+	case string:
+		return b != ""
+	case float64:
+		return b != 0
+	}
+	return true
+}
+
+type ruleExpr interface {
+	eval(e *ruleEnv) interface{}
+}
+
+type ruleLiteral struct{ v interface{} }
+
+func (l ruleLiteral) eval(*ruleEnv) interface{} { return l.v }
+
+type ruleField struct{ path []string }
+
+func (f ruleField) eval(e *ruleEnv) interface{} { return e.field(f.path) }
+
+type ruleNot struct{ x ruleExpr }
+
+func (n ruleNot) eval(e *ruleEnv) interface{} { return !truthy(n.x.eval(e)) }
+
+type ruleLogical struct {
+	op   string
+	l, r ruleExpr
+}
+
+func (l ruleLogical) eval(e *ruleEnv) interface{} {
+	if l.op == "&&" {
+		return truthy(l.l.eval(e)) && truthy(l.r.eval(e))
+	}
+	return truthy(l.l.eval(e)) || truthy(l.r.eval(e))
+}
+
+type ruleCompare struct {
+	op   string
+	l, r ruleExpr
+}
+
+func (c ruleCompare) eval(e *ruleEnv) interface{} {
+	l, r := c.l.eval(e), c.r.eval(e)
+	switch c.op {
+	case "==":
+		return ruleEqual(l, r)
+	case "!=":
+		return !ruleEqual(l, r)
+	}
+	var cmp int
+	switch lv := l.(type) {
+	case float64:
+		rv, ok := r.(float64)
+		if !ok {
+			return false
+		}
+		if lv < rv {
+			cmp = -1
+		} else if lv > rv {
+			cmp = 1
+		}
+	case string:
+		rv, ok := r.(string)
+		if !ok {
+			return false
+		}
+		cmp = strings.Compare(lv, rv)
+	default:
+		return false
+	}
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	}
+	return cmp >= 0
+}
+
+func ruleEqual(l, r interface{}) bool {
+	switch l.(type) {
+	case nil, bool, string, float64:
+		return l == r
+	}
+	return false
+}
+
+type ruleMatch struct {
+	x  ruleExpr
+	re *regexp.Regexp
+}
+
+func (m ruleMatch) eval(e *ruleEnv) interface{} {
+	s, ok := m.x.eval(e).(string)
+	return ok && m.re.MatchString(s)
+}
+
+type ruleToken struct {
+	kind byte // 'i'dent, 's'tring, 'n'umber or 'o'perator
+	text string
+}
+
+// Splits a line into tokens, stopping at a comment
+func ruleTokens(line string) ([]ruleToken, error) {
+	var toks []ruleToken
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == '#':
+			return toks, nil
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(line) && line[j] != '"'; j++ {
+				if line[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(line) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			s, err := strconv.Unquote(line[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", line[i:j+1])
+			}
+			toks = append(toks, ruleToken{'s', s})
+			i = j + 1
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(line) && (line[j] == '.' || line[j] >= '0' && line[j] <= '9') {
+				j++
+			}
+			toks = append(toks, ruleToken{'n', line[i:j]})
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i + 1
+			for j < len(line) && (line[j] == '_' || line[j] == '.' || unicode.IsLetter(rune(line[j])) || unicode.IsDigit(rune(line[j]))) {
+				j++
+			}
+			toks = append(toks, ruleToken{'i', line[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, o := range []string{"==", "!=", "<=", ">=", "=~", "&&", "||", "<", ">", "!", "(", ")", "="} {
+				if strings.HasPrefix(line[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q", c)
+			}
+			toks = append(toks, ruleToken{'o', op})
+			i += len(op)
+		}
+	}
+	return toks, nil
+}
+
+type ruleParser struct {
+	toks []ruleToken
+	pos  int
+}
+
+func (p *ruleParser) peek() ruleToken {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return ruleToken{}
+}
+
+func (p *ruleParser) next() ruleToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *ruleParser) rule() (rule, error) {
+	var r rule
+	var err error
+	switch r.action = p.next().text; r.action {
+	case "drop":
+	case "set", "delete":
+		if r.path, err = p.target(); err != nil {
+			return r, err
+		}
+		if r.action == "set" {
+			if t := p.next(); t.text != "=" || t.kind != 'o' {
+				return r, fmt.Errorf("expected `=` after `set %s`", strings.Join(r.path, "."))
+			}
+			if r.value, err = p.or(); err != nil {
+				return r, err
+			}
+		}
+	default:
+		return r, fmt.Errorf("unknown action %q", r.action)
+	}
+	if t := p.peek(); t.kind == 'i' && t.text == "when" {
+		p.next()
+		if r.when, err = p.or(); err != nil {
+			return r, err
+		}
+	}
+	if p.pos < len(p.toks) {
+		return r, fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	return r, nil
+}
+
+// A field of Data or Meta that can be set
+func (p *ruleParser) target() ([]string, error) {
+	t := p.next()
+	path := strings.Split(t.text, ".")
+	if t.kind != 'i' || len(path) < 2 || (path[0] != "data" && path[0] != "meta") || (path[0] == "meta" && len(path) != 2) {
+		return nil, fmt.Errorf("expected a field of data or meta, got %q", t.text)
+	}
+	return path, nil
+}
+
+func (p *ruleParser) or() (ruleExpr, error) {
+	l, err := p.and()
+	for err == nil && p.peek().text == "||" {
+		p.next()
+		var r ruleExpr
+		if r, err = p.and(); err == nil {
+			l = ruleLogical{"||", l, r}
+		}
+	}
+	return l, err
+}
+
+func (p *ruleParser) and() (ruleExpr, error) {
+	l, err := p.unary()
+	for err == nil && p.peek().text == "&&" {
+		p.next()
+		var r ruleExpr
+		if r, err = p.unary(); err == nil {
+			l = ruleLogical{"&&", l, r}
+		}
+	}
+	return l, err
+}
+
+func (p *ruleParser) unary() (ruleExpr, error) {
+	if t := p.peek(); t.kind == 'o' && t.text == "!" {
+		p.next()
+		x, err := p.unary()
+		return ruleNot{x}, err
+	}
+	return p.comparison()
+}
+
+func (p *ruleParser) comparison() (ruleExpr, error) {
+	l, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if t.kind != 'o' {
+		return l, nil
+	}
+	switch t.text {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+		r, err := p.operand()
+		return ruleCompare{t.text, l, r}, err
+	case "=~":
+		p.next()
+		s := p.next()
+		if s.kind != 's' {
+			return nil, fmt.Errorf("expected a regular expression string after `=~`")
+		}
+		re, err := regexp.Compile(s.text)
+		if err != nil {
+			return nil, err
+		}
+		return ruleMatch{l, re}, nil
+	}
+	return l, nil
+}
+
+func (p *ruleParser) operand() (ruleExpr, error) {
+	t := p.next()
+	switch t.kind {
+	case 's':
+		return ruleLiteral{t.text}, nil
+	case 'n':
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.text)
+		}
+		return ruleLiteral{f}, nil
+	case 'i':
+		switch t.text {
+		case "true":
+			return ruleLiteral{true}, nil
+		case "false":
+			return ruleLiteral{false}, nil
+		case "null":
+			return ruleLiteral{nil}, nil
+		}
+		path := strings.Split(t.text, ".")
+		switch path[0] {
+		case "level", "file", "line", "func", "id":
+			if len(path) == 1 {
+				return ruleField{path}, nil
+			}
+		case "data", "meta":
+			return ruleField{path}, nil
+		}
+		return nil, fmt.Errorf("unknown field %q", t.text)
+	case 'o':
+		if t.text == "(" {
+			x, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			if p.next().text != ")" {
+				return nil, fmt.Errorf("expected `)`")
+			}
+			return x, nil
+		}
+	case 0:
+		return nil, fmt.Errorf("unexpected end of rule")
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
@@ -0,0 +1,62 @@
+package loggers
+
+import (
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestRules(t *testing.T) {
+	r, err := ParseRules(`
+# health checks are noise
+drop when level == "debug" && data.path == "/healthz"
+set data.env = "prod"
+set meta.team = "payments" when data.path =~ "^/pay/" || data.status >= 500
+set data.http.slow = true when !(data.ms < 100)
+delete data.password
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := &testLogger{}
+	rl := NewRuleLogger(l, r)
+	health := &jog.Message{Level: jog.DEBUG, Data: map[string]interface{}{"path": "/healthz"}}
+	rl.Log(health)
+	if l.count() != 0 {
+		t.Fatal("Expected the health check to be dropped")
+	}
+
+	m := &jog.Message{Level: jog.INFO, Data: map[string]interface{}{"path": "/pay/1", "ms": 250, "password": "x"}}
+	rl.Log(m)
+	d := m.Data.(map[string]interface{})
+	if d["env"] != "prod" || m.Meta["team"] != "payments" || d["http"].(map[string]interface{})["slow"] != true {
+		t.Error("Expected the fields to be set, got", d, m.Meta)
+	}
+	if _, ok := d["password"]; ok {
+		t.Error("Expected the password to be deleted")
+	}
+
+	rl.SetRules(nil)
+	rl.Log(health)
+	if l.count() != 2 {
+		t.Error("Expected no rules to keep every message")
+	}
+}
+
+func TestRulesErrors(t *testing.T) {
+	for _, src := range []string{
+		`explode`,
+		`drop when level ==`,
+		`set level = "info"`,
+		`set data.x "y"`,
+		`drop when data.path =~ "("`,
+		`drop when data.path == "/x`,
+		`drop when (level == "info"`,
+		`drop when host == "a"`,
+	} {
+		if _, err := ParseRules(src); err == nil {
+			t.Error("Expected", src, "to fail")
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// RuntimeMetrics returns the goroutine count, heap usage, and GC pause statistics
+func RuntimeMetrics() map[string]interface{} {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	last := ms.PauseNs[(ms.NumGC+255)%256]
+	return map[string]interface{}{
+		"goroutines":        runtime.NumGoroutine(),
+		"heap_inuse":        ms.HeapInuse,
+		"heap_alloc":        ms.HeapAlloc,
+		"num_gc":            ms.NumGC,
+		"gc_pause_last_ms":  float64(last) / float64(time.Millisecond),
+		"gc_pause_total_ms": float64(ms.PauseTotalNs) / float64(time.Millisecond),
+	}
+}
+
+type runtimeStats struct {
+	logger   jog.Logger
+	level    jog.Level
+	interval time.Duration
+	mu       sync.Mutex
+	last     time.Time
+}
+
+func (r *runtimeStats) Log(m interface{}) (int, error) {
+	if msg, ok := m.(*jog.Message); ok && r.attach(msg) {
+		msg.SetMeta("runtime", RuntimeMetrics())
+	}
+	return r.logger.Log(m)
+}
+
+// Whether the message gets stats, by level or because the interval has passed
+func (r *runtimeStats) attach(m *jog.Message) bool {
+	if r.level != "" && m.Level.AtLeast(r.level) {
+		return true
+	}
+	if r.interval <= 0 {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now := time.Now(); now.Sub(r.last) >= r.interval {
+		r.last = now
+		return true
+	}
+	return false
+}
+
+// RuntimeStats returns a jog.Logger that adds runtime statistics, see RuntimeMetrics,
+// under a `runtime` key to messages at, or above, `level`, and to one message per
+// `interval` otherwise. Either may be left empty.
+func RuntimeStats(l jog.Logger, level jog.Level, interval time.Duration) jog.Logger {
+	return &runtimeStats{logger: l, level: level, interval: interval}
+}
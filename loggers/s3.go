@@ -0,0 +1,231 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// S3Config holds the settings for an S3 logger
+type S3Config struct {
+	// Endpoint of the S3 compatible service, eg. `https://s3.us-east-1.amazonaws.com`.
+	// Objects are addressed path-style, `{Endpoint}/{Bucket}/{key}`.
+	Endpoint    string
+	Bucket      string
+	Region      string
+	Credentials AWSCredentials
+	// Prefix of object keys (defaults to `logs`)
+	Prefix string
+	// Host is used in object names (defaults to the hostname)
+	Host string
+	// Codec, when set, compresses objects, eg. Gzip
+	Codec Codec
+	// Gzip compresses objects with gzip, as when Codec is Gzip
+	Gzip bool
+	// BatchSize and Interval control how often objects are uploaded (default 10000, 5m)
+	BatchSize int
+	Interval  time.Duration
+	Client    *http.Client
+}
+
+// S3 is a jog.Logger that uploads batches of messages, as NDJSON objects, to
+// S3 compatible storage. Object keys are partitioned by date, eg.
+// `logs/2024/06/01/host-150405-0001.json.gz`.
+type S3 struct {
+	cfg   S3Config
+	seq   uint64
+	batch *jog.Batcher
+}
+
+// Log queues the message, uploading the batch once it's full
+func (s *S3) Log(m interface{}) (int, error) {
+	return s.batch.Log(m)
+}
+
+// LogBatch uploads the messages now, as a whole batch, see jog.BatchLogger
+func (s *S3) LogBatch(msgs []*jog.Message) (int, error) {
+	return 0, s.write(msgs)
+}
+
+// Flush uploads all pending messages
+func (s *S3) Flush() error {
+	return s.batch.Flush()
+}
+
+// Close uploads any pending messages and stops the background flushing
+func (s *S3) Close() error {
+	return s.batch.Close()
+}
+
+// Key returns the object key for a batch uploaded at `t`
+func (s *S3) key(t time.Time) string {
+	t = t.UTC()
+	n := atomic.AddUint64(&s.seq, 1)
+	k := fmt.Sprintf("%s/%s/%s-%s-%04d.json", s.cfg.Prefix, t.Format("2006/01/02"), s.cfg.Host, t.Format("150405"), n)
+	if s.cfg.Codec != nil {
+		k += s.cfg.Codec.Extension()
+	}
+	return k
+}
+
+func (s *S3) write(msgs []*jog.Message) error {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var zw CompressWriter
+	if s.cfg.Codec != nil {
+		var err error
+		if zw, err = s.cfg.Codec.NewWriter(&buf); err != nil {
+			return err
+		}
+		w = zw
+	}
+	enc := json.NewEncoder(w)
+	for _, m := range msgs {
+		if err := enc.Encode(m); err != nil {
+			return err
+		}
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return err
+		}
+	}
+	return s.put(s.key(time.Now()), buf.Bytes())
+}
+
+// Uploads the object
+func (s *S3) put(key string, body []byte) error {
+	u := strings.TrimSuffix(s.cfg.Endpoint, "/") + "/" + s.cfg.Bucket + "/" + key
+	req, err := http.NewRequest("PUT", u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	switch {
+	case s.cfg.Codec == Gzip:
+		req.Header.Set("Content-Type", "application/gzip")
+	case s.cfg.Codec != nil:
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		req.Header.Set("Content-Encoding", s.cfg.Codec.Name())
+	default:
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+	signV4(req, body, "s3", s.cfg.Region, s.cfg.Credentials, time.Now())
+
+	resp, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received a `%d` uploading `%s`: %s", resp.StatusCode, key, b)
+	}
+	return nil
+}
+
+type s3List struct {
+	Contents []struct {
+		Key          string
+		LastModified time.Time
+		Size         int64
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// Prune deletes the objects under the Prefix that are outside of the policy,
+// see Pruner
+func (s *S3) Prune(before time.Time, budget int64) (int, error) {
+	var entries []retained
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}, "prefix": {s.cfg.Prefix + "/"}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		var l s3List
+		if err := s.do("GET", "?"+q.Encode(), &l); err != nil {
+			return 0, err
+		}
+		for _, o := range l.Contents {
+			entries = append(entries, retained{o.Key, o.LastModified, o.Size})
+		}
+		if !l.IsTruncated {
+			break
+		}
+		token = l.NextContinuationToken
+	}
+
+	var n int
+	for _, e := range expired(entries, before, budget) {
+		if err := s.do("DELETE", "/"+e.name, nil); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Makes a bodiless request of the bucket, decoding an XML response into `v`
+func (s *S3) do(method, path string, v interface{}) error {
+	u := strings.TrimSuffix(s.cfg.Endpoint, "/") + "/" + s.cfg.Bucket + path
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return err
+	}
+	signV4(req, nil, "s3", s.cfg.Region, s.cfg.Credentials, time.Now())
+
+	resp, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received a `%d` from `%s %s`: %s", resp.StatusCode, method, u, b)
+	}
+	if v == nil {
+		return nil
+	}
+	return xml.NewDecoder(resp.Body).Decode(v)
+}
+
+// NewS3 returns a new S3 logger
+func NewS3(c S3Config) *S3 {
+	if c.Prefix == "" {
+		c.Prefix = "logs"
+	}
+	c.Prefix = strings.Trim(c.Prefix, "/")
+	if c.Host == "" {
+		c.Host, _ = os.Hostname()
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 10000
+	}
+	if c.Interval <= 0 {
+		c.Interval = 5 * time.Minute
+	}
+	if c.Gzip && c.Codec == nil {
+		c.Codec = Gzip
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	s := &S3{cfg: c}
+	s.batch = jog.NewBatcher(c.BatchSize, c.Interval, s.write)
+	return s
+}
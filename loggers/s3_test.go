@@ -0,0 +1,43 @@
+package loggers
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestS3(t *testing.T) {
+	var path, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+			t.Error("Expected signed request, got", r.Header.Get("Authorization"))
+		}
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(gz)
+		body = string(b)
+	}))
+	defer srv.Close()
+
+	s := NewS3(S3Config{Endpoint: srv.URL, Bucket: "archive", Region: "us-east-1", Host: "web1", Gzip: true, Interval: time.Hour})
+	s.Log(msg("one"))
+	s.Log(msg("two"))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !regexp.MustCompile(`^/archive/logs/\d{4}/\d{2}/\d{2}/web1-\d{6}-0001\.json\.gz$`).MatchString(path) {
+		t.Error("Unexpected object key", path)
+	}
+	if n := strings.Count(body, "\n"); n != 2 {
+		t.Error("Expected 2 NDJSON lines, got", n, body)
+	}
+}
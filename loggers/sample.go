@@ -0,0 +1,156 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// SampleConfig holds the settings for a Sampler
+type SampleConfig struct {
+	// Rate keeps 1 in Rate messages below Keep (defaults to 1, keeping them all)
+	Rate int
+	// Keep is the level at, and above, which every message is kept (defaults to jog.ERROR)
+	Keep jog.Level
+	// MaxRate, when above Rate, makes the sampler adaptive. While the pipeline is
+	// overloaded the rate doubles every Interval, up to MaxRate, and halves back
+	// towards Rate once it recovers.
+	MaxRate int
+	// Queue returns the depth of the queue being fed, eg. Async.Len, the pipeline
+	// is overloaded while it's above MaxQueue
+	Queue    func() int
+	MaxQueue int
+	// MaxLatency is the 95th percentile latency, of the Log calls through the
+	// sampler within an Interval, above which the pipeline is overloaded
+	MaxLatency time.Duration
+	// Interval at which the rate is adapted (defaults to 1s)
+	Interval time.Duration
+}
+
+// Sampler is a jog.Logger that logs a sample of the messages below a level,
+// shedding bulk DEBUG and INFO messages while keeping every error
+type Sampler struct {
+	logger  jog.Logger
+	cfg     SampleConfig
+	rate    int64
+	off     int32
+	n       uint64
+	mu      sync.Mutex
+	latency *jog.Latency
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// Log logs the message when it's kept by the sample
+func (s *Sampler) Log(m interface{}) (int, error) {
+	if msg, ok := m.(*jog.Message); ok && !msg.Level.AtLeast(s.cfg.Keep) && atomic.LoadInt32(&s.off) == 0 {
+		if rate := uint64(atomic.LoadInt64(&s.rate)); atomic.AddUint64(&s.n, 1)%rate != 0 {
+			return 0, nil
+		}
+	}
+	if s.cfg.MaxLatency <= 0 {
+		return s.logger.Log(m)
+	}
+	start := time.Now()
+	n, err := s.logger.Log(m)
+	s.mu.Lock()
+	s.latency.Since(start)
+	s.mu.Unlock()
+	return n, err
+}
+
+// Rate returns the current sample rate, 1 in Rate messages are kept
+func (s *Sampler) Rate() int {
+	return int(atomic.LoadInt64(&s.rate))
+}
+
+// SetEnabled turns sampling on or off, while off every message is kept
+func (s *Sampler) SetEnabled(on bool) {
+	var off int32
+	if !on {
+		off = 1
+	}
+	atomic.StoreInt32(&s.off, off)
+}
+
+// Enabled reports whether messages are being sampled, see SetEnabled
+func (s *Sampler) Enabled() bool {
+	return atomic.LoadInt32(&s.off) == 0
+}
+
+// Close stops adapting the rate
+func (s *Sampler) Close() error {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+	}
+	return nil
+}
+
+// Whether the queue, or latency, is over its threshold, starting a new latency window
+func (s *Sampler) overloaded() bool {
+	s.mu.Lock()
+	l := s.latency.Stats()
+	s.latency = new(jog.Latency)
+	s.mu.Unlock()
+	if s.cfg.MaxLatency > 0 && l.P95 > s.cfg.MaxLatency {
+		return true
+	}
+	return s.cfg.Queue != nil && s.cfg.MaxQueue > 0 && s.cfg.Queue() > s.cfg.MaxQueue
+}
+
+// Doubles, or halves, the rate within the Rate and MaxRate bounds
+func (s *Sampler) adapt() {
+	rate := s.Rate()
+	next := rate
+	if s.overloaded() {
+		if next *= 2; next > s.cfg.MaxRate {
+			next = s.cfg.MaxRate
+		}
+	} else if next /= 2; next < s.cfg.Rate {
+		next = s.cfg.Rate
+	}
+	if next != rate {
+		atomic.StoreInt64(&s.rate, int64(next))
+		jog.Diagnose(jog.INFO, "sample_rate", map[string]interface{}{"from": rate, "to": next})
+	}
+}
+
+func (s *Sampler) run() {
+	defer close(s.done)
+	t := time.NewTicker(s.cfg.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			s.adapt()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// NewSampler returns a new Sampler logging to `l`
+func NewSampler(l jog.Logger, c SampleConfig) *Sampler {
+	if c.Rate <= 0 {
+		c.Rate = 1
+	}
+	if c.Keep == "" {
+		c.Keep = jog.ERROR
+	}
+	if c.Interval <= 0 {
+		c.Interval = time.Second
+	}
+	s := &Sampler{logger: l, cfg: c, rate: int64(c.Rate), latency: new(jog.Latency)}
+	if c.MaxRate > c.Rate {
+		s.stop, s.done = make(chan struct{}), make(chan struct{})
+		go s.run()
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+package loggers
+
+import (
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestSampler(t *testing.T) {
+	l := &testLogger{}
+	s := NewSampler(l, SampleConfig{Rate: 10})
+	for i := 0; i < 100; i++ {
+		s.Log(msg(i))
+	}
+	e := msg("kaboom")
+	e.Level = jog.ERROR
+	s.Log(e)
+	if n := l.count(); n != 11 {
+		t.Error("Expected 10 sampled messages and the error, got", n)
+	}
+}
+
+func TestSamplerAdaptive(t *testing.T) {
+	depth := 500
+	s := NewSampler(&testLogger{}, SampleConfig{Rate: 10, MaxRate: 100, Queue: func() int { return depth }, MaxQueue: 100})
+	s.Close()
+
+	for i := 0; i < 5; i++ {
+		s.adapt()
+	}
+	if r := s.Rate(); r != 100 {
+		t.Error("Expected the rate to tighten to 100, got", r)
+	}
+	depth = 0
+	for i := 0; i < 5; i++ {
+		s.adapt()
+	}
+	if r := s.Rate(); r != 10 {
+		t.Error("Expected the rate to relax to 10, got", r)
+	}
+}
+
+func TestSamplerDisabled(t *testing.T) {
+	l := &testLogger{}
+	s := NewSampler(l, SampleConfig{Rate: 10})
+	s.SetEnabled(false)
+	for i := 0; i < 10; i++ {
+		s.Log(msg("x"))
+	}
+	if l.count() != 10 || s.Enabled() {
+		t.Error("Expected every message to be kept while disabled, got", l.count())
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.minty.io/jog"
+)
+
+// SIEMConfig holds the device fields of CEF and LEEF events
+type SIEMConfig struct {
+	Vendor  string
+	Product string
+	Version string
+	// EventID returns the event class ID of a message (defaults to its level)
+	EventID func(*jog.Message) string
+}
+
+// CEF/LEEF severities (0-10) of the jog levels
+var siemSeverity = map[jog.Level]int{
+	jog.DEBUG:    1,
+	jog.INFO:     3,
+	jog.WARNING:  5,
+	jog.ERROR:    8,
+	jog.CRITICAL: 10,
+}
+
+func (c SIEMConfig) eventID(m *jog.Message) string {
+	if c.EventID != nil {
+		return c.EventID(m)
+	}
+	return string(m.Level)
+}
+
+var (
+	cefHeader    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtension = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+	leefHeader   = strings.NewReplacer(`|`, ` `, "\t", " ", "\r", " ", "\n", " ")
+	leefValue    = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+)
+
+// Makes a valid extension key, letters, digits, `.` and `_` only
+func siemKey(s string) string {
+	k := []byte(s)
+	for i, c := range k {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '.' && c != '_' {
+			k[i] = '_'
+		}
+	}
+	return string(k)
+}
+
+// Adds the message's Meta to the fields, named by their path under `meta`, eg.
+// `meta.request_id`, and its Tags as `tags` unless Data has a field of that name
+func siemMeta(m *jog.Message, fields map[string]string) {
+	if len(m.Meta) > 0 {
+		syslogFlatten(fields, "meta", m.Meta)
+	}
+	if _, ok := fields["tags"]; !ok && len(m.Tags) > 0 {
+		fields["tags"] = strings.Join(m.Tags, ",")
+	}
+}
+
+// Sorted keys of the fields
+func sortedKeys(fields map[string]string) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NewCEF returns an Encoder producing ArcSight Common Event Format events.
+// The `message` field, or string Data, is the event name and `msg`, the timestamp is
+// `rt` and the other fields of Data, and Meta, are extensions named by their path.
+func NewCEF(c SIEMConfig) jog.Encoder {
+	prefix := "CEF:0|" + cefHeader.Replace(c.Vendor) + "|" + cefHeader.Replace(c.Product) +
+		"|" + cefHeader.Replace(c.Version) + "|"
+	return jog.EncoderFunc(func(m *jog.Message) ([]byte, error) {
+		msg, fields, err := syslogFields(m)
+		if err != nil {
+			return nil, err
+		}
+		if fields == nil {
+			fields = map[string]string{}
+		}
+		siemMeta(m, fields)
+		name := msg
+		if name == "" {
+			name = string(m.Level)
+		}
+
+		var b bytes.Buffer
+		b.WriteString(prefix)
+		b.WriteString(cefHeader.Replace(c.eventID(m)) + "|" + cefHeader.Replace(name) + "|")
+		b.WriteString(strconv.Itoa(siemSeverity[m.Level]) + "|")
+		b.WriteString("rt=" + strconv.FormatInt(m.Time.UnixNano()/1e6, 10))
+		if msg != "" {
+			b.WriteString(" msg=" + cefExtension.Replace(msg))
+		}
+		for _, k := range sortedKeys(fields) {
+			b.WriteString(" " + siemKey(k) + "=" + cefExtension.Replace(fields[k]))
+		}
+		b.WriteByte('\n')
+		return b.Bytes(), nil
+	})
+}
+
+// NewLEEF returns an Encoder producing IBM QRadar LEEF 1.0 events, with tab
+// delimited attributes. The timestamp is `devTime` (epoch milliseconds), the
+// severity `sev`, and the fields of Data, and Meta, are attributes named by their path.
+func NewLEEF(c SIEMConfig) jog.Encoder {
+	prefix := "LEEF:1.0|" + leefHeader.Replace(c.Vendor) + "|" + leefHeader.Replace(c.Product) +
+		"|" + leefHeader.Replace(c.Version) + "|"
+	return jog.EncoderFunc(func(m *jog.Message) ([]byte, error) {
+		msg, fields, err := syslogFields(m)
+		if err != nil {
+			return nil, err
+		}
+		if fields == nil {
+			fields = map[string]string{}
+		}
+		siemMeta(m, fields)
+
+		var b bytes.Buffer
+		b.WriteString(prefix)
+		b.WriteString(leefHeader.Replace(c.eventID(m)) + "|")
+		b.WriteString("devTime=" + strconv.FormatInt(m.Time.UnixNano()/1e6, 10))
+		b.WriteString("\tsev=" + strconv.Itoa(siemSeverity[m.Level]))
+		if msg != "" {
+			b.WriteString("\tmsg=" + leefValue.Replace(msg))
+		}
+		for _, k := range sortedKeys(fields) {
+			b.WriteString("\t" + siemKey(k) + "=" + leefValue.Replace(fields[k]))
+		}
+		b.WriteByte('\n')
+		return b.Bytes(), nil
+	})
+}
@@ -0,0 +1,35 @@
+package loggers
+
+import (
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+func TestCEFAndLEEF(t *testing.T) {
+	c := SIEMConfig{Vendor: "Acme", Product: "a|b", Version: "1.0"}
+	m := msg(map[string]interface{}{"message": "login failed", "src": "10.0.0.1", "user": map[string]interface{}{"name": "a=b"}})
+	m.Level = jog.WARNING
+	m.Time = time.Unix(1700000000, 0)
+	m.SetMeta("request_id", "abc")
+	m.Tag("auth")
+
+	b, err := NewCEF(c).Encode(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `CEF:0|Acme|a\|b|1.0|warning|login failed|5|rt=1700000000000 msg=login failed meta.request_id=abc src=10.0.0.1 tags=auth user.name=a\=b` + "\n"
+	if string(b) != exp {
+		t.Errorf("Expected\n%s\ngot\n%s", exp, b)
+	}
+
+	b, err = NewLEEF(c).Encode(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = "LEEF:1.0|Acme|a b|1.0|warning|devTime=1700000000000\tsev=5\tmsg=login failed\tmeta.request_id=abc\tsrc=10.0.0.1\ttags=auth\tuser.name=a=b\n"
+	if string(b) != exp {
+		t.Errorf("Expected\n%q\ngot\n%q", exp, b)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"code.minty.io/jog"
+)
+
+// ErrSignature is returned when an entry's signature doesn't match
+var ErrSignature = errors.New("jog: invalid signature")
+
+type signer struct {
+	logger jog.Logger
+	keyID  string
+	key    []byte
+}
+
+// Signs the message and logs it
+func (s *signer) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return s.logger.Log(m)
+	}
+	msg.SetMeta("key_id", s.keyID)
+	sig, err := signature(msg, s.key)
+	if err != nil {
+		return 0, err
+	}
+	msg.SetMeta("signature", sig)
+	return s.logger.Log(msg)
+}
+
+// HMAC-SHA256 of the canonical JSON of an entry, hex encoded
+func signature(v interface{}, key []byte) (string, error) {
+	b, err := canonicalJSON(v)
+	if err != nil {
+		return "", err
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Sign returns a jog.Logger that adds a `key_id` and an HMAC-SHA256 `signature`
+// to the Meta of each message before logging it to `l`. The signature covers the
+// canonical JSON of the message (keys sorted), so it survives re-encoding.
+func Sign(l jog.Logger, keyID string, key []byte) jog.Logger {
+	return &signer{l, keyID, key}
+}
+
+// VerifySignature checks the signature of an encoded (JSON) message, looking up
+// the key by the message's `key_id`
+func VerifySignature(entry []byte, keys map[string][]byte) error {
+	o, err := decodeJSON(json.RawMessage(entry))
+	if err != nil {
+		return err
+	}
+	m, _ := o.(map[string]interface{})
+	meta, _ := m["meta"].(map[string]interface{})
+	sig, _ := meta["signature"].(string)
+	id, _ := meta["key_id"].(string)
+	if sig == "" {
+		return fmt.Errorf("%w: not signed", ErrSignature)
+	}
+	key, ok := keys[id]
+	if !ok {
+		return fmt.Errorf("%w: unknown key %q", ErrSignature, id)
+	}
+
+	delete(meta, "signature")
+	exp, err := signature(m, key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(sig), []byte(exp)) {
+		return ErrSignature
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package loggers
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	var buf bytes.Buffer
+	keys := map[string][]byte{"k1": []byte("secret")}
+	l := Sign(lineLogger{&buf}, "k1", keys["k1"])
+	if _, err := l.Log(msg(map[string]interface{}{"user": "bob", "id": 9007199254740993})); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := bytes.TrimSpace(buf.Bytes())
+	if err := VerifySignature(entry, keys); err != nil {
+		t.Error("Expected a valid signature, got", err)
+	}
+	if err := VerifySignature(bytes.Replace(entry, []byte("bob"), []byte("eve"), 1), keys); !errors.Is(err, ErrSignature) {
+		t.Error("Expected a tampered entry to fail, got", err)
+	}
+	if err := VerifySignature(entry, map[string][]byte{"k1": []byte("other")}); !errors.Is(err, ErrSignature) {
+		t.Error("Expected the wrong key to fail, got", err)
+	}
+}
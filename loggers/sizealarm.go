@@ -0,0 +1,141 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// SizeAlarmConfig holds the settings for a SizeAlarm
+type SizeAlarmConfig struct {
+	// Percentile of the sizes seen so far, between 0 and 1, above which a message
+	// is an outlier, eg. 0.999 (defaults to none)
+	Percentile float64
+	// MinSamples is the number of messages seen before Percentile applies (defaults to 1000)
+	MinSamples uint64
+	// MaxBytes is the size above which a message is always an outlier (defaults to none)
+	MaxBytes int
+	// OnOutlier is called for each outlier, before it's logged
+	OnOutlier func(o SizeOutlier, m *jog.Message)
+	// Quiet skips logging a WARNING for outliers, eg. when OnOutlier reports them
+	Quiet bool
+	// Interval is the least time between WARNINGs for the same call site (defaults to 1m)
+	Interval time.Duration
+}
+
+// SizeOutlier is the Data of the WARNING a SizeAlarm logs for an outlier
+type SizeOutlier struct {
+	Message   string        `json:"message"`
+	Size      int           `json:"size"`
+	Threshold int           `json:"threshold"`
+	Stats     jog.SizeStats `json:"stats"`
+	Level     jog.Level     `json:"level"`
+	File      string        `json:"file,omitempty"`
+	Line      int           `json:"line,omitempty"`
+	Logger    string        `json:"logger,omitempty"`
+}
+
+// SizeAlarm is a jog.Logger that tracks the distribution of encoded message
+// sizes, and reports outliers, catching accidental logging of whole request
+// bodies or large blobs. Outliers are still logged.
+type SizeAlarm struct {
+	logger jog.Logger
+	cfg    SizeAlarmConfig
+	sizes  jog.Sizes
+	mu     sync.Mutex
+	warned map[string]time.Time
+}
+
+// Log logs the message, reporting it first when it's an outlier
+func (s *SizeAlarm) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return s.logger.Log(m)
+	}
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return s.logger.Log(m)
+	}
+	size := len(b)
+	threshold := s.threshold()
+	s.sizes.Observe(size)
+	if threshold > 0 && size > threshold {
+		s.report(msg, size, threshold)
+	}
+	return s.logger.Log(m)
+}
+
+// Sizes returns the percentiles of the encoded message sizes
+func (s *SizeAlarm) Sizes() jog.SizeStats {
+	return s.sizes.Stats()
+}
+
+// The size above which messages are outliers, zero when there's none yet
+func (s *SizeAlarm) threshold() int {
+	t := s.cfg.MaxBytes
+	if s.cfg.Percentile <= 0 {
+		return t
+	}
+	if p, n := s.sizes.Percentile(s.cfg.Percentile); n >= s.cfg.MinSamples && (t <= 0 || p < t) {
+		t = p
+	}
+	return t
+}
+
+func (s *SizeAlarm) report(msg *jog.Message, size, threshold int) {
+	o := SizeOutlier{
+		Message:   fmt.Sprintf("message of %d bytes exceeds %d", size, threshold),
+		Size:      size,
+		Threshold: threshold,
+		Stats:     s.sizes.Stats(),
+		Level:     msg.Level,
+		File:      msg.File,
+		Line:      msg.Line,
+	}
+	o.Logger, _ = msg.Meta["logger"].(string)
+	if s.cfg.OnOutlier != nil {
+		s.cfg.OnOutlier(o, msg)
+	}
+	if s.cfg.Quiet {
+		return
+	}
+
+	site := fmt.Sprintf("%s:%d", msg.File, msg.Line)
+	now := time.Now()
+	s.mu.Lock()
+	if t, ok := s.warned[site]; ok && now.Sub(t) < s.cfg.Interval {
+		s.mu.Unlock()
+		return
+	}
+	s.warned[site] = now
+	s.mu.Unlock()
+
+	w := &jog.Message{
+		Data:    o,
+		Level:   jog.WARNING,
+		Time:    now.UTC(),
+		Version: jog.SchemaVersion,
+		File:    msg.File,
+		Line:    msg.Line,
+		Func:    msg.Func,
+	}
+	s.logger.Log(w)
+}
+
+// NewSizeAlarm returns a new SizeAlarm logging to `l`
+func NewSizeAlarm(l jog.Logger, c SizeAlarmConfig) *SizeAlarm {
+	if c.MinSamples == 0 {
+		c.MinSamples = 1000
+	}
+	if c.Interval <= 0 {
+		c.Interval = time.Minute
+	}
+	return &SizeAlarm{logger: l, cfg: c, warned: make(map[string]time.Time)}
+}
@@ -0,0 +1,56 @@
+package loggers
+
+import (
+	"strings"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestSizeAlarm(t *testing.T) {
+	l := &testLogger{}
+	var outliers []SizeOutlier
+	s := NewSizeAlarm(l, SizeAlarmConfig{
+		Percentile: 0.99,
+		MinSamples: 100,
+		MaxBytes:   1 << 20,
+		OnOutlier:  func(o SizeOutlier, m *jog.Message) { outliers = append(outliers, o) },
+	})
+	for i := 0; i < 100; i++ {
+		s.Log(msg("ok"))
+	}
+	if len(outliers) != 0 {
+		t.Fatal("Expected no outliers among similar messages, got", outliers)
+	}
+
+	big := msg(strings.Repeat("x", 10000))
+	big.File, big.Line = "handler.go", 42
+	s.Log(big)
+	s.Log(big)
+	if len(outliers) != 2 || outliers[0].Size < 10000 || outliers[0].File != "handler.go" {
+		t.Fatalf("Expected both outliers to be reported, got %+v", outliers)
+	}
+	// Each outlier is logged, with one WARNING for the call site
+	if n := l.count(); n != 103 {
+		t.Fatal("Expected the messages and a single warning, got", n)
+	}
+	w := l.messages[100].(*jog.Message)
+	if o, ok := w.Data.(SizeOutlier); !ok || w.Level != jog.WARNING || o.Threshold >= o.Size {
+		t.Errorf("Unexpected warning %+v", w)
+	}
+	if st := s.Sizes(); st.Count != 102 || st.Max < 10000 {
+		t.Errorf("Unexpected sizes %+v", st)
+	}
+}
+
+func TestSizeAlarmMaxBytes(t *testing.T) {
+	l := &testLogger{}
+	s := NewSizeAlarm(l, SizeAlarmConfig{MaxBytes: 100, Quiet: true})
+	s.Log(msg(strings.Repeat("x", 200)))
+	if n := l.count(); n != 1 {
+		t.Error("Expected quiet outliers to be logged without a warning, got", n)
+	}
+	if th := s.threshold(); th != 100 {
+		t.Error("Expected the absolute threshold, got", th)
+	}
+}
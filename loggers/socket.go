@@ -0,0 +1,139 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Socket is a jog.Logger that writes encoded messages to a stream socket, such as
+// a local collector's Unix domain socket. It connects on first use, and reconnects
+// after a failed write.
+type Socket struct {
+	network string
+	addr    string
+	enc     jog.Encoder
+	tls     *tls.Config
+	timeout time.Duration
+	zip     Codec
+	mu      sync.Mutex
+	conn    net.Conn
+	zw      CompressWriter
+	dropped bool
+}
+
+// Log encodes and writes the message, retrying once on a fresh connection
+func (s *Socket) Log(m interface{}) (int, error) {
+	b, err := encode(s.enc, m)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, err := s.write(b)
+	if err != nil {
+		n, err = s.write(b)
+	}
+	return n, err
+}
+
+// Encodes a message, raw JSON (see jog.WithRaw) being written as is
+func encode(enc jog.Encoder, m interface{}) ([]byte, error) {
+	switch m := m.(type) {
+	case *jog.Message:
+		return enc.Encode(m)
+	case json.RawMessage:
+		return append(m[:len(m):len(m)], '\n'), nil
+	}
+	return enc.Encode(&jog.Message{Data: m, Level: jog.UNKNOWN})
+}
+
+func (s *Socket) write(b []byte) (int, error) {
+	if s.conn == nil {
+		c, err := s.dial()
+		if err != nil {
+			return 0, err
+		}
+		if s.dropped {
+			s.dropped = false
+			jog.Diagnose(jog.INFO, "reconnect", map[string]interface{}{"network": s.network, "addr": s.addr})
+		}
+		if s.zip != nil {
+			if s.zw, err = s.zip.NewWriter(c); err != nil {
+				c.Close()
+				return 0, err
+			}
+		}
+		s.conn = c
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	n, err := writeRecord(s.conn, s.zw, b)
+	if err != nil {
+		s.conn.Close()
+		s.conn, s.zw = nil, nil
+		s.dropped = true
+	}
+	return n, err
+}
+
+// SetCompressor compresses the stream written to each connection, eg. with Gzip.
+// Each message is flushed, so the collector can decompress them as they arrive.
+func (s *Socket) SetCompressor(c Codec) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.zip = c
+}
+
+func (s *Socket) dial() (net.Conn, error) {
+	d := &net.Dialer{Timeout: s.timeout}
+	if s.tls != nil {
+		return tls.DialWithDialer(d, s.network, s.addr, s.tls)
+	}
+	return d.Dial(s.network, s.addr)
+}
+
+// Close closes the connection
+func (s *Socket) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	if s.zw != nil {
+		s.zw.Close()
+	}
+	err := s.conn.Close()
+	s.conn, s.zw = nil, nil
+	return err
+}
+
+// NewSocket returns a new Socket logger writing to `addr` on the given network,
+// eg. `unix` or `tcp`, using `enc` (jog.JSON when nil)
+func NewSocket(network, addr string, enc jog.Encoder) *Socket {
+	if enc == nil {
+		enc = jog.JSON
+	}
+	return &Socket{network: network, addr: addr, enc: enc, timeout: 3 * time.Second}
+}
+
+// NewUnix returns a new Socket logger writing NDJSON to the Unix domain socket at `path`
+func NewUnix(path string) *Socket {
+	return NewSocket("unix", path, jog.JSON)
+}
+
+// NewTLSSocket returns a new Socket logger writing to `addr` over TCP with TLS,
+// see TLSConfig for mutual TLS and custom CAs
+func NewTLSSocket(addr string, t *tls.Config, enc jog.Encoder) *Socket {
+	s := NewSocket("tcp", addr, enc)
+	s.tls = t
+	return s
+}
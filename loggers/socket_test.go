@@ -0,0 +1,102 @@
+package loggers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestUnixReconnect(t *testing.T) {
+	dir, err := os.MkdirTemp("", "jog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "collector.sock")
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 2)
+	go func() {
+		for i := 0; i < 2; i++ {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			l, _ := bufio.NewReader(c).ReadString('\n')
+			lines <- l
+			c.Close()
+		}
+	}()
+
+	s := NewUnix(path)
+	defer s.Close()
+	if _, err := s.Log(msg("first")); err != nil {
+		t.Fatal(err)
+	}
+	if l := <-lines; !strings.Contains(l, `"first"`) {
+		t.Error("Unexpected line", l)
+	}
+
+	// The collector closed the connection, so writes eventually fail and reconnect
+	for i := 0; i < 100 && len(lines) == 0; i++ {
+		s.Log(msg("second"))
+	}
+	if l := <-lines; !strings.Contains(l, `"second"`) {
+		t.Error("Unexpected line", l)
+	}
+}
+
+func TestEncodeRaw(t *testing.T) {
+	b, err := encode(jog.JSON, json.RawMessage(`{"b":1,"a":2}`))
+	if err != nil || string(b) != "{\"b\":1,\"a\":2}\n" {
+		t.Errorf("Expected raw JSON as is, got %q %v", b, err)
+	}
+}
+
+func TestSocketGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "collector.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 2)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		gz, err := gzip.NewReader(c)
+		if err != nil {
+			return
+		}
+		r := bufio.NewReader(gz)
+		for i := 0; i < 2; i++ {
+			l, _ := r.ReadString('\n')
+			lines <- l
+		}
+	}()
+
+	s := NewUnix(path)
+	s.SetCompressor(Gzip)
+	defer s.Close()
+	s.Log(msg("one"))
+	s.Log(msg("two"))
+	for _, d := range []string{"one", "two"} {
+		if l := <-lines; !strings.Contains(l, `"data":"`+d+`"`) {
+			t.Error("Unexpected line", l)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Spool is a file backed queue of messages, one JSON record per line, each prefixed
+// by its CRC-32. It's used to hold messages that can't be delivered right away.
+//
+// Records that fail their checksum, such as a partially written record after a
+// crash, are moved to a quarantine file, the spool's path with a `.corrupt` suffix,
+// for inspection.
+type Spool struct {
+	mu      sync.Mutex
+	file    *os.File
+	path    string
+	n       int
+	corrupt int
+	expired int
+	ttl     map[jog.Level]time.Duration
+}
+
+// SetTTL sets how long messages of each level are kept, messages older than their
+// level's TTL are discarded rather than delivered, eg. stale DEBUG messages after an
+// outage. Levels without a TTL, such as ERROR, are kept indefinitely.
+func (s *Spool) SetTTL(ttl map[jog.Level]time.Duration) {
+	s.mu.Lock()
+	s.ttl = ttl
+	s.mu.Unlock()
+}
+
+// Reports whether the message has outlived its level's TTL, counting it if so
+func (s *Spool) stale(m *jog.Message, now time.Time) bool {
+	ttl, ok := s.ttl[m.Level]
+	if !ok || m.Time.IsZero() || now.Sub(m.Time) <= ttl {
+		return false
+	}
+	s.expired++
+	return true
+}
+
+// OpenSpool opens, or creates, the spool file at the given path, recovering the
+// valid records left over from a previous run
+func OpenSpool(path string) (*Spool, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	s := &Spool{file: f, path: path}
+
+	var good, bad [][]byte
+	r := bufio.NewReader(f)
+	for {
+		b, err := r.ReadBytes('\n')
+		if len(b) > 1 {
+			if _, ok := unframe(b); ok && err == nil {
+				good = append(good, b)
+			} else {
+				bad = append(bad, b)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	s.n = len(good)
+	if len(bad) == 0 {
+		return s, nil
+	}
+
+	// Quarantine the corrupt records and rewrite the spool without them
+	if err := s.quarantine(bad); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Truncate(0); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Write(bytes.Join(good, nil)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Frames a record as its CRC-32, in hex, a space, and the record
+func frame(b []byte) []byte {
+	out := make([]byte, 0, len(b)+10)
+	out = append(out, fmt.Sprintf("%08x ", crc32.ChecksumIEEE(b))...)
+	out = append(out, b...)
+	return append(out, '\n')
+}
+
+// Returns the record of a line, and whether it's intact. Lines without a checksum,
+// written by earlier versions, are accepted when they're valid JSON.
+func unframe(line []byte) ([]byte, bool) {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	if len(line) > 9 && line[8] == ' ' {
+		sum, err := strconv.ParseUint(string(line[:8]), 16, 32)
+		if err == nil {
+			b := line[9:]
+			return b, uint32(sum) == crc32.ChecksumIEEE(b)
+		}
+	}
+	return line, len(line) > 0 && line[0] == '{' && json.Valid(line)
+}
+
+// Appends the records to the quarantine file
+func (s *Spool) quarantine(records [][]byte) error {
+	q, err := os.OpenFile(s.path+".corrupt", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	for _, b := range records {
+		if len(b) > 0 && b[len(b)-1] != '\n' {
+			b = append(b, '\n')
+		}
+		if _, err := q.Write(b); err != nil {
+			q.Close()
+			return err
+		}
+	}
+	s.corrupt += len(records)
+	jog.Diagnose(jog.WARNING, "spool_corrupt", map[string]interface{}{"path": s.path, "records": len(records)})
+	return q.Close()
+}
+
+// Append writes the message to the end of the spool
+func (s *Spool) Append(m interface{}) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(frame(b)); err != nil {
+		return err
+	}
+	s.n++
+	return nil
+}
+
+// Len returns the number of records in the spool
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.n
+}
+
+// Expired returns the number of messages discarded for outliving their TTL
+func (s *Spool) Expired() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expired
+}
+
+// Corrupted returns the number of records that have been quarantined
+func (s *Spool) Corrupted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.corrupt
+}
+
+// Peek returns the messages of up to `n` records from the front of the spool,
+// without removing them, and the number of records read, to be passed to Ack.
+// Records that fail their checksum, can't be decoded, or are stale (see SetTTL)
+// are read but skipped.
+func (s *Spool) Peek(n int) ([]*jog.Message, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+	var msgs []*jog.Message
+	var read int
+	now := time.Now()
+	r := bufio.NewReader(s.file)
+	for read < n {
+		b, err := r.ReadBytes('\n')
+		if len(b) > 1 && err == nil {
+			read++
+			m := new(jog.Message)
+			if rec, ok := unframe(b); ok && json.Unmarshal(rec, m) == nil && !s.stale(m, now) {
+				msgs = append(msgs, m)
+			}
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, 0, err
+		}
+	}
+	return msgs, read, nil
+}
+
+// Ack removes the first `n` records, once they've been delivered, see Peek
+func (s *Spool) Ack(n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReader(s.file)
+	for i := 0; i < n; {
+		b, err := r.ReadBytes('\n')
+		if len(b) > 1 {
+			i++
+		}
+		if err != nil {
+			break
+		}
+	}
+	rest, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if err := s.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := s.file.Write(rest); err != nil {
+		return err
+	}
+	if s.n -= n; s.n < 0 {
+		s.n = 0
+	}
+	return s.file.Sync()
+}
+
+// Drain removes all records from the spool and returns them as messages.
+// Records that fail their checksum are quarantined, and those that can't be
+// decoded, or are stale (see SetTTL), are skipped.
+func (s *Spool) Drain() ([]*jog.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var msgs []*jog.Message
+	var bad [][]byte
+	now := time.Now()
+	r := bufio.NewReader(s.file)
+	for {
+		b, err := r.ReadBytes('\n')
+		if len(b) > 1 {
+			if rec, ok := unframe(b); !ok {
+				bad = append(bad, b)
+			} else if m := new(jog.Message); json.Unmarshal(rec, m) == nil && !s.stale(m, now) {
+				msgs = append(msgs, m)
+			}
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if len(bad) > 0 {
+		if err := s.quarantine(bad); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.file.Truncate(0); err != nil {
+		return nil, err
+	}
+	s.n = 0
+	return msgs, nil
+}
+
+// Close closes the underlying spool file
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,64 @@
+package loggers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+func TestSpoolRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool")
+	s, err := OpenSpool(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Append(msg("one"))
+	s.Append(msg("two"))
+	s.Close()
+
+	// Flip a byte of the second record, and leave a partial record, as after a crash
+	b, _ := os.ReadFile(path)
+	i := strings.Index(string(b), "two")
+	b[i] = 'T'
+	b = append(b, `0badc0de {"data":"thr`...)
+	os.WriteFile(path, b, 0600)
+
+	if s, err = OpenSpool(path); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if s.Len() != 1 || s.Corrupted() != 2 {
+		t.Fatal("Expected 1 record recovered and 2 quarantined, got", s.Len(), s.Corrupted())
+	}
+	msgs, err := s.Drain()
+	if err != nil || len(msgs) != 1 || msgs[0].Data != "one" {
+		t.Error("Unexpected drained messages", msgs, err)
+	}
+	q, _ := os.ReadFile(path + ".corrupt")
+	if !strings.Contains(string(q), "Two") || !strings.Contains(string(q), "thr") {
+		t.Error("Expected the corrupt records to be quarantined, got", string(q))
+	}
+}
+
+func TestSpoolTTL(t *testing.T) {
+	s, err := OpenSpool(filepath.Join(t.TempDir(), "spool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetTTL(map[jog.Level]time.Duration{jog.DEBUG: time.Minute})
+
+	old := time.Now().Add(-time.Hour)
+	s.Append(&jog.Message{Data: "stale", Level: jog.DEBUG, Time: old})
+	s.Append(&jog.Message{Data: "kept", Level: jog.ERROR, Time: old})
+	s.Append(&jog.Message{Data: "fresh", Level: jog.DEBUG, Time: time.Now()})
+
+	msgs, n, err := s.Peek(10)
+	if err != nil || n != 3 || len(msgs) != 2 || msgs[0].Data != "kept" || msgs[1].Data != "fresh" || s.Expired() != 1 {
+		t.Error("Expected the stale message to be skipped, got", msgs, n, s.Expired(), err)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Dialect holds the SQL that differs between databases
+type Dialect struct {
+	// Schema is the CREATE TABLE statement, formatted with the table name. Tables
+	// created before the `meta` and `tags` columns were added need them added,
+	// eg. `ALTER TABLE logs ADD COLUMN meta JSONB, ADD COLUMN tags JSONB`.
+	Schema string
+	// Init statements are run when a Logger is created
+	Init []string
+	// Placeholder returns the bind parameter for the n'th (1 based) argument
+	Placeholder func(n int) string
+}
+
+// Postgres stores Data, Meta and Tags in JSONB columns
+var Postgres = &Dialect{
+	Schema: `CREATE TABLE IF NOT EXISTS %s (
+	id BIGSERIAL PRIMARY KEY,
+	time TIMESTAMPTZ NOT NULL,
+	level TEXT NOT NULL,
+	file TEXT NOT NULL,
+	line INTEGER NOT NULL,
+	data JSONB,
+	meta JSONB,
+	tags JSONB
+)`,
+	Placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+}
+
+// SQLite stores Data, Meta and Tags as JSON text, and switches the database to WAL mode so
+// readers don't block the logger
+var SQLite = &Dialect{
+	Schema: `CREATE TABLE IF NOT EXISTS %s (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	time DATETIME NOT NULL,
+	level TEXT NOT NULL,
+	file TEXT NOT NULL,
+	line INTEGER NOT NULL,
+	data TEXT,
+	meta TEXT,
+	tags TEXT
+)`,
+	Init:        []string{"PRAGMA journal_mode=WAL"},
+	Placeholder: func(n int) string { return "?" },
+}
+
+// CreateTable returns the statement creating the given log table
+func (d *Dialect) CreateTable(table string) string {
+	return fmt.Sprintf(d.Schema, table)
+}
@@ -0,0 +1,89 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+var levels = []jog.Level{jog.DEBUG, jog.INFO, jog.WARNING, jog.ERROR, jog.CRITICAL}
+
+// Query selects logged messages, zero values are ignored
+type Query struct {
+	// Level is the minimum level of messages returned
+	Level jog.Level
+	Since time.Time
+	Until time.Time
+	// Limit is the maximum number of messages returned, newest first
+	Limit int
+}
+
+// Query returns the logged messages matching `q`, newest first
+func (l *Logger) Query(q Query) ([]*jog.Message, error) {
+	var where []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return l.dialect.Placeholder(len(args))
+	}
+
+	if q.Level != "" {
+		var in []string
+		for _, lv := range levels {
+			if lv.AtLeast(q.Level) {
+				in = append(in, arg(string(lv)))
+			}
+		}
+		where = append(where, "level IN ("+strings.Join(in, ", ")+")")
+	}
+	if !q.Since.IsZero() {
+		where = append(where, "time >= "+arg(q.Since))
+	}
+	if !q.Until.IsZero() {
+		where = append(where, "time < "+arg(q.Until))
+	}
+
+	s := fmt.Sprintf("SELECT time, level, file, line, data, meta, tags FROM %s", l.table)
+	if len(where) > 0 {
+		s += " WHERE " + strings.Join(where, " AND ")
+	}
+	s += " ORDER BY id DESC"
+	if q.Limit > 0 {
+		s += fmt.Sprintf(" LIMIT %d", q.Limit)
+	}
+
+	rows, err := l.db.Query(s, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var msgs []*jog.Message
+	for rows.Next() {
+		var m jog.Message
+		var level string
+		var data, meta, tags []byte
+		if err := rows.Scan(&m.Time, &level, &m.File, &m.Line, &data, &meta, &tags); err != nil {
+			return nil, err
+		}
+		m.Level = jog.Level(level)
+		if len(data) > 0 {
+			json.Unmarshal(data, &m.Data)
+		}
+		if len(meta) > 0 {
+			json.Unmarshal(meta, &m.Meta)
+		}
+		if len(tags) > 0 {
+			json.Unmarshal(tags, &m.Tags)
+		}
+		msgs = append(msgs, &m)
+	}
+	return msgs, rows.Err()
+}
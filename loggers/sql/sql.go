@@ -0,0 +1,182 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sql contains a jog.Logger that inserts messages into a database table
+// using database/sql. The driver is left to the application, eg.
+//
+//	import _ "github.com/lib/pq"
+//
+//	db, _ := sql.Open("postgres", "dbname=app sslmode=disable")
+//	l, err := jogsql.New(db, jogsql.Config{Table: "logs", CreateTable: true})
+//
+// or, as an embedded store
+//
+//	import _ "github.com/mattn/go-sqlite3"
+//
+//	db, _ := sql.Open("sqlite3", "logs.db")
+//	l, err := jogsql.New(db, jogsql.Config{Dialect: jogsql.SQLite, CreateTable: true, MaxRows: 100000})
+package sql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+var ident = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Config holds the settings for a Logger
+type Config struct {
+	// Table messages are inserted into (defaults to `logs`)
+	Table string
+	// BatchSize is the number of messages inserted per transaction (defaults to 100)
+	BatchSize int
+	// Interval at which pending messages are inserted (defaults to 1s)
+	Interval time.Duration
+	// Dialect of the database (defaults to Postgres)
+	Dialect *Dialect
+	// CreateTable creates the table, when it doesn't exist, see Dialect.Schema
+	CreateTable bool
+	// MaxRows, when set, prunes the oldest rows after each batch so the table
+	// holds at most this many messages
+	MaxRows int
+}
+
+// Logger is a jog.Logger that inserts messages, in batched transactions, into a table
+// with `time`, `level`, `file`, `line`, `data`, `meta` and `tags` columns, see
+// Dialect.Schema.
+type Logger struct {
+	db      *sql.DB
+	dialect *Dialect
+	table   string
+	insert  *sql.Stmt
+	prune   string
+	expire  string
+	max     int
+	batch   *jog.Batcher
+}
+
+// Log queues the message, inserting the batch once it's full
+func (l *Logger) Log(m interface{}) (int, error) {
+	return l.batch.Log(m)
+}
+
+// LogBatch inserts the messages now, as a whole batch, see jog.BatchLogger
+func (l *Logger) LogBatch(msgs []*jog.Message) (int, error) {
+	return 0, l.write(msgs)
+}
+
+// Flush inserts all pending messages in a single transaction
+func (l *Logger) Flush() error {
+	return l.batch.Flush()
+}
+
+// Inserts the messages in a single transaction
+func (l *Logger) write(msgs []*jog.Message) error {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt := tx.Stmt(l.insert)
+	for _, m := range msgs {
+		d, err := m.DataJSON()
+		if err != nil {
+			d, _ = json.Marshal(fmt.Sprint(m.Data))
+		}
+		if _, err := stmt.Exec(m.Time, string(m.Level), m.File, m.Line, string(d), column(len(m.Meta), m.Meta), column(len(m.Tags), m.Tags)); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if l.max > 0 {
+		if _, err := tx.Exec(l.prune, l.max); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Returns the JSON of a Meta, or Tags, column, NULL when empty
+func column(n int, v interface{}) interface{} {
+	if n == 0 {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return string(b)
+}
+
+// Prune deletes the rows logged before `before`. The size budget isn't applied,
+// MaxRows bounds the size of the table instead. It satisfies loggers.Pruner, so a
+// table may be pruned by a loggers.Retention manager.
+func (l *Logger) Prune(before time.Time, budget int64) (int, error) {
+	if before.IsZero() {
+		return 0, nil
+	}
+	r, err := l.db.Exec(l.expire, before)
+	if err != nil {
+		return 0, err
+	}
+	n, err := r.RowsAffected()
+	return int(n), err
+}
+
+// Close inserts any pending messages and stops the background flushing
+func (l *Logger) Close() error {
+	err := l.batch.Close()
+	l.insert.Close()
+	return err
+}
+
+// New returns a new Logger inserting into the configured table of `db`
+func New(db *sql.DB, c Config) (*Logger, error) {
+	if c.Table == "" {
+		c.Table = "logs"
+	}
+	if !ident.MatchString(c.Table) {
+		return nil, errors.New("jog/sql: invalid table name " + c.Table)
+	}
+	d := c.Dialect
+	if d == nil {
+		d = Postgres
+	}
+
+	for _, s := range d.Init {
+		if _, err := db.Exec(s); err != nil {
+			return nil, err
+		}
+	}
+	if c.CreateTable {
+		if _, err := db.Exec(d.CreateTable(c.Table)); err != nil {
+			return nil, err
+		}
+	}
+
+	p := d.Placeholder
+	q := fmt.Sprintf("INSERT INTO %s (time, level, file, line, data, meta, tags) VALUES (%s, %s, %s, %s, %s, %s, %s)",
+		c.Table, p(1), p(2), p(3), p(4), p(5), p(6), p(7))
+	stmt, err := db.Prepare(q)
+	if err != nil {
+		return nil, err
+	}
+	l := &Logger{
+		db:      db,
+		dialect: d,
+		table:   c.Table,
+		insert:  stmt,
+		prune:   fmt.Sprintf("DELETE FROM %s WHERE id <= (SELECT MAX(id) FROM %s) - %s", c.Table, c.Table, p(1)),
+		expire:  fmt.Sprintf("DELETE FROM %s WHERE time < %s", c.Table, p(1)),
+		max:     c.MaxRows,
+	}
+	l.batch = jog.NewBatcher(c.BatchSize, c.Interval, l.write)
+	return l, nil
+}
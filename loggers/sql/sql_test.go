@@ -0,0 +1,237 @@
+package sql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// A database/sql driver recording the statements run against it, so the generated
+// SQL is tested without a database
+type fakeDriver struct {
+	mu   sync.Mutex
+	conn map[string]*fakeConn
+}
+
+var fake = &fakeDriver{conn: make(map[string]*fakeConn)}
+
+func init() {
+	sql.Register("jogfake", fake)
+}
+
+type fakeExec struct {
+	query string
+	args  []driver.Value
+}
+
+type fakeConn struct {
+	mu    sync.Mutex
+	execs []fakeExec
+	// Rows returned by queries
+	rows [][]driver.Value
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.conn[name], nil
+}
+
+// Opens a database recording to the returned conn
+func fakeDB(t *testing.T) (*sql.DB, *fakeConn) {
+	c := &fakeConn{}
+	fake.mu.Lock()
+	fake.conn[t.Name()] = c
+	fake.mu.Unlock()
+	db, err := sql.Open("jogfake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db, c
+}
+
+func (c *fakeConn) record(query string, args []driver.Value) {
+	c.mu.Lock()
+	c.execs = append(c.execs, fakeExec{query, args})
+	c.mu.Unlock()
+}
+
+// Returns the recorded statements, clearing them
+func (c *fakeConn) take() []fakeExec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.execs
+	c.execs = nil
+	return e
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c, query}, nil
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.record("BEGIN", nil)
+	return fakeTx{c}, nil
+}
+
+type fakeTx struct{ c *fakeConn }
+
+func (tx fakeTx) Commit() error   { tx.c.record("COMMIT", nil); return nil }
+func (tx fakeTx) Rollback() error { tx.c.record("ROLLBACK", nil); return nil }
+
+type fakeStmt struct {
+	c     *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.record(s.query, args)
+	return driver.RowsAffected(len(s.c.rows)), nil
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.record(s.query, args)
+	return &fakeRows{rows: s.c.rows}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"time", "level", "file", "line", "data", "meta", "tags"}
+}
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestPostgres(t *testing.T) {
+	db, c := fakeDB(t)
+	l, err := New(db, Config{CreateTable: true, Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	e := c.take()
+	if len(e) != 1 || !strings.HasPrefix(e[0].query, "CREATE TABLE IF NOT EXISTS logs (\n\tid BIGSERIAL PRIMARY KEY,") ||
+		!strings.Contains(e[0].query, "meta JSONB,\n\ttags JSONB\n)") {
+		t.Error("Expected the Postgres schema, got", e)
+	}
+
+	now := time.Now()
+	m := &jog.Message{Data: map[string]interface{}{"user": "jack"}, Level: jog.ERROR, File: "main.go", Line: 12, Time: now}
+	m.SetMeta("host", "web1")
+	m.Tags = []string{"api"}
+	plain := &jog.Message{Data: "plain", Level: jog.INFO, Time: now}
+	if _, err := l.LogBatch([]*jog.Message{m, plain}); err != nil {
+		t.Fatal(err)
+	}
+
+	e = c.take()
+	insert := "INSERT INTO logs (time, level, file, line, data, meta, tags) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	if len(e) != 4 || e[0].query != "BEGIN" || e[1].query != insert || e[3].query != "COMMIT" {
+		t.Fatal("Expected the messages inserted in a transaction, got", e)
+	}
+	if a := fmt.Sprint(e[1].args[1:]); a != `[error main.go 12 {"user":"jack"} {"host":"web1"} ["api"]]` {
+		t.Error("Unexpected arguments", a)
+	}
+	if a := e[2].args; a[4] != `"plain"` || a[5] != nil || a[6] != nil {
+		t.Error("Expected NULL meta and tags, got", a)
+	}
+
+	if _, err := New(db, Config{Table: "logs; DROP TABLE logs"}); err == nil {
+		t.Error("Expected an invalid table name to fail")
+	}
+}
+
+func TestSQLite(t *testing.T) {
+	db, c := fakeDB(t)
+	l, err := New(db, Config{Dialect: SQLite, Table: "app.logs", CreateTable: true, MaxRows: 100, Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	e := c.take()
+	if len(e) != 2 || e[0].query != "PRAGMA journal_mode=WAL" ||
+		!strings.HasPrefix(e[1].query, "CREATE TABLE IF NOT EXISTS app.logs (\n\tid INTEGER PRIMARY KEY AUTOINCREMENT,") {
+		t.Error("Expected WAL mode and the SQLite schema, got", e)
+	}
+
+	if _, err := l.LogBatch([]*jog.Message{{Data: "hello", Level: jog.INFO}}); err != nil {
+		t.Fatal(err)
+	}
+	e = c.take()
+	if len(e) != 4 || e[1].query != "INSERT INTO app.logs (time, level, file, line, data, meta, tags) VALUES (?, ?, ?, ?, ?, ?, ?)" {
+		t.Fatal("Expected an insert, got", e)
+	}
+	if p := e[2]; p.query != "DELETE FROM app.logs WHERE id <= (SELECT MAX(id) FROM app.logs) - ?" || p.args[0] != int64(100) {
+		t.Error("Expected the table pruned to MaxRows in the transaction, got", p)
+	}
+
+	before := time.Now()
+	l.Prune(before, 0)
+	if e = c.take(); len(e) != 1 || e[0].query != "DELETE FROM app.logs WHERE time < ?" || e[0].args[0] != before {
+		t.Error("Expected rows before the time deleted, got", e)
+	}
+	if l.Prune(time.Time{}, 0); len(c.take()) != 0 {
+		t.Error("Expected a zero time to prune nothing")
+	}
+}
+
+func TestQuery(t *testing.T) {
+	db, c := fakeDB(t)
+	l, err := New(db, Config{Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	c.take()
+
+	now := time.Now()
+	c.rows = [][]driver.Value{
+		{now, "error", "main.go", int64(12), []byte(`{"user":"jack"}`), []byte(`{"host":"web1"}`), []byte(`["api"]`)},
+		{now, "warning", "main.go", int64(13), []byte(`"plain"`), nil, nil},
+	}
+	msgs, err := l.Query(Query{Level: jog.WARNING, Since: now.Add(-time.Hour), Until: now, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := c.take()
+	q := "SELECT time, level, file, line, data, meta, tags FROM logs WHERE level IN ($1, $2, $3) AND time >= $4 AND time < $5 ORDER BY id DESC LIMIT 10"
+	if len(e) != 1 || e[0].query != q || fmt.Sprint(e[0].args[:3]) != "[warning error critical]" {
+		t.Error("Unexpected query", e)
+	}
+	if len(msgs) != 2 {
+		t.Fatal("Expected 2 messages, got", len(msgs))
+	}
+	if m := msgs[0]; m.Level != jog.ERROR || m.Line != 12 || m.Meta["host"] != "web1" || len(m.Tags) != 1 || m.Data.(map[string]interface{})["user"] != "jack" {
+		t.Errorf("Unexpected message %+v", m)
+	}
+	if m := msgs[1]; m.Data != "plain" || m.Meta != nil || m.Tags != nil {
+		t.Errorf("Unexpected message %+v", m)
+	}
+
+	c.rows = nil
+	l.Query(Query{})
+	if e = c.take(); e[0].query != "SELECT time, level, file, line, data, meta, tags FROM logs ORDER BY id DESC" || len(e[0].args) != 0 {
+		t.Error("Expected an unfiltered query, got", e)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Limits of a single SendMessageBatch call
+const (
+	sqsMaxEntries = 10
+	sqsMaxBytes   = 256 << 10
+)
+
+// SQSConfig holds the settings for an SQS logger
+type SQSConfig struct {
+	// QueueURL, eg. `https://sqs.us-east-1.amazonaws.com/123456789012/logs`
+	QueueURL    string
+	Region      string
+	Credentials AWSCredentials
+	// Endpoint (defaults to `https://sqs.{Region}.amazonaws.com/`)
+	Endpoint string
+	// BatchSize, up to 10, and Interval control how often messages are sent (default 10, 1s).
+	// A BatchSize of 1 sends every message as it's logged.
+	BatchSize int
+	Interval  time.Duration
+	Client    *http.Client
+}
+
+// SQS is a jog.Logger that sends messages to an SQS queue, with the message's
+// level as a `level` message attribute
+type SQS struct {
+	cfg   SQSConfig
+	batch *jog.Batcher
+}
+
+type sqsAttribute struct {
+	DataType    string
+	StringValue string
+}
+
+type sqsEntry struct {
+	Id                string
+	MessageBody       string
+	MessageAttributes map[string]sqsAttribute
+}
+
+// Log queues the message, sending the batch once it's full
+func (s *SQS) Log(m interface{}) (int, error) {
+	return s.batch.Log(m)
+}
+
+// LogBatch sends the messages now, as a whole batch, see jog.BatchLogger
+func (s *SQS) LogBatch(msgs []*jog.Message) (int, error) {
+	return 0, s.write(msgs)
+}
+
+// Flush sends all pending messages
+func (s *SQS) Flush() error {
+	return s.batch.Flush()
+}
+
+// Close sends any pending messages and stops the background flushing
+func (s *SQS) Close() error {
+	return s.batch.Close()
+}
+
+// Splits the messages into calls within the SendMessageBatch limits, skipping any
+// larger than a message may be
+func (s *SQS) write(msgs []*jog.Message) error {
+	var entries []sqsEntry
+	size := 0
+	for i, m := range msgs {
+		b, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		if len(b) > sqsMaxBytes {
+			// Skipped, rather than failing the rest of the batch
+			jog.Diagnose(jog.WARNING, "oversized", map[string]interface{}{"backend": "sqs", "bytes": len(b), "limit": sqsMaxBytes})
+			continue
+		}
+		if len(entries) == sqsMaxEntries || size+len(b) > sqsMaxBytes {
+			if err := s.send(entries); err != nil {
+				return err
+			}
+			entries, size = nil, 0
+		}
+		entries = append(entries, sqsEntry{
+			Id:                strconv.Itoa(i),
+			MessageBody:       string(b),
+			MessageAttributes: map[string]sqsAttribute{"level": {"String", string(m.Level)}},
+		})
+		size += len(b)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return s.send(entries)
+}
+
+func (s *SQS) send(entries []sqsEntry) error {
+	body, err := json.Marshal(struct {
+		QueueUrl string
+		Entries  []sqsEntry
+	}{s.cfg.QueueURL, entries})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", s.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	req.Header.Set("X-Amz-Target", "AmazonSQS.SendMessageBatch")
+	signV4(req, body, "sqs", s.cfg.Region, s.cfg.Credentials, time.Now())
+
+	resp, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received a `%d` from SQS: %s", resp.StatusCode, b)
+	}
+
+	var r struct {
+		Failed []struct{ Id, Code, Message string }
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return err
+	}
+	if n := len(r.Failed); n > 0 {
+		return fmt.Errorf("jog: %d SQS messages failed, last error %s: %s", n, r.Failed[n-1].Code, r.Failed[n-1].Message)
+	}
+	return nil
+}
+
+// NewSQS returns a new SQS logger
+func NewSQS(c SQSConfig) *SQS {
+	if c.Endpoint == "" {
+		c.Endpoint = fmt.Sprintf("https://sqs.%s.amazonaws.com/", c.Region)
+	}
+	if c.BatchSize <= 0 || c.BatchSize > sqsMaxEntries {
+		c.BatchSize = sqsMaxEntries
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	s := &SQS{cfg: c}
+	s.batch = jog.NewBatcher(c.BatchSize, c.Interval, s.write)
+	return s
+}
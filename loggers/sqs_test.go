@@ -0,0 +1,129 @@
+package loggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+func TestSQS(t *testing.T) {
+	creds := AWSCredentials{AccessKey: "AKID", SecretKey: "secret", SessionToken: "token"}
+	var calls []int
+	var first sqsEntry
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			QueueUrl string
+			Entries  []sqsEntry
+		}
+		json.Unmarshal(body, &req)
+		if len(calls) == 0 {
+			first = req.Entries[0]
+		}
+		calls = append(calls, len(req.Entries))
+
+		if r.Header.Get("X-Amz-Target") != "AmazonSQS.SendMessageBatch" || req.QueueUrl != "https://sqs/123/logs" {
+			t.Error("Unexpected request", r.Header.Get("X-Amz-Target"), req.QueueUrl)
+		}
+		// Sign the received request again, it must match the signature sent
+		now, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+		if err != nil {
+			t.Error(err)
+		}
+		check, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), bytes.NewReader(body))
+		for _, k := range []string{"Content-Type", "X-Amz-Target", "X-Amz-Security-Token"} {
+			check.Header.Set(k, r.Header.Get(k))
+		}
+		signV4(check, body, "sqs", "us-east-1", creds, now)
+		auth := r.Header.Get("Authorization")
+		if auth != check.Header.Get("Authorization") {
+			t.Error("Expected a valid signature, got", auth)
+		}
+		if !strings.Contains(auth, "/us-east-1/sqs/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target,") {
+			t.Error("Expected the SQS scope and headers to be signed, got", auth)
+		}
+		fmt.Fprint(w, `{"Successful": []}`)
+	}))
+	defer srv.Close()
+
+	s := NewSQS(SQSConfig{QueueURL: "https://sqs/123/logs", Region: "us-east-1", Credentials: creds, Endpoint: srv.URL, Interval: time.Hour})
+	defer s.Close()
+	msgs := make([]*jog.Message, 12)
+	for i := range msgs {
+		msgs[i] = msg("hello")
+	}
+	msgs[0].Level = jog.ERROR
+	if _, err := s.LogBatch(msgs); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(calls) != "[10 2]" {
+		t.Error("Expected batches of at most 10 messages, got", calls)
+	}
+	var m jog.Message
+	json.Unmarshal([]byte(first.MessageBody), &m)
+	if first.Id != "0" || m.Data != "hello" || first.MessageAttributes["level"] != (sqsAttribute{"String", "error"}) {
+		t.Errorf("Unexpected entry %+v", first)
+	}
+}
+
+func TestSQSErrors(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			http.Error(w, `{"__type": "AccessDenied"}`, status)
+			return
+		}
+		fmt.Fprint(w, `{"Failed": [{"Id": "0", "Code": "InvalidMessageContents", "Message": "bad"}]}`)
+	}))
+	defer srv.Close()
+
+	s := NewSQS(SQSConfig{Region: "us-east-1", Endpoint: srv.URL, Interval: time.Hour})
+	defer s.Close()
+	if _, err := s.LogBatch([]*jog.Message{msg("hello")}); err == nil || !strings.Contains(err.Error(), "InvalidMessageContents") {
+		t.Error("Expected failed entries to be an error, got", err)
+	}
+	status = http.StatusForbidden
+	if _, err := s.LogBatch([]*jog.Message{msg("hello")}); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Error("Expected the response to be an error, got", err)
+	}
+}
+
+func TestSQSOversized(t *testing.T) {
+	var calls []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Entries []sqsEntry }
+		json.NewDecoder(r.Body).Decode(&req)
+		calls = append(calls, len(req.Entries))
+		fmt.Fprint(w, `{"Successful": []}`)
+	}))
+	defer srv.Close()
+	diag := &testLogger{}
+	jog.SetDiagnostics(diag)
+	defer jog.SetDiagnostics(nil)
+
+	s := NewSQS(SQSConfig{Region: "us-east-1", Endpoint: srv.URL, Interval: time.Hour})
+	defer s.Close()
+	msgs := []*jog.Message{msg("one"), msg(strings.Repeat("x", sqsMaxBytes)), msg("three")}
+	if _, err := s.LogBatch(msgs); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(calls) != "[2]" {
+		t.Error("Expected the valid messages to be sent, got", calls)
+	}
+	if d, _ := diag.messages[0].(*jog.Message).Data.(map[string]interface{}); diag.count() != 1 || d["event"] != "oversized" {
+		t.Error("Expected the skipped message to be reported, got", diag.messages)
+	}
+
+	// Nothing is sent when every message is skipped
+	if _, err := s.LogBatch(msgs[1:2]); err != nil || len(calls) != 1 {
+		t.Error("Expected no request, got", calls, err)
+	}
+}
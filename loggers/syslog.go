@@ -0,0 +1,226 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.minty.io/jog"
+)
+
+// SyslogConfig holds the header settings of RFC 5424 syslog messages
+type SyslogConfig struct {
+	// Facility code, eg. 16 (local0), defaults to 1 (user-level)
+	Facility int
+	// Hostname and AppName default to os.Hostname and the executable's name
+	Hostname string
+	AppName  string
+	ProcID   string
+	MsgID    string
+	// SDID names the STRUCTURED-DATA element holding the message's fields.
+	// It defaults to `jog@32473`, 32473 being the enterprise number reserved for
+	// documentation, so replace it with your own.
+	SDID string
+	// MetaSDID names the STRUCTURED-DATA element holding the message's Meta, eg.
+	// its request and trace IDs, and Tags. It defaults to `meta@` and the
+	// enterprise number of SDID.
+	MetaSDID string
+	// OctetCounting frames messages with their length (RFC 6587), as expected by
+	// most TCP receivers, rather than ending them with a newline
+	OctetCounting bool
+}
+
+// Syslog severities of the jog levels
+var syslogSeverity = map[jog.Level]int{
+	jog.CRITICAL: 2,
+	jog.ERROR:    3,
+	jog.WARNING:  4,
+	jog.INFO:     6,
+	jog.DEBUG:    7,
+}
+
+// NewSyslog returns an Encoder producing RFC 5424 messages, with the fields of a
+// message's Data, and its Meta, encoded as STRUCTURED-DATA so receivers can index them.
+// A `message` field, or a string Data, becomes the MSG part.
+// Use it with a Socket, eg. `NewSocket("udp", "localhost:514", NewSyslog(c))`.
+func NewSyslog(c SyslogConfig) jog.Encoder {
+	if c.Facility <= 0 {
+		c.Facility = 1
+	}
+	if c.Hostname == "" {
+		c.Hostname, _ = os.Hostname()
+	}
+	if c.AppName == "" {
+		c.AppName = filepath.Base(os.Args[0])
+	}
+	if c.ProcID == "" {
+		c.ProcID = strconv.Itoa(os.Getpid())
+	}
+	if c.SDID == "" {
+		c.SDID = "jog@32473"
+	}
+	header := fmt.Sprintf("%s %s %s %s",
+		syslogHeader(c.Hostname, 255), syslogHeader(c.AppName, 48),
+		syslogHeader(c.ProcID, 128), syslogHeader(c.MsgID, 32))
+	if c.MetaSDID == "" {
+		c.MetaSDID = "meta"
+		if i := strings.IndexByte(c.SDID, '@'); i >= 0 {
+			c.MetaSDID += c.SDID[i:]
+		}
+	}
+	sdid, metaID := syslogName(c.SDID), syslogName(c.MetaSDID)
+
+	return jog.EncoderFunc(func(m *jog.Message) ([]byte, error) {
+		sev, ok := syslogSeverity[m.Level]
+		if !ok {
+			sev = 5
+		}
+		var b bytes.Buffer
+		fmt.Fprintf(&b, "<%d>1 %s %s ", c.Facility*8+sev,
+			m.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"), header)
+
+		msg, fields, err := syslogFields(m)
+		if err != nil {
+			return nil, err
+		}
+		meta := map[string]string{}
+		for k, v := range m.Meta {
+			syslogFlatten(meta, k, v)
+		}
+		if len(m.Tags) > 0 {
+			meta["tags"] = strings.Join(m.Tags, ",")
+		}
+		if len(fields) == 0 && len(meta) == 0 {
+			b.WriteByte('-')
+		}
+		syslogElement(&b, sdid, fields)
+		syslogElement(&b, metaID, meta)
+		if msg != "" {
+			b.WriteString(" " + msg)
+		}
+
+		if c.OctetCounting {
+			return append([]byte(strconv.Itoa(b.Len())+" "), b.Bytes()...), nil
+		}
+		b.WriteByte('\n')
+		return b.Bytes(), nil
+	})
+}
+
+// Splits Data into the MSG and the SD-PARAMs, nested fields being named by their path
+func syslogFields(msg *jog.Message) (string, map[string]string, error) {
+	b, err := msg.DataJSON()
+	if err != nil {
+		return "", nil, err
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return "", nil, err
+	}
+	if s, ok := v.(string); ok {
+		return s, nil, nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return string(b), nil, nil
+	}
+
+	text, _ := m["message"].(string)
+	if text != "" {
+		delete(m, "message")
+	}
+	fields := map[string]string{}
+	syslogFlatten(fields, "", m)
+	return text, fields, nil
+}
+
+// Adds the value to the SD-PARAMs, nested fields being named by their path
+func syslogFlatten(fields map[string]string, prefix string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			syslogFlatten(fields, k, e)
+		}
+	case string:
+		fields[syslogName(prefix)] = v
+	case nil:
+		fields[syslogName(prefix)] = ""
+	case []interface{}:
+		b, _ := json.Marshal(v)
+		fields[syslogName(prefix)] = string(b)
+	default:
+		fields[syslogName(prefix)] = fmt.Sprint(v)
+	}
+}
+
+// Writes an SD-ELEMENT of the params, sorted by name, unless there are none
+func syslogElement(b *bytes.Buffer, id string, params map[string]string) {
+	if len(params) == 0 {
+		return
+	}
+	b.WriteString("[" + id)
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(b, ` %s="%s"`, k, syslogEscape(params[k]))
+	}
+	b.WriteByte(']')
+}
+
+// Makes a valid SD-NAME; printable ASCII, except `=`, ` `, `]` and `"`, of at most 32 chars
+func syslogName(s string) string {
+	n := []byte(s)
+	for i, c := range n {
+		if c <= 32 || c >= 127 || c == '=' || c == ']' || c == '"' {
+			n[i] = '_'
+		}
+	}
+	if len(n) > 32 {
+		n = n[:32]
+	}
+	if len(n) == 0 {
+		return "_"
+	}
+	return string(n)
+}
+
+var syslogEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// Escapes a PARAM-VALUE
+func syslogEscape(s string) string {
+	return syslogEscaper.Replace(s)
+}
+
+// Makes a valid header field; printable ASCII, or the NILVALUE when empty
+func syslogHeader(s string, max int) string {
+	h := []byte(s)
+	for i, c := range h {
+		if c <= 32 || c >= 127 {
+			h[i] = '_'
+		}
+	}
+	if len(h) > max {
+		h = h[:max]
+	}
+	if len(h) == 0 {
+		return "-"
+	}
+	return string(h)
+}
@@ -0,0 +1,41 @@
+package loggers
+
+import (
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+func TestSyslog(t *testing.T) {
+	enc := NewSyslog(SyslogConfig{Facility: 16, Hostname: "web1", AppName: "api", ProcID: "42", MsgID: "req"})
+	m := msg(map[string]interface{}{
+		"message": "request failed",
+		"user":    map[string]interface{}{"name": `bo"b]`},
+		"status":  502,
+	})
+	m.Level = jog.ERROR
+	m.Time = time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+	b, err := enc.Encode(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `<131>1 2024-01-02T03:04:05.000006Z web1 api 42 req [jog@32473 status="502" user.name="bo\"b\]"] request failed` + "\n"
+	if string(b) != exp {
+		t.Errorf("Expected\n%s\ngot\n%s", exp, b)
+	}
+
+	m = msg("hello")
+	m.SetMeta("request_id", "abc")
+	m.Tag("api", "slow")
+	exp = `<134>1 0001-01-01T00:00:00.000000Z web1 api 42 req [meta@32473 request_id="abc" tags="api,slow"] hello` + "\n"
+	if b, _ = enc.Encode(m); string(b) != exp {
+		t.Errorf("Expected\n%s\ngot\n%s", exp, b)
+	}
+
+	enc = NewSyslog(SyslogConfig{Hostname: "web1", AppName: "api", ProcID: "42", OctetCounting: true})
+	if b, _ = enc.Encode(msg("hello")); string(b) != "55 <14>1 0001-01-01T00:00:00.000000Z web1 api 42 - - hello" {
+		t.Errorf("Unexpected framed message %q", b)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"code.minty.io/jog"
+)
+
+// Tail is a jog.Logger that streams messages to HTTP clients as Server-Sent Events.
+// It implements http.Handler so it can be mounted on an existing mux, eg.
+//
+//	t := loggers.NewTail(100)
+//	http.Handle("/debug/logs", t)
+//
+// New clients first receive the recent messages, then live messages as they're logged.
+// A `level` query parameter limits the stream to messages at, or above, that level,
+// an unknown level being a `400 Bad Request`.
+type Tail struct {
+	ring *RingBuffer
+	mu   sync.Mutex
+	subs map[chan *jog.Message]struct{}
+}
+
+// Log records the message and sends it to every connected client.
+// Clients that can't keep up miss messages rather than blocking the caller.
+func (t *Tail) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return 0, nil
+	}
+	t.mu.Lock()
+	// The replay buffer, and each client, get their own copy, as they're encoded
+	// after Log returns
+	t.ring.Log(msg.Clone())
+	for c := range t.subs {
+		select {
+		case c <- msg.Clone():
+		default:
+		}
+	}
+	t.mu.Unlock()
+	return 0, nil
+}
+
+// ServeHTTP streams recent, then live, messages to the client until it disconnects
+func (t *Tail) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	var level jog.Level
+	if s := r.FormValue("level"); s != "" {
+		if level = jog.ParseLevel(s); level == jog.UNKNOWN {
+			http.Error(w, fmt.Sprintf("unknown level %q", s), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	c := make(chan *jog.Message, 64)
+	t.mu.Lock()
+	recent := t.ring.Dump()
+	t.subs[c] = struct{}{}
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.subs, c)
+		t.mu.Unlock()
+	}()
+
+	send := func(m *jog.Message) error {
+		if level != "" && !m.Level.AtLeast(level) {
+			return nil
+		}
+		b, err := json.Marshal(m)
+		if err != nil {
+			return nil
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", m.Level, b); err != nil {
+			return err
+		}
+		f.Flush()
+		return nil
+	}
+
+	for _, m := range recent {
+		if send(m) != nil {
+			return
+		}
+	}
+	f.Flush()
+	for {
+		select {
+		case m := <-c:
+			if send(m) != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// NewTail returns a new Tail that replays the last `recent` messages to new clients
+func NewTail(recent int) *Tail {
+	return &Tail{
+		ring: Ring(recent),
+		subs: make(map[chan *jog.Message]struct{}),
+	}
+}
@@ -0,0 +1,118 @@
+package loggers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Connects to the tail, returning a func reading the next event's level and data
+func tailEvents(t *testing.T, ctx context.Context, url string) func() (string, string) {
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatal("Expected an event stream, got", resp.StatusCode, ct)
+	}
+	s := bufio.NewScanner(resp.Body)
+	return func() (event, data string) {
+		for s.Scan() {
+			switch line := s.Text(); {
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				var m jog.Message
+				json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &m)
+				data, _ = m.Data.(string)
+			case line == "":
+				return
+			}
+		}
+		return
+	}
+}
+
+// Serves a new Tail, closed after the test's clients
+func tailServer(t *testing.T) (*Tail, *httptest.Server) {
+	tail := NewTail(10)
+	srv := httptest.NewServer(tail)
+	t.Cleanup(srv.Close)
+	return tail, srv
+}
+
+func TestTail(t *testing.T) {
+	tail, srv := tailServer(t)
+
+	tail.Log(msg("before"))
+	next := tailEvents(t, context.Background(), srv.URL)
+	if e, d := next(); e != "info" || d != "before" {
+		t.Error("Expected the recent message, got", e, d)
+	}
+
+	m := msg("live")
+	tail.Log(m)
+	// The logged message may be reused once Log returns
+	m.Data = "reused"
+	if e, d := next(); e != "info" || d != "live" {
+		t.Error("Expected the live message, got", e, d)
+	}
+}
+
+func TestTailLevel(t *testing.T) {
+	tail, srv := tailServer(t)
+
+	next := tailEvents(t, context.Background(), srv.URL+"?level=warn")
+	tail.Log(msg("skipped"))
+	m := msg("kept")
+	m.Level = jog.ERROR
+	tail.Log(m)
+	if e, d := next(); e != "error" || d != "kept" {
+		t.Error("Expected only the error, got", e, d)
+	}
+
+	resp, err := http.Get(srv.URL + "?level=loud")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Error("Expected a 400 for an unknown level, got", resp.StatusCode)
+	}
+}
+
+func TestTailDisconnect(t *testing.T) {
+	tail, srv := tailServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tailEvents(t, ctx, srv.URL)
+	tail.mu.Lock()
+	n := len(tail.subs)
+	tail.mu.Unlock()
+	if n != 1 {
+		t.Fatal("Expected a subscriber, got", n)
+	}
+
+	cancel()
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(5 * time.Millisecond) {
+		tail.mu.Lock()
+		n = len(tail.subs)
+		tail.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the subscriber to be removed on disconnect")
+		}
+	}
+	tail.Log(msg("after"))
+}
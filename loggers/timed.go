@@ -0,0 +1,49 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Timed is a jog.Logger that tracks the latency of another Logger's Log, and
+// LogBatch, calls, so a degrading collector is noticed before it stalls the app
+type Timed struct {
+	logger jog.Logger
+	log    jog.Latency
+	batch  jog.Latency
+}
+
+// Log logs the message, timing the call
+func (t *Timed) Log(m interface{}) (int, error) {
+	defer t.log.Since(time.Now())
+	return t.logger.Log(m)
+}
+
+// LogBatch logs the messages, timing the call, see jog.LogBatch
+func (t *Timed) LogBatch(msgs []*jog.Message) (int, error) {
+	defer t.batch.Since(time.Now())
+	return jog.LogBatch(t.logger, msgs)
+}
+
+// Latency returns the percentiles of the Log, and LogBatch, call durations
+func (t *Timed) Latency() (log, batch jog.LatencyStats) {
+	return t.log.Stats(), t.batch.Stats()
+}
+
+// Close closes the wrapped Logger, when it can be
+func (t *Timed) Close() error {
+	if c, ok := t.logger.(interface{ Close() error }); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// NewTimed returns a new Timed logger wrapping `l`
+func NewTimed(l jog.Logger) *Timed {
+	return &Timed{logger: l}
+}
@@ -0,0 +1,39 @@
+package loggers
+
+import (
+	"testing"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Takes `delay` to log each message
+type slowLogger struct {
+	testLogger
+	delay time.Duration
+}
+
+func (l *slowLogger) Log(m interface{}) (int, error) {
+	time.Sleep(l.delay)
+	return l.testLogger.Log(m)
+}
+
+func TestTimed(t *testing.T) {
+	l := &slowLogger{delay: 5 * time.Millisecond}
+	timed := NewTimed(l)
+	for i := 0; i < 3; i++ {
+		timed.Log(msg(i))
+	}
+	timed.LogBatch([]*jog.Message{msg(3), msg(4)})
+
+	log, batch := timed.Latency()
+	if log.Count != 3 || log.P50 < 5*time.Millisecond || log.Max < 5*time.Millisecond {
+		t.Errorf("Expected 3 calls of at least 5ms, got %+v", log)
+	}
+	if batch.Count != 1 || batch.Max < 10*time.Millisecond {
+		t.Errorf("Expected a batch of at least 10ms, got %+v", batch)
+	}
+	if n := l.count(); n != 5 {
+		t.Error("Expected every message to be logged, got", n)
+	}
+}
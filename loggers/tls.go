@@ -0,0 +1,52 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+)
+
+// TLSConfig holds the TLS settings used by the HTTP and socket loggers
+type TLSConfig struct {
+	// CertFile and KeyFile hold the client certificate, for mutual TLS
+	CertFile string
+	KeyFile  string
+	// CAFile holds the certificates used to verify the server, instead of the system pool
+	CAFile string
+	// ServerName verified against the server's certificate, when it differs from the host
+	ServerName string
+	// InsecureSkipVerify disables all verification of the server, for testing only
+	InsecureSkipVerify bool
+}
+
+// Load builds a tls.Config from the settings
+func (c TLSConfig) Load() (*tls.Config, error) {
+	t := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		t.Certificates = []tls.Certificate{cert}
+	}
+	if c.CAFile != "" {
+		b, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		t.RootCAs = x509.NewCertPool()
+		if !t.RootCAs.AppendCertsFromPEM(b) {
+			return nil, errors.New("jog: no certificates found in " + c.CAFile)
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,41 @@
+package loggers
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTLSConfigCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	dir, err := os.MkdirTemp("", "jog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ca := filepath.Join(dir, "ca.crt")
+	b := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(ca, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := TLSConfig{CAFile: ca, ServerName: "example.com"}.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: c}}
+	if _, err := client.Get(srv.URL); err != nil {
+		t.Error("Expected server to be verified by the CA file, got", err)
+	}
+
+	c, _ = TLSConfig{}.Load()
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: c}}
+	if _, err := client.Get(srv.URL); err == nil {
+		t.Error("Expected server to fail verification against the system pool")
+	}
+}
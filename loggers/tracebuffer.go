@@ -0,0 +1,161 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"sync"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// TraceBufferConfig holds the settings for a TraceBuffer
+type TraceBufferConfig struct {
+	// Buffer is the level below which messages are buffered (defaults to jog.WARNING)
+	Buffer jog.Level
+	// Trigger is the level at which a trace's buffered messages are logged
+	// (defaults to jog.ERROR)
+	Trigger jog.Level
+	// MaxMessages buffered per trace, older messages are dropped (defaults to 256)
+	MaxMessages int
+	// MaxAge after which an idle trace's messages are discarded (defaults to 1m)
+	MaxAge time.Duration
+	// End, when set, reports whether a message ends its trace, eg. httplog's
+	// access log message, discarding the buffered messages
+	End func(*jog.Message) bool
+}
+
+type traceBuf struct {
+	msgs []*jog.Message
+	last time.Time
+}
+
+// TraceBuffer is a jog.Logger that holds back low level messages of each trace, or
+// request, and logs them only when the trace has an error, giving the full context
+// of failures without logging everything. A message's trace is its Meta `trace_id`,
+// or `request_id`, see jog.LogContext; messages without either are logged as is.
+type TraceBuffer struct {
+	logger jog.Logger
+	cfg    TraceBufferConfig
+	mu     sync.Mutex
+	traces map[string]*traceBuf
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// Log buffers the message, or logs it, along with its trace's buffered messages
+func (t *TraceBuffer) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return t.logger.Log(m)
+	}
+	id := traceKey(msg)
+	if id == "" {
+		return t.logger.Log(m)
+	}
+
+	t.mu.Lock()
+	b := t.traces[id]
+	switch {
+	case !msg.Level.AtLeast(t.cfg.Buffer):
+		if b == nil {
+			b = &traceBuf{}
+			t.traces[id] = b
+		}
+		if len(b.msgs) == t.cfg.MaxMessages {
+			b.msgs = b.msgs[1:]
+		}
+		b.msgs = append(b.msgs, msg)
+		b.last = time.Now()
+		if t.cfg.End != nil && t.cfg.End(msg) {
+			delete(t.traces, id)
+		}
+		t.mu.Unlock()
+		return 0, nil
+	case msg.Level.AtLeast(t.cfg.Trigger):
+		delete(t.traces, id)
+	case t.cfg.End != nil && t.cfg.End(msg):
+		delete(t.traces, id)
+		b = nil
+	default:
+		b = nil
+	}
+	t.mu.Unlock()
+
+	if b != nil {
+		if _, err := jog.LogBatch(t.logger, b.msgs); err != nil {
+			return 0, err
+		}
+	}
+	return t.logger.Log(msg)
+}
+
+// End discards the buffered messages of the trace, eg. once its request succeeded
+func (t *TraceBuffer) End(id string) {
+	t.mu.Lock()
+	delete(t.traces, id)
+	t.mu.Unlock()
+}
+
+// Close stops discarding idle traces
+func (t *TraceBuffer) Close() error {
+	close(t.stop)
+	<-t.done
+	return nil
+}
+
+// Returns the trace, or request, ID of the message
+func traceKey(m *jog.Message) string {
+	if id, _ := m.Meta["trace_id"].(string); id != "" {
+		return id
+	}
+	id, _ := m.Meta["request_id"].(string)
+	return id
+}
+
+func (t *TraceBuffer) run() {
+	defer close(t.done)
+	tick := time.NewTicker(t.cfg.MaxAge / 2)
+	defer tick.Stop()
+	for {
+		select {
+		case now := <-tick.C:
+			t.mu.Lock()
+			for id, b := range t.traces {
+				if now.Sub(b.last) > t.cfg.MaxAge {
+					delete(t.traces, id)
+				}
+			}
+			t.mu.Unlock()
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// NewTraceBuffer returns a new TraceBuffer logging to `l`
+func NewTraceBuffer(l jog.Logger, c TraceBufferConfig) *TraceBuffer {
+	if c.Buffer == "" {
+		c.Buffer = jog.WARNING
+	}
+	if c.Trigger == "" {
+		c.Trigger = jog.ERROR
+	}
+	if c.MaxMessages <= 0 {
+		c.MaxMessages = 256
+	}
+	if c.MaxAge <= 0 {
+		c.MaxAge = time.Minute
+	}
+	t := &TraceBuffer{
+		logger: l,
+		cfg:    c,
+		traces: make(map[string]*traceBuf),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
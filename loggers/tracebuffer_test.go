@@ -0,0 +1,37 @@
+package loggers
+
+import (
+	"context"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestTraceBuffer(t *testing.T) {
+	l := &testLogger{}
+	b := NewTraceBuffer(l, TraceBufferConfig{})
+	defer b.Close()
+	j := jog.New(b, jog.WithLevel(jog.DEBUG))
+
+	ok := jog.ContextWithRequestID(context.Background(), "ok")
+	j.LogContext(ok, jog.DEBUG, "cache miss")
+	j.LogContext(ok, jog.INFO, "done")
+	b.End("ok")
+
+	failed := jog.ContextWithRequestID(context.Background(), "failed")
+	j.LogContext(failed, jog.DEBUG, "cache miss")
+	j.LogContext(failed, jog.INFO, "querying")
+	j.Info("unrelated")
+	if n := l.count(); n != 1 {
+		t.Fatal("Expected only the untraced message to be logged, got", n)
+	}
+	j.LogContext(failed, jog.ERROR, "query failed")
+
+	var got []interface{}
+	for _, m := range l.messages {
+		got = append(got, m.(*jog.Message).Data)
+	}
+	if len(got) != 4 || got[1] != "cache miss" || got[2] != "querying" || got[3] != "query failed" {
+		t.Error("Expected the failed request's messages, got", got)
+	}
+}
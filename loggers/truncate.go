@@ -0,0 +1,131 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"code.minty.io/jog"
+)
+
+type truncate struct {
+	logger jog.Logger
+	max    int
+}
+
+// Log truncates the message when its encoding is larger than the limit
+func (t *truncate) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return t.logger.Log(m)
+	}
+	b, err := json.Marshal(msg)
+	if err != nil || len(b) <= t.max {
+		return t.logger.Log(m)
+	}
+
+	// Data is kept as a string, either as is or as its JSON encoding
+	s, ok := msg.Data.(string)
+	if !ok {
+		d, err := msg.DataJSON()
+		if err != nil {
+			d, _ = json.Marshal(fmt.Sprint(msg.Data))
+		}
+		s = string(d)
+	}
+
+	c := *msg
+	c.Data, c.Truncated, c.Length = "", true, len(s)
+	if b, _ = json.Marshal(&c); len(b) > t.max {
+		t.shrink(&c)
+	}
+	t.fit(&c, s, func(v string) { c.Data = v })
+	return t.logger.Log(&c)
+}
+
+// Sets, with `set`, the longest prefix of `s` the message fits with
+func (t *truncate) fit(m *jog.Message, s string, set func(string)) {
+	set("")
+	b, _ := json.Marshal(m)
+	room := t.max - len(b)
+
+	// Escaping can grow the string, so shrink until it fits
+	for room > 0 {
+		set(cut(s, room))
+		b, _ = json.Marshal(m)
+		if len(b) <= t.max {
+			return
+		}
+		room -= len(b) - t.max
+	}
+	set("")
+}
+
+// Shrinks the rest of a message without Data to fit, dropping its Raw line, cutting
+// its stack, then dropping its largest Meta fields
+func (t *truncate) shrink(m *jog.Message) {
+	over := func() int {
+		b, _ := json.Marshal(m)
+		return len(b) - t.max
+	}
+	m.Raw = ""
+	if m.Stack != "" && over() > 0 {
+		t.fit(m, m.Stack, func(v string) { m.Stack = v })
+	}
+	for len(m.Frames) > 0 && over() > 0 {
+		m.Frames = m.Frames[:len(m.Frames)-1]
+	}
+	if len(m.Meta) == 0 || over() <= 0 {
+		return
+	}
+
+	// Meta is shared with the original message, so it's trimmed in a copy
+	sizes := make(map[string]int, len(m.Meta))
+	keys := make([]string, 0, len(m.Meta))
+	meta := make(map[string]interface{}, len(m.Meta))
+	for k, v := range m.Meta {
+		b, _ := json.Marshal(v)
+		sizes[k], meta[k] = len(k)+len(b), v
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if sizes[keys[i]] != sizes[keys[j]] {
+			return sizes[keys[i]] > sizes[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	m.Meta = meta
+	for _, k := range keys {
+		if over() <= 0 {
+			break
+		}
+		delete(meta, k)
+	}
+	if len(meta) == 0 {
+		m.Meta = nil
+	}
+}
+
+// Cuts the string to at most `n` bytes without splitting a rune
+func cut(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// Truncate returns a jog.Logger that limits the encoded size of each message to
+// `max` bytes before passing it to `l`. Data is cut first, and when that isn't
+// enough the message's Raw line is dropped, its stack cut, then its largest Meta
+// fields dropped.
+func Truncate(l jog.Logger, max int) jog.Logger {
+	return &truncate{l, max}
+}
@@ -0,0 +1,62 @@
+package loggers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestTruncate(t *testing.T) {
+	l := &testLogger{}
+	tr := Truncate(l, 200)
+
+	tr.Log(msg("small"))
+	tr.Log(msg(strings.Repeat("é\"", 500)))
+	tr.Log(msg(map[string]interface{}{"body": strings.Repeat("x", 1000)}))
+
+	for i, v := range l.messages {
+		m := v.(*jog.Message)
+		b, _ := json.Marshal(m)
+		if len(b) > 200 {
+			t.Errorf("Expected message %d to fit in 200 bytes, got %d", i, len(b))
+		}
+		if i > 0 && (!m.Truncated || m.Length < 1000) {
+			t.Errorf("Expected message %d to be marked truncated, got %v %d", i, m.Truncated, m.Length)
+		}
+	}
+	if l.messages[0].(*jog.Message).Truncated {
+		t.Error("Expected small message to be left alone")
+	}
+}
+
+func TestTruncateMetaAndStack(t *testing.T) {
+	l := &testLogger{}
+	tr := Truncate(l, 300)
+
+	m := msg("hello")
+	m.Stack = strings.Repeat("main.main()\n\t/app/main.go:12\n", 100)
+	tr.Log(m)
+	m = msg("hello")
+	m.SetMeta("request_id", "abc")
+	m.SetMeta("headers", strings.Repeat("x", 1000))
+	tr.Log(m)
+
+	for i, v := range l.messages {
+		b, _ := json.Marshal(v)
+		if len(b) > 300 {
+			t.Errorf("Expected message %d to fit in 300 bytes, got %d", i, len(b))
+		}
+	}
+	if s := l.messages[0].(*jog.Message).Stack; !strings.HasPrefix(s, "main.main()\n\t/app/main.go:12\n") {
+		t.Error("Expected the top of the stack to be kept, got", s)
+	}
+	c := l.messages[1].(*jog.Message)
+	if c.Meta["request_id"] != "abc" || c.Meta["headers"] != nil {
+		t.Error("Expected the largest Meta field to be dropped, got", c.Meta)
+	}
+	if m.Meta["headers"] == nil {
+		t.Error("Expected the original message's Meta to be left alone")
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"strings"
+
+	"code.minty.io/jog"
+)
+
+// UserAgent is a parsed User-Agent header
+type UserAgent struct {
+	Browser        string `json:"browser,omitempty"`
+	BrowserVersion string `json:"browser_version,omitempty"`
+	OS             string `json:"os,omitempty"`
+	OSVersion      string `json:"os_version,omitempty"`
+	// Device is `desktop`, `mobile`, `tablet` or `bot`
+	Device string `json:"device"`
+}
+
+// Browser tokens, in the order they're checked, as some browsers also claim to be
+// those they're derived from
+var uaBrowsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"FxiOS/", "Firefox"},
+	{"Firefox/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Version/", "Safari"},
+	{"MSIE ", "Internet Explorer"},
+	{"Trident/", "Internet Explorer"},
+}
+
+var uaBots = []string{"bot", "crawler", "spider", "slurp", "curl/", "wget/", "python-requests/", "go-http-client/"}
+
+// ParseUserAgent parses the browser, OS, and device class from a User-Agent, by
+// the tokens of common browsers. Unrecognized values are left empty.
+func ParseUserAgent(s string) UserAgent {
+	ua := UserAgent{Device: "desktop"}
+	lower := strings.ToLower(s)
+	for _, b := range uaBots {
+		if strings.Contains(lower, b) {
+			ua.Device = "bot"
+			ua.Browser, ua.BrowserVersion = uaProduct(s)
+			return ua
+		}
+	}
+
+	for _, b := range uaBrowsers {
+		if i := strings.Index(s, b.token); i >= 0 {
+			ua.Browser = b.name
+			ua.BrowserVersion = uaVersion(s[i+len(b.token):])
+			if b.token == "Trident/" {
+				ua.BrowserVersion = ""
+				if j := strings.Index(s, "rv:"); j >= 0 {
+					ua.BrowserVersion = uaVersion(s[j+3:])
+				}
+			}
+			break
+		}
+	}
+
+	switch {
+	case strings.Contains(s, "Windows NT "):
+		ua.OS = "Windows"
+		ua.OSVersion = windowsVersions[uaVersion(s[strings.Index(s, "Windows NT ")+11:])]
+	case strings.Contains(s, "iPhone OS "), strings.Contains(s, "CPU OS "):
+		ua.OS = "iOS"
+		i := strings.Index(s, " OS ")
+		ua.OSVersion = strings.Replace(uaVersion(s[i+4:]), "_", ".", -1)
+	case strings.Contains(s, "Android"):
+		ua.OS = "Android"
+		if i := strings.Index(s, "Android "); i >= 0 {
+			ua.OSVersion = uaVersion(s[i+8:])
+		}
+	case strings.Contains(s, "Mac OS X"):
+		ua.OS = "macOS"
+		if i := strings.Index(s, "Mac OS X "); i >= 0 {
+			ua.OSVersion = strings.Replace(uaVersion(s[i+9:]), "_", ".", -1)
+		}
+	case strings.Contains(s, "CrOS"):
+		ua.OS = "ChromeOS"
+	case strings.Contains(s, "Linux"):
+		ua.OS = "Linux"
+	}
+
+	switch {
+	case strings.Contains(s, "iPad"), strings.Contains(s, "Tablet"),
+		ua.OS == "Android" && !strings.Contains(s, "Mobile"):
+		ua.Device = "tablet"
+	case strings.Contains(s, "Mobile"), strings.Contains(s, "iPhone"):
+		ua.Device = "mobile"
+	}
+	return ua
+}
+
+var windowsVersions = map[string]string{
+	"10.0": "10", "6.3": "8.1", "6.2": "8", "6.1": "7", "6.0": "Vista", "5.1": "XP",
+}
+
+// Returns the leading version, eg. `120.0.1` of `120.0.1 Safari/537.36`
+func uaVersion(s string) string {
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.' && r != '_'
+	})
+	if end < 0 {
+		end = len(s)
+	}
+	return strings.Trim(s[:end], "._")
+}
+
+// Returns the product of a bot's User-Agent, eg. `Googlebot` and `2.1` of
+// `Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)`
+func uaProduct(s string) (string, string) {
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ';' || r == '(' || r == ')' }) {
+		p := strings.SplitN(f, "/", 2)
+		if len(p) == 2 && p[0] != "Mozilla" && p[0] != "compatible" {
+			return p[0], uaVersion(p[1])
+		}
+	}
+	return "", ""
+}
+
+type userAgents struct {
+	logger jog.Logger
+	fields []string
+}
+
+func (u *userAgents) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return u.logger.Log(m)
+	}
+	d, ok := dataMap(msg)
+	if !ok {
+		return u.logger.Log(m)
+	}
+	var found bool
+	for _, f := range u.fields {
+		if s, _ := d[f].(string); s != "" {
+			d[f+"_parsed"] = ParseUserAgent(s)
+			found = true
+		}
+	}
+	if found {
+		msg.Data = d
+	}
+	return u.logger.Log(msg)
+}
+
+// ParseUserAgents returns a jog.Logger that parses the User-Agent Data fields, eg.
+// httplog's `user_agent`, adding the browser, OS, and device as `<field>_parsed`
+// before passing the message to `l`
+func ParseUserAgents(l jog.Logger, fields ...string) jog.Logger {
+	return &userAgents{l, fields}
+}
@@ -0,0 +1,33 @@
+package loggers
+
+import (
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := map[string]UserAgent{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.109 Safari/537.36":                      {"Chrome", "120.0.6099.109", "Windows", "10", "desktop"},
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.61":         {"Edge", "120.0.2210.61", "Windows", "10", "desktop"},
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1": {"Safari", "17.1", "iOS", "17.1.2", "mobile"},
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:121.0) Gecko/20100101 Firefox/121.0":                                                      {"Firefox", "121.0", "macOS", "10.15", "desktop"},
+		"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36":                            {"Chrome", "119.0.0.0", "Android", "13", "tablet"},
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)":                                                                  {"Googlebot", "2.1", "", "", "bot"},
+		"curl/8.4.0": {"curl", "8.4.0", "", "", "bot"},
+	}
+	for s, want := range tests {
+		if ua := ParseUserAgent(s); ua != want {
+			t.Errorf("%s\n\tgot %+v\n\twant %+v", s, ua, want)
+		}
+	}
+}
+
+func TestParseUserAgents(t *testing.T) {
+	l := &testLogger{}
+	ParseUserAgents(l, "user_agent").Log(msg(map[string]interface{}{"user_agent": "curl/8.4.0"}))
+	d := l.messages[0].(*jog.Message).Data.(map[string]interface{})
+	if ua, ok := d["user_agent_parsed"].(UserAgent); !ok || ua.Device != "bot" {
+		t.Error("Expected the user agent to be parsed, got", d)
+	}
+}
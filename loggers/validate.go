@@ -0,0 +1,129 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loggers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.minty.io/jog"
+)
+
+// Field types of a Schema
+const (
+	TypeString = "string"
+	TypeNumber = "number"
+	TypeBool   = "bool"
+	TypeObject = "object"
+	TypeArray  = "array"
+	TypeAny    = ""
+)
+
+// Field describes a field of a message's JSON form
+type Field struct {
+	// Type of the field's value, eg. TypeString (TypeAny allows any type)
+	Type     string
+	Required bool
+}
+
+// Schema maps the dot separated paths of fields, within the JSON form of a message,
+// to their description, eg. `{"data.user_id": {Type: loggers.TypeNumber, Required: true}}`
+type Schema map[string]Field
+
+// ValidationError describes a field that doesn't match a Schema
+type ValidationError struct {
+	Path   string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("jog: invalid field `%s`: %s", e.Path, e.Reason)
+}
+
+// Check validates the JSON form of a message, returning a *ValidationError for
+// the first field, by path, that doesn't match
+func (s Schema) Check(m interface{}) error {
+	o, err := decodeJSON(m)
+	if err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(s))
+	for p := range s {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		f := s[p]
+		v, ok := lookup(o, p)
+		if !ok {
+			if f.Required {
+				return &ValidationError{p, "missing"}
+			}
+			continue
+		}
+		if t := jsonType(v); f.Type != TypeAny && t != f.Type {
+			return &ValidationError{p, fmt.Sprintf("expected %s, got %s", f.Type, t)}
+		}
+	}
+	return nil
+}
+
+// The value at a dot separated path
+func lookup(v interface{}, path string) (interface{}, bool) {
+	for _, k := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[k]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return TypeString
+	case json.Number:
+		return TypeNumber
+	case bool:
+		return TypeBool
+	case map[string]interface{}:
+		return TypeObject
+	case []interface{}:
+		return TypeArray
+	}
+	return "null"
+}
+
+type validator struct {
+	logger  jog.Logger
+	schema  Schema
+	invalid func(m interface{}, err error)
+}
+
+// Logs the message when it's valid, otherwise passes it to the error handler
+func (v *validator) Log(m interface{}) (int, error) {
+	if err := v.schema.Check(m); err != nil {
+		if v.invalid == nil {
+			return 0, err
+		}
+		v.invalid(m, err)
+		return 0, nil
+	}
+	return v.logger.Log(m)
+}
+
+// Validate returns a jog.Logger that only logs messages matching the schema to `l`.
+// Invalid messages are passed to `invalid`, eg. to send them to a dead letter
+// logger, or when nil, are dropped and the error returned.
+func Validate(l jog.Logger, s Schema, invalid func(m interface{}, err error)) jog.Logger {
+	return &validator{l, s, invalid}
+}
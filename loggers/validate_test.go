@@ -0,0 +1,39 @@
+package loggers
+
+import (
+	"errors"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+func TestValidate(t *testing.T) {
+	s := Schema{
+		"level":        {Type: TypeString, Required: true},
+		"data.user_id": {Type: TypeNumber, Required: true},
+		"data.tags":    {Type: TypeArray},
+	}
+	l := &testLogger{}
+	var rejected []error
+	v := Validate(l, s, func(m interface{}, err error) {
+		rejected = append(rejected, err)
+	})
+
+	v.Log(msg(map[string]interface{}{"user_id": 1, "tags": []string{"a"}}))
+	v.Log(msg(map[string]interface{}{"user_id": "1"}))
+	v.Log(msg("no user"))
+	if l.count() != 1 || len(rejected) != 2 {
+		t.Fatal("Expected 1 message logged and 2 rejected, got", l.count(), rejected)
+	}
+	var ve *ValidationError
+	if !errors.As(rejected[0], &ve) || ve.Path != "data.user_id" || ve.Reason != "expected number, got string" {
+		t.Error("Unexpected error", rejected[0])
+	}
+
+	if _, err := Validate(l, s, nil).Log(&jog.Message{Data: map[string]interface{}{"user_id": 1}}); err != nil {
+		t.Error("Expected empty level to be a string, got", err)
+	}
+	if _, err := Validate(l, s, nil).Log(msg(nil)); !errors.As(err, &ve) {
+		t.Error("Expected an error without a handler, got", err)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package zmq contains a jog.Logger that publishes messages on a ZeroMQ PUB socket,
+// fanning them out to any number of live subscribers without a broker. It's
+// binding agnostic, a pebbe/zmq4 socket is a Publisher as is
+//
+//	sock, _ := zmq4.NewSocket(zmq4.PUB)
+//	sock.Bind("tcp://*:5556")
+//	j := jog.New(zmq.New(sock, zmq.Config{}))
+//
+// Each message is sent as two frames, the topic and the encoded message, and the
+// topic ends with the level so subscribers can filter on it, eg. a SUB socket
+// subscribed to `jog.error` only receives errors.
+package zmq
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"code.minty.io/jog"
+)
+
+// Publisher is implemented by the application's ZeroMQ PUB socket
+type Publisher interface {
+	SendMessage(parts ...interface{}) (int, error)
+}
+
+// Config holds the settings for a Logger
+type Config struct {
+	// Prefix of the topics, the level being appended (defaults to `jog.`)
+	Prefix string
+	// Encoder of the messages (defaults to jog.JSON)
+	Encoder jog.Encoder
+}
+
+// Logger is a jog.Logger publishing messages on a PUB socket
+type Logger struct {
+	mu     sync.Mutex
+	pub    Publisher
+	prefix string
+	enc    jog.Encoder
+}
+
+// Topic returns the topic messages of the level are published on
+func (l *Logger) Topic(level jog.Level) string {
+	return l.prefix + string(level)
+}
+
+// Log publishes the message. Like any PUB socket, messages without a subscriber
+// are discarded.
+func (l *Logger) Log(m interface{}) (int, error) {
+	msg, ok := m.(*jog.Message)
+	if !ok {
+		return 0, fmt.Errorf("jog: unexpected message type %T", m)
+	}
+	b, err := l.enc.Encode(msg)
+	if err != nil {
+		return 0, err
+	}
+	b = bytes.TrimSuffix(b, []byte("\n"))
+
+	// Sockets aren't safe for concurrent use
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pub.SendMessage(l.Topic(msg.Level), b)
+}
+
+// Close closes the socket, when it's an io.Closer
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if c, ok := l.pub.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// New returns a new Logger publishing on `p`
+func New(p Publisher, c Config) *Logger {
+	if c.Prefix == "" {
+		c.Prefix = "jog."
+	}
+	if c.Encoder == nil {
+		c.Encoder = jog.JSON
+	}
+	return &Logger{pub: p, prefix: c.Prefix, enc: c.Encoder}
+}
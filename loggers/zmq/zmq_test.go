@@ -0,0 +1,67 @@
+package zmq
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"code.minty.io/jog"
+)
+
+// Records the frames of each message sent
+type testSocket struct {
+	frames [][]interface{}
+	closed bool
+	err    error
+}
+
+func (s *testSocket) SendMessage(parts ...interface{}) (int, error) {
+	s.frames = append(s.frames, parts)
+	return len(parts), s.err
+}
+
+func (s *testSocket) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestLogger(t *testing.T) {
+	s := &testSocket{}
+	l := New(s, Config{})
+	if _, err := l.Log(&jog.Message{Data: "hello", Level: jog.ERROR}); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.frames) != 1 || len(s.frames[0]) != 2 {
+		t.Fatal("Expected a topic and message frame, got", s.frames)
+	}
+	if topic := s.frames[0][0]; topic != "jog.error" {
+		t.Error("Expected the level's topic, got", topic)
+	}
+	b, _ := s.frames[0][1].([]byte)
+	var m jog.Message
+	if err := json.Unmarshal(b, &m); err != nil || m.Data != "hello" || b[len(b)-1] == '\n' {
+		t.Errorf("Expected the JSON message without a newline, got %q", b)
+	}
+
+	if err := l.Close(); err != nil || !s.closed {
+		t.Error("Expected the socket to be closed")
+	}
+}
+
+func TestLoggerConfig(t *testing.T) {
+	s := &testSocket{}
+	enc := jog.EncoderFunc(func(m *jog.Message) ([]byte, error) { return []byte(m.Data.(string)), nil })
+	l := New(s, Config{Prefix: "app/", Encoder: enc})
+	l.Log(&jog.Message{Data: "hello", Level: jog.INFO})
+	if f := s.frames[0]; f[0] != "app/info" || string(f[1].([]byte)) != "hello" {
+		t.Error("Expected the configured topic and encoding, got", f)
+	}
+
+	if _, err := l.Log("raw"); err == nil {
+		t.Error("Expected a non-message to fail")
+	}
+	s.err = errors.New("closed")
+	if _, err := l.Log(&jog.Message{Data: "hello"}); err != s.err {
+		t.Error("Expected the socket's error, got", err)
+	}
+}
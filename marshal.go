@@ -0,0 +1,348 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MarshalPolicy decides how Data is encoded. Strings are always encoded as
+// they are, and nil as `null`. Cycles, maps with keys JSON doesn't support, and
+// values it can't encode, are encoded with placeholders, eg. `"[cycle via *main.Node]"`,
+// and stringified keys, rather than failing, see WithEncodeErrors.
+type MarshalPolicy int
+
+const (
+	// Heuristic marshals Data to JSON, falling back to its fmt.Sprint string when
+	// marshaling fails or produces fewer than 3 bytes (eg. `{}`, or a number).
+	// It's the default, kept for compatibility.
+	Heuristic MarshalPolicy = iota
+	// JSONFirst marshals Data to JSON, only falling back to its fmt.Sprint string
+	// when marshaling fails. Numbers stay numbers, and empty structs are `{}`.
+	JSONFirst
+	// StringerFirst encodes Data implementing fmt.Stringer, or encoding.TextMarshaler,
+	// as its text, and anything else as JSONFirst does
+	StringerFirst
+	// Strict marshals Data to JSON and fails when it can't be marshaled, or when a
+	// struct with fields encodes as `{}` because none are exported
+	Strict
+)
+
+// WithMarshalPolicy sets how the Data of messages is encoded, see MarshalPolicy
+func WithMarshalPolicy(p MarshalPolicy) Option {
+	return func(j *Jog) {
+		j.policy = p
+	}
+}
+
+// WithEncodeErrors makes Data holding cycles, maps with keys JSON doesn't support,
+// or values it can't encode, such as funcs and NaN, fail to encode with an
+// EncodeError naming where, instead of being encoded with placeholders. It's
+// meant for development, to catch them early. The Strict policy implies it.
+func WithEncodeErrors() Option {
+	return func(j *Jog) {
+		j.loud = true
+	}
+}
+
+// EncodeError reports Data that can't be encoded as JSON, see WithEncodeErrors
+type EncodeError struct {
+	// Path to the value within Data, eg. `data.children[0].parent`
+	Path   string
+	Reason string
+}
+
+func (e *EncodeError) Error() string {
+	return "jog: " + e.Reason + " at " + e.Path
+}
+
+// ErrorData is the form errors are logged in, as json.Marshal encodes most error
+// types as `{}`
+type ErrorData struct {
+	Error string `json:"error"`
+	Type  string `json:"type"`
+	// Stack is the detailed form of errors that carry one, such as those of
+	// github.com/pkg/errors, as printed by `%+v`
+	Stack string `json:"stack,omitempty"`
+}
+
+func errorData(err error) ErrorData {
+	e := ErrorData{Error: err.Error(), Type: fmt.Sprintf("%T", err)}
+	if s := fmt.Sprintf("%+v", err); s != e.Error {
+		e.Stack = s
+	}
+	return e
+}
+
+// DataJSON returns the JSON form of Data, according to the message's MarshalPolicy.
+// Errors, unless they marshal themselves, are encoded as ErrorData.
+func (m *Message) DataJSON() ([]byte, error) {
+	switch d := m.Data.(type) {
+	case nil:
+		return []byte("null"), nil
+	case string:
+		return json.Marshal(d)
+	case *LazyValue:
+		return json.Marshal(d)
+	case json.Marshaler:
+		// Errors that marshal themselves are left to the policy
+	case error:
+		return json.Marshal(errorData(d))
+	}
+
+	var err error
+	switch m.policy {
+	case JSONFirst:
+		var b []byte
+		if b, err = m.marshal(); err == nil {
+			return b, nil
+		}
+	case StringerFirst:
+		switch d := m.Data.(type) {
+		case fmt.Stringer:
+			return json.Marshal(d.String())
+		case encoding.TextMarshaler:
+			t, err := d.MarshalText()
+			if err != nil {
+				return nil, err
+			}
+			return json.Marshal(string(t))
+		}
+		var b []byte
+		if b, err = m.marshal(); err == nil {
+			return b, nil
+		}
+	case Strict:
+		b, err := m.marshal()
+		if err != nil {
+			return nil, err
+		}
+		if string(b) == "{}" {
+			v := reflect.Indirect(reflect.ValueOf(m.Data))
+			if v.Kind() == reflect.Struct && v.NumField() > 0 {
+				return nil, fmt.Errorf("jog: %T has no exported fields", m.Data)
+			}
+		}
+		return b, nil
+	default:
+		var b []byte
+		if b, err = m.marshal(); err == nil && len(b) >= 3 {
+			return b, nil
+		}
+	}
+	// Errors asked for by WithEncodeErrors aren't covered up
+	var e *EncodeError
+	if errors.As(err, &e) {
+		return nil, err
+	}
+	return json.Marshal(fmt.Sprint(m.Data))
+}
+
+// Marshals Data, encoding it with placeholders, or failing with an EncodeError
+// (see WithEncodeErrors), when json.Marshal fails on a cycle, or an unsupported
+// type or value
+func (m *Message) marshal() ([]byte, error) {
+	b, err := json.Marshal(m.Data)
+	var typeErr *json.UnsupportedTypeError
+	var valueErr *json.UnsupportedValueError
+	if err == nil || (!errors.As(err, &typeErr) && !errors.As(err, &valueErr)) {
+		return b, err
+	}
+	e := &encodable{seen: map[interface{}]bool{}, loud: m.loud || m.policy == Strict}
+	v, err := e.value(reflect.ValueOf(m.Data), "data")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+var (
+	jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Converts values to a form json.Marshal accepts, following json.Marshal's rules
+// for structs, and keeping the pointers, maps and slices of the current path to
+// detect cycles
+type encodable struct {
+	seen map[interface{}]bool
+	loud bool
+}
+
+// Returns the placeholder of an unsupported value, or an EncodeError when loud
+func (e *encodable) unsupported(path, reason string) (interface{}, error) {
+	if e.loud {
+		return nil, &EncodeError{path, reason}
+	}
+	return "[" + reason + "]", nil
+}
+
+func (e *encodable) value(v reflect.Value, path string) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+	if v.Kind() != reflect.Interface && (v.Type().Implements(jsonMarshaler) || v.Type().Implements(textMarshaler)) {
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return e.unsupported(path, err.Error())
+		}
+		return json.RawMessage(b), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		return e.value(v.Elem(), path)
+	case reflect.Ptr:
+		return e.visit(v.Pointer(), v, path, func() (interface{}, error) {
+			return e.value(v.Elem(), path)
+		})
+	case reflect.Map:
+		return e.visit(v.Pointer(), v, path, func() (interface{}, error) {
+			return e.mapValue(v, path)
+		})
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), nil
+		}
+		key := struct {
+			ptr uintptr
+			len int
+		}{v.Pointer(), v.Len()}
+		return e.visit(key, v, path, func() (interface{}, error) {
+			return e.elems(v, path)
+		})
+	case reflect.Array:
+		return e.elems(v, path)
+	case reflect.Struct:
+		out := map[string]interface{}{}
+		return out, e.fields(out, v, path)
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return e.unsupported(path, "unsupported value "+strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return e.unsupported(path, "unsupported type "+v.Type().String())
+	}
+	return v.Interface(), nil
+}
+
+// Converts the value of `fn`, unless `key` is already on the path, being a cycle
+func (e *encodable) visit(key interface{}, v reflect.Value, path string, fn func() (interface{}, error)) (interface{}, error) {
+	if e.seen[key] {
+		return e.unsupported(path, "cycle via "+v.Type().String())
+	}
+	e.seen[key] = true
+	defer delete(e.seen, key)
+	return fn()
+}
+
+func (e *encodable) elems(v reflect.Value, path string) (interface{}, error) {
+	out := make([]interface{}, v.Len())
+	for i := range out {
+		var err error
+		if out[i], err = e.value(v.Index(i), path+"["+strconv.Itoa(i)+"]"); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Converts a map, keys JSON doesn't support being formatted with fmt.Sprint
+func (e *encodable) mapValue(v reflect.Value, path string) (interface{}, error) {
+	out := make(map[string]interface{}, v.Len())
+	it := v.MapRange()
+	for it.Next() {
+		k := it.Key()
+		var key string
+		switch {
+		case k.Kind() == reflect.String:
+			key = k.String()
+		case k.Type().Implements(textMarshaler):
+			t, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, err
+			}
+			key = string(t)
+		case k.Kind() >= reflect.Int && k.Kind() <= reflect.Uintptr:
+			key = fmt.Sprint(k.Interface())
+		case e.loud:
+			return nil, &EncodeError{path, "unsupported map key type " + k.Type().String()}
+		default:
+			key = fmt.Sprint(k.Interface())
+		}
+		val, err := e.value(it.Value(), path+"["+strconv.Quote(key)+"]")
+		if err != nil {
+			return nil, err
+		}
+		out[key] = val
+	}
+	return out, nil
+}
+
+// Adds the fields of a struct to `out`, named by their json tags, as json.Marshal
+// does, embedded structs' fields being promoted unless `out` has them
+func (e *encodable) fields(out map[string]interface{}, v reflect.Value, path string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || f.PkgPath != "" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+		if f.Anonymous && name == "" {
+			ev := reflect.Indirect(fv)
+			if ev.Kind() == reflect.Struct {
+				embedded := map[string]interface{}{}
+				if err := e.fields(embedded, ev, path); err != nil {
+					return err
+				}
+				for k, ev := range embedded {
+					if _, ok := out[k]; !ok {
+						out[k] = ev
+					}
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") && empty(fv) {
+			continue
+		}
+		val, err := e.value(fv, path+"."+name)
+		if err != nil {
+			return err
+		}
+		out[name] = val
+	}
+	return nil
+}
+
+// Whether the value is omitted by `omitempty`
+func empty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Struct:
+		return false
+	}
+	return v.IsZero()
+}
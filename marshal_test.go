@@ -0,0 +1,141 @@
+package jog
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"testing"
+)
+
+func TestMarshalPolicy(t *testing.T) {
+	tests := []struct {
+		policy   MarshalPolicy
+		data     interface{}
+		expected string
+	}{
+		{Heuristic, 42, `"42"`},
+		{Heuristic, dummy1{"blah"}, `"{blah}"`},
+		{JSONFirst, 42, `42`},
+		{JSONFirst, dummy1{"blah"}, `{}`},
+		{JSONFirst, dummy2{dummy1{"blah"}}, `{}`},
+		{StringerFirst, dummy2{dummy1{"blah"}}, `"dummy2"`},
+		{StringerFirst, net.ParseIP("10.0.0.1"), `"10.0.0.1"`},
+		{StringerFirst, map[string]int{"a": 1}, `{"a":1}`},
+		{Strict, bench{"Jack", 1}, `{"name":"Jack","count":1}`},
+		{Strict, struct{}{}, `{}`},
+	}
+	for _, v := range tests {
+		m := &Message{Data: v.data, policy: v.policy}
+		b, err := m.DataJSON()
+		if err != nil || string(b) != v.expected {
+			t.Errorf("Policy %d: expected %s got %s %v", v.policy, v.expected, b, err)
+		}
+	}
+
+	for _, d := range []interface{}{dummy1{"blah"}, make(chan int)} {
+		if _, err := (&Message{Data: d, policy: Strict}).DataJSON(); err == nil {
+			t.Errorf("Expected %T to fail with Strict", d)
+		}
+	}
+
+	l := &testLogger{}
+	New(l, WithMarshalPolicy(JSONFirst)).Info(42)
+	if b, _ := l.message.DataJSON(); string(b) != "42" {
+		t.Error("Expected the option to set the policy, got", string(b))
+	}
+}
+
+type stackError struct{}
+
+func (stackError) Error() string { return "boom" }
+
+func (e stackError) Format(s fmt.State, verb rune) {
+	if s.Flag('+') {
+		fmt.Fprint(s, "boom\nmain.go:12")
+		return
+	}
+	fmt.Fprint(s, "boom")
+}
+
+func TestErrorData(t *testing.T) {
+	tests := []struct {
+		data     interface{}
+		expected string
+	}{
+		{errors.New("not found"), `{"error":"not found","type":"*errors.errorString"}`},
+		{fmt.Errorf("open: %w", os.ErrNotExist), `{"error":"open: file does not exist","type":"*fmt.wrapError"}`},
+		{stackError{}, `{"error":"boom","type":"jog.stackError","stack":"boom\nmain.go:12"}`},
+	}
+	for _, v := range tests {
+		b, err := (&Message{Data: v.data}).DataJSON()
+		if err != nil || string(b) != v.expected {
+			t.Errorf("Expected %s got %s %v", v.expected, b, err)
+		}
+	}
+
+	m := &Message{Data: errors.New("denied")}
+	m.Set("user", "bob")
+	if s := fmt.Sprint(m.Data); s != "map[error:denied type:*errors.errorString user:bob]" {
+		t.Error("Expected error fields to merge, got", s)
+	}
+}
+
+type node struct {
+	Name     string  `json:"name"`
+	Parent   *node   `json:"parent,omitempty"`
+	Children []*node `json:"children,omitempty"`
+}
+
+type point struct{ X, Y int }
+
+func TestMarshalUnsupported(t *testing.T) {
+	root := &node{Name: "root"}
+	root.Children = []*node{{Name: "child", Parent: root}}
+	self := map[string]interface{}{"a": 1}
+	self["self"] = self
+
+	tests := []struct {
+		data     interface{}
+		expected string
+	}{
+		{root, `{"children":[{"name":"child","parent":"[cycle via *jog.node]"}],"name":"root"}`},
+		{self, `{"a":1,"self":"[cycle via map[string]interface {}]"}`},
+		{map[point]string{{1, 2}: "a"}, `{"{1 2}":"a"}`},
+		{map[interface{}]interface{}{"a": 1, 2: []interface{}{true}}, `{"2":[true],"a":1}`},
+		{map[string]interface{}{"fn": func() {}, "nan": math.NaN()}, `{"fn":"[unsupported type func()]","nan":"[unsupported value NaN]"}`},
+	}
+	for _, v := range tests {
+		for _, p := range []MarshalPolicy{Heuristic, JSONFirst} {
+			b, err := (&Message{Data: v.data, policy: p}).DataJSON()
+			if err != nil || string(b) != v.expected {
+				t.Errorf("Policy %d: expected %s got %s %v", p, v.expected, b, err)
+			}
+		}
+	}
+
+	for _, v := range []struct {
+		data interface{}
+		err  string
+	}{
+		{root, "jog: cycle via *jog.node at data.children[0].parent"},
+		{map[point]string{{1, 2}: "a"}, "jog: unsupported map key type jog.point at data"},
+		{[]float64{1, math.Inf(1)}, "jog: unsupported value +Inf at data[1]"},
+	} {
+		_, err := (&Message{Data: v.data, loud: true}).DataJSON()
+		var e *EncodeError
+		if !errors.As(err, &e) || err.Error() != v.err {
+			t.Errorf("Expected %q, got %v", v.err, err)
+		}
+		if _, err := (&Message{Data: v.data, policy: Strict}).DataJSON(); err == nil {
+			t.Errorf("Expected %T to fail with Strict", v.data)
+		}
+	}
+
+	l := &testLogger{}
+	New(l, WithEncodeErrors()).Info(root)
+	if _, err := l.message.DataJSON(); err == nil {
+		t.Error("Expected the option to fail encoding")
+	}
+}
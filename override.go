@@ -0,0 +1,106 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type override struct {
+	level Level
+}
+
+// Level overrides of the Jogs sharing a New, by name, the last of each being in effect
+type overrides struct {
+	sync.RWMutex
+	n      int32
+	levels map[string][]*override
+}
+
+func newOverrides() *overrides {
+	return &overrides{levels: map[string][]*override{}}
+}
+
+// WithTemporaryLevel overrides the minimum Level of the named logger (see Named),
+// and of those named below it, until `undo` is called, eg. to turn on DEBUG for a
+// single component while investigating. Overrides stack, undoing one restores the
+// one before it, and undo may be called more than once.
+// Overrides are scoped to the Jogs derived, by Named, from the same New, so other
+// instances aren't affected; overriding an unnamed Jog covers all its named ones.
+func (j *Jog) WithTemporaryLevel(l Level) (undo func()) {
+	o := &override{l}
+	name, set := j.name, j.override
+	set.Lock()
+	set.levels[name] = append(set.levels[name], o)
+	atomic.StoreInt32(&set.n, int32(len(set.levels)))
+	set.Unlock()
+
+	return func() {
+		set.Lock()
+		defer set.Unlock()
+		ls := set.levels[name]
+		for i, e := range ls {
+			if e == o {
+				ls = append(ls[:i:i], ls[i+1:]...)
+				break
+			}
+		}
+		if len(ls) == 0 {
+			delete(set.levels, name)
+		} else {
+			set.levels[name] = ls
+		}
+		atomic.StoreInt32(&set.n, int32(len(set.levels)))
+	}
+}
+
+// DebugFor logs the named logger at DEBUG for `d`, reverting automatically, see
+// WithTemporaryLevel. The returned undo reverts early.
+func (j *Jog) DebugFor(d time.Duration) (undo func()) {
+	u := j.WithTemporaryLevel(DEBUG)
+	t := time.AfterFunc(d, u)
+	return func() {
+		t.Stop()
+		u()
+	}
+}
+
+// Returns the overriding level of the named logger, if any
+func (o *overrides) level(name string) (Level, bool) {
+	if atomic.LoadInt32(&o.n) == 0 {
+		return "", false
+	}
+	o.RLock()
+	defer o.RUnlock()
+	for {
+		if ls, ok := o.levels[name]; ok {
+			return ls[len(ls)-1].level, true
+		}
+		if name == "" {
+			return "", false
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			name = ""
+		} else {
+			name = name[:i]
+		}
+	}
+}
+
+// Overrides returns the level overrides in effect for the Jog, and those sharing its
+// New, by logger name, see WithTemporaryLevel
+func (j *Jog) Overrides() map[string]Level {
+	j.override.RLock()
+	defer j.override.RUnlock()
+	levels := make(map[string]Level, len(j.override.levels))
+	for name, ls := range j.override.levels {
+		levels[name] = ls[len(ls)-1].level
+	}
+	return levels
+}
@@ -0,0 +1,85 @@
+package jog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTemporaryLevel(t *testing.T) {
+	j := New(&testLogger{}, WithLevel(WARNING))
+	db := j.Named("db")
+	pool := db.Named("pool")
+
+	undo := db.WithTemporaryLevel(DEBUG)
+	if !db.Enabled(DEBUG) || !pool.Enabled(DEBUG) || j.Enabled(DEBUG) {
+		t.Error("Expected DEBUG to be enabled for db and below only")
+	}
+	inner := pool.WithTemporaryLevel(ERROR)
+	if pool.Enabled(WARNING) || !db.Enabled(DEBUG) {
+		t.Error("Expected the more specific override to apply")
+	}
+	undo()
+	undo()
+	if db.Enabled(INFO) || !db.Enabled(WARNING) || pool.Enabled(WARNING) {
+		t.Error("Expected db to revert to its level")
+	}
+	inner()
+	if pool.Enabled(INFO) {
+		t.Error("Expected pool to revert to its level")
+	}
+}
+
+func TestTemporaryLevelStack(t *testing.T) {
+	j := New(&testLogger{}, WithLevel(ERROR)).Named("stack")
+	a := j.WithTemporaryLevel(INFO)
+	b := j.WithTemporaryLevel(DEBUG)
+	a()
+	if !j.Enabled(DEBUG) {
+		t.Error("Expected the latest override to remain")
+	}
+	b()
+	if j.Enabled(WARNING) {
+		t.Error("Expected all overrides to be undone")
+	}
+}
+
+func TestDebugFor(t *testing.T) {
+	j := New(&testLogger{}, WithLevel(INFO)).Named("brief")
+	j.DebugFor(20 * time.Millisecond)
+	if !j.Enabled(DEBUG) {
+		t.Error("Expected DEBUG to be enabled")
+	}
+	deadline := time.Now().Add(time.Second)
+	for j.Enabled(DEBUG) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if j.Enabled(DEBUG) {
+		t.Error("Expected DEBUG to revert")
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	j := New(&testLogger{}, WithLevel(WARNING)).Named("listed")
+	undo := j.WithTemporaryLevel(DEBUG)
+	if j.Overrides()["listed"] != DEBUG || j.Level() != DEBUG {
+		t.Error("Expected the override to be listed, got", j.Overrides())
+	}
+	undo()
+	if _, ok := j.Overrides()["listed"]; ok || j.Level() != WARNING {
+		t.Error("Expected the override to be removed")
+	}
+}
+
+func TestTemporaryLevelScope(t *testing.T) {
+	a := New(&testLogger{}, WithLevel(WARNING))
+	b := New(&testLogger{}, WithLevel(ERROR))
+
+	undo := a.WithTemporaryLevel(DEBUG)
+	defer undo()
+	if !a.Enabled(DEBUG) || !a.Named("db").Enabled(DEBUG) {
+		t.Error("Expected DEBUG for the Jog and its named loggers")
+	}
+	if b.Enabled(WARNING) || b.Named("db").Enabled(WARNING) || len(b.Overrides()) != 0 {
+		t.Error("Expected another Jog to keep its level")
+	}
+}
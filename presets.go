@@ -0,0 +1,36 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import "os"
+
+// NewProduction returns a Jog suited to production, logging to `l`; INFO and
+// above, stacks on errors, Data encoded as JSON, and the binary's build information.
+// The options are applied after the preset's, so they may override it.
+func NewProduction(l Logger, opts ...Option) *Jog {
+	preset := []Option{
+		WithLevel(INFO),
+		WithStacktrace(ERROR),
+		WithMarshalPolicy(JSONFirst),
+		WithBuildInfo(),
+	}
+	return New(l, append(preset, opts...)...)
+}
+
+// NewDevelopment returns a Jog suited to development, writing readable, and
+// colored when on a terminal, output to stderr; everything down to DEBUG, stacks
+// on warnings, Stringers encoded as text, short function names, and Data that
+// can't be encoded reported rather than quietly replaced, see WithEncodeErrors.
+// The options are applied after the preset's, so they may override it.
+func NewDevelopment(opts ...Option) *Jog {
+	preset := []Option{
+		WithLevel(DEBUG),
+		WithStacktrace(WARNING),
+		WithMarshalPolicy(StringerFirst),
+		WithShortFuncs(),
+		WithEncodeErrors(),
+	}
+	return New(NewConsole(os.Stderr, ConsoleConfig{}), append(preset, opts...)...)
+}
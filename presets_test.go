@@ -0,0 +1,42 @@
+package jog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewProduction(t *testing.T) {
+	l := &testLogger{}
+	j := NewProduction(l)
+	j.Debug("dropped")
+	if l.message != nil {
+		t.Error("Expected DEBUG to be dropped, got", l.message)
+	}
+
+	j.Info("started")
+	if l.message.Stack != "" || l.message.Meta["build"] == nil {
+		t.Errorf("Expected build info and no stack, got %+v", l.message)
+	}
+	j.Error("failed")
+	if !strings.HasPrefix(l.message.Stack, "code.minty.io/jog.TestNewProduction\n\t") {
+		t.Error("Expected the stack from the caller, got", l.message.Stack)
+	}
+
+	NewProduction(l, WithLevel(DEBUG)).Debug("kept")
+	if l.message.Data != "kept" {
+		t.Error("Expected options to override the preset, got", l.message.Data)
+	}
+}
+
+func TestNewDevelopment(t *testing.T) {
+	j := NewDevelopment()
+	if !j.Enabled(DEBUG) {
+		t.Error("Expected DEBUG to be enabled")
+	}
+	if _, ok := j.logger.(*Console); !ok {
+		t.Errorf("Expected a Console logger, got %T", j.logger)
+	}
+	if !j.loud {
+		t.Error("Expected encode errors to be reported")
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// ReadError reports a line of a stream that couldn't be decoded
+type ReadError struct {
+	Line int
+	Err  error
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("jog: line %d: %s", e.Line, e.Err)
+}
+
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// Reader decodes a stream of newline delimited JSON messages, such as a file
+// written by the file logger, see Decode
+type Reader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// Next returns the next message of the stream, or io.EOF at its end.
+// A line that can't be decoded returns a *ReadError, and reading may continue.
+func (r *Reader) Next() (*Message, error) {
+	for r.s.Scan() {
+		r.line++
+		b := bytes.TrimSpace(r.s.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		m, err := Decode(b)
+		if err != nil {
+			return nil, &ReadError{r.line, err}
+		}
+		return m, nil
+	}
+	if err := r.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// NewReader returns a new Reader decoding messages from `r`
+func NewReader(r io.Reader) *Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 16<<20)
+	return &Reader{s: s}
+}
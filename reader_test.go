@@ -0,0 +1,54 @@
+package jog
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReader(t *testing.T) {
+	in := `{"data":"one","level":"WARN","timestamp":"2013-06-01T00:00:00.5Z"}
+
+not json
+{"data":"two","level":"error","timestamp":1370044800123}
+{"data":"three","timestamp":"1370044800123456789"}
+{"data":"four","level":"fatal","timestamp":1370044800.250}
+`
+	r := NewReader(strings.NewReader(in))
+	var got []*Message
+	var rerr *ReadError
+	for {
+		m, err := r.Next()
+		if err == io.EOF {
+			break
+		} else if errors.As(err, &rerr) {
+			continue
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, m)
+	}
+
+	if rerr == nil || rerr.Line != 3 {
+		t.Error("Expected an error on line 3, got", rerr)
+	}
+	expected := []struct {
+		level Level
+		time  time.Time
+	}{
+		{WARNING, time.Date(2013, 6, 1, 0, 0, 0, 5e8, time.UTC)},
+		{ERROR, time.Date(2013, 6, 1, 0, 0, 0, 123e6, time.UTC)},
+		{INFO, time.Date(2013, 6, 1, 0, 0, 0, 123456789, time.UTC)},
+		{CRITICAL, time.Date(2013, 6, 1, 0, 0, 0, 250e6, time.UTC)},
+	}
+	if len(got) != len(expected) {
+		t.Fatal("Expected", len(expected), "messages, got", len(got))
+	}
+	for i, e := range expected {
+		if got[i].Level != e.level || !got[i].Time.Equal(e.time) {
+			t.Errorf("Expected %s %s, got %s %s", e.level, e.time, got[i].Level, got[i].Time)
+		}
+	}
+}
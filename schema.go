@@ -0,0 +1,121 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the Message wire format written by this package.
+//
+//	1  data, level, file, line and timestamp; the original format
+//	2  adds schema_version, func, stack, meta, tags, raw, truncated and length
+const SchemaVersion = 2
+
+// NewVersioned returns an Encoder producing newline delimited JSON in the given
+// version of the wire format, for collectors that haven't been upgraded.
+// Version 1 folds Meta into Data, where older enrichment used to put it.
+func NewVersioned(version int) (Encoder, error) {
+	switch version {
+	case 1:
+		return EncoderFunc(func(m *Message) ([]byte, error) {
+			v1 := &Message{Data: m.Data, Level: m.Level, File: m.File, Line: m.Line, Time: m.Time}
+			v1.Merge(m.Meta)
+			d, err := v1.DataJSON()
+			if err != nil {
+				return nil, err
+			}
+			b, err := json.Marshal(struct {
+				Data  json.RawMessage `json:"data"`
+				Level Level           `json:"level"`
+				File  string          `json:"file"`
+				Line  int             `json:"line"`
+				Time  time.Time       `json:"timestamp"`
+			}{d, v1.Level, v1.File, v1.Line, v1.Time})
+			if err != nil {
+				return nil, err
+			}
+			return append(b, '\n'), nil
+		}), nil
+	case SchemaVersion:
+		return EncoderFunc(func(m *Message) ([]byte, error) {
+			if m.Version != SchemaVersion {
+				c := *m
+				c.Version = SchemaVersion
+				m = &c
+			}
+			return JSON.Encode(m)
+		}), nil
+	}
+	return nil, fmt.Errorf("jog: unknown schema version %d", version)
+}
+
+// Decode parses an encoded (JSON) message of any version into a Message.
+// Numbers in Data and Meta are kept as json.Number, and the timestamp may be in
+// any of the TimeFormats. A message without a `schema_version` is version 1, and
+// versions newer than SchemaVersion are decoded as far as their fields are known.
+func Decode(b []byte) (*Message, error) {
+	m := new(Message)
+	w := struct {
+		*message
+		Time json.RawMessage `json:"timestamp"`
+	}{message: (*message)(m)}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&w); err != nil {
+		return nil, err
+	}
+	if len(w.Time) > 0 && string(w.Time) != "null" {
+		t, err := parseTime(w.Time)
+		if err != nil {
+			return nil, err
+		}
+		m.Time = t
+	}
+	m.Level = ParseLevel(string(m.Level))
+	if m.Version == 0 {
+		m.Version = 1
+	}
+	return m, nil
+}
+
+// Parses a timestamp written in any of the TimeFormats
+func parseTime(b []byte) (time.Time, error) {
+	var s string
+	if b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return time.Time{}, err
+		}
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t, nil
+		}
+	} else {
+		s = string(b)
+	}
+
+	// Epoch seconds have a fraction, strings of digits are nanoseconds (UnixNanoString)
+	// and other numbers milliseconds (EpochMillis)
+	if strings.Contains(s, ".") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("jog: invalid timestamp %s", b)
+		}
+		return time.Unix(0, int64(math.Round(f*1e3))*1e6).UTC(), nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("jog: invalid timestamp %s", b)
+	}
+	if b[0] == '"' {
+		return time.Unix(0, n).UTC(), nil
+	}
+	return time.Unix(0, n*1e6).UTC(), nil
+}
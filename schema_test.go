@@ -0,0 +1,45 @@
+package jog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestVersioned(t *testing.T) {
+	m := &Message{Data: "hi", Level: INFO, File: "a.go", Line: 1, Time: time.Date(2013, 6, 1, 0, 0, 0, 0, time.UTC)}
+	m.SetMeta("host", "web1")
+
+	v1, _ := NewVersioned(1)
+	b, err := v1.Encode(m)
+	expected := `{"data":{"host":"web1","message":"hi"},"level":"info","file":"a.go","line":1,"timestamp":"2013-06-01T00:00:00Z"}` + "\n"
+	if err != nil || string(b) != expected {
+		t.Error("Expected", expected, "got", string(b), err)
+	}
+
+	v2, _ := NewVersioned(2)
+	b, err = v2.Encode(m)
+	expected = `{"data":"hi","level":"info","file":"a.go","line":1,"timestamp":"2013-06-01T00:00:00Z","schema_version":2,"meta":{"host":"web1"}}` + "\n"
+	if err != nil || string(b) != expected {
+		t.Error("Expected", expected, "got", string(b), err)
+	}
+
+	if _, err := NewVersioned(9); err == nil {
+		t.Error("Expected an unknown version to fail")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	m, err := Decode([]byte(`{"data":{"id":12345678901234567890},"level":"error","file":"a.go","line":3,"timestamp":"2013-06-01T00:00:00Z"}`))
+	if err != nil || m.Version != 1 || m.Level != ERROR {
+		t.Fatal("Expected a version 1 message, got", m, err)
+	}
+	if id := m.Data.(map[string]interface{})["id"]; id != json.Number("12345678901234567890") {
+		t.Error("Expected the ID to keep its precision, got", id)
+	}
+
+	m, err = Decode([]byte(`{"data":"x","level":"info","schema_version":3,"future":true,"meta":{"env":"prod"}}`))
+	if err != nil || m.Version != 3 || m.Meta["env"] != "prod" {
+		t.Error("Expected a newer version to decode its known fields, got", m, err)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package server contains an http.Handler that accepts messages POSTed by jog's
+// HTTP loggers, such as the basic logger, and passes them on to a jog.Logger;
+// the building block of a collector.
+//
+//	f, _ := loggers.NewFile(loggers.FileConfig{Path: "/var/log/collected.log"})
+//	http.Handle("/logs/", server.New(server.Config{Logger: f}))
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"code.minty.io/jog"
+)
+
+// Config holds the settings for a Handler
+type Config struct {
+	// Logger the accepted messages are passed to
+	Logger jog.Logger
+	// MaxBytes of a request's body (defaults to 1MB)
+	MaxBytes int64
+	// Validate, when set, checks each message in addition to the built-in
+	// checks, eg. a loggers.Schema's Check
+	Validate func(*jog.Message) error
+}
+
+// Handler accepts POSTed messages. A body holds either a single JSON message, or
+// newline delimited messages, and may be compressed with a gzip or deflate
+// Content-Encoding. The last element of the request's path, being the
+// name the basic logger posts to, is added to each message's Meta as `app`.
+//
+// A request whose messages were all logged gets a 204, an invalid message fails
+// the whole request with a 400 before anything is logged, and a failure of the
+// Logger gets a 502.
+type Handler struct {
+	cfg Config
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := decompress(r.Header.Get("Content-Encoding"), http.MaxBytesReader(w, r.Body, h.cfg.MaxBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	msgs, err := h.read(body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	app := path.Base(r.URL.Path)
+	for _, m := range msgs {
+		if app != "/" && app != "." {
+			m.SetMeta("app", app)
+		}
+		if _, err := h.cfg.Logger.Log(m); err != nil {
+			http.Error(w, "unable to log the message", http.StatusBadGateway)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Reads and validates all messages of the body
+func (h *Handler) read(body io.Reader) ([]*jog.Message, error) {
+	// The limit applies to decompressed bodies too
+	b, err := io.ReadAll(io.LimitReader(body, h.cfg.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > h.cfg.MaxBytes {
+		return nil, &http.MaxBytesError{Limit: h.cfg.MaxBytes}
+	}
+	var msgs []*jog.Message
+	s := bufio.NewScanner(bytes.NewReader(b))
+	s.Buffer(nil, len(b)+1)
+	for n := 1; s.Scan(); n++ {
+		line := bytes.TrimSpace(s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		m, err := jog.Decode(line)
+		if err == nil {
+			err = h.validate(m)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %s", n, err)
+		}
+		msgs = append(msgs, m)
+	}
+	if len(msgs) == 0 {
+		return nil, errors.New("no messages")
+	}
+	return msgs, nil
+}
+
+// Returns the body decoded from its Content-Encoding
+func decompress(encoding string, body io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return body, nil
+	case "gzip":
+		return gzip.NewReader(body)
+	case "deflate":
+		return zlib.NewReader(body)
+	}
+	return nil, fmt.Errorf("unsupported Content-Encoding %q", encoding)
+}
+
+func (h *Handler) validate(m *jog.Message) error {
+	if m.Level == jog.UNKNOWN {
+		return errors.New("unknown level")
+	}
+	if m.Data == nil {
+		return errors.New("missing data")
+	}
+	if m.Time.IsZero() {
+		m.Time = time.Now().UTC()
+	}
+	if strings.TrimSpace(m.File) == "" {
+		m.File = "???"
+	}
+	if h.cfg.Validate != nil {
+		return h.cfg.Validate(m)
+	}
+	return nil
+}
+
+// New returns a new Handler passing messages to the configured Logger
+func New(c Config) *Handler {
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = 1 << 20
+	}
+	return &Handler{c}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"code.minty.io/jog"
+	"code.minty.io/jog/jogtest"
+	"code.minty.io/jog/loggers"
+)
+
+func TestHandler(t *testing.T) {
+	_, rec := jogtest.New()
+	srv := httptest.NewServer(New(Config{Logger: rec}))
+	defer srv.Close()
+
+	// The basic logger posts to `url/name`
+	l := loggers.New(srv.Client(), "billing", srv.URL+"/logs")
+	j := jog.New(l)
+	if err := j.Warning(map[string]interface{}{"id": 7}); err != nil {
+		t.Fatal(err)
+	}
+	m := rec.Last()
+	if m == nil || m.Level != jog.WARNING || m.Meta["app"] != "billing" || m.Line == 0 {
+		t.Fatalf("Unexpected message %+v", m)
+	}
+
+	tests := []struct {
+		method, body string
+		status       int
+	}{
+		{"POST", `{"data":"a","level":"info"}` + "\n" + `{"data":"b","level":"debug"}`, 204},
+		{"POST", `{"data":"a","level":"bogus"}`, 400},
+		{"POST", `{"level":"info"}`, 400},
+		{"POST", ``, 400},
+		{"POST", `{"data":"` + strings.Repeat("x", 2<<20) + `","level":"info"}`, 413},
+		{"GET", ``, 405},
+	}
+	for _, v := range tests {
+		r, _ := http.NewRequest(v.method, srv.URL+"/logs/app", strings.NewReader(v.body))
+		resp, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != v.status {
+			t.Errorf("Expected %d for %.40q, got %d", v.status, v.body, resp.StatusCode)
+		}
+	}
+	if n := len(rec.Entries()); n != 3 {
+		t.Error("Expected 3 messages logged, got", n)
+	}
+}
+
+func TestHandlerCompressed(t *testing.T) {
+	_, rec := jogtest.New()
+	srv := httptest.NewServer(New(Config{Logger: rec}))
+	defer srv.Close()
+
+	for _, c := range []loggers.Codec{loggers.Gzip, loggers.Deflate} {
+		j := jog.New(loggers.NewCompressed(srv.Client(), "billing", srv.URL+"/logs", c))
+		if err := j.Info(c.Name()); err != nil {
+			t.Fatal(c.Name(), err)
+		}
+		if m := rec.Last(); m == nil || m.Data != c.Name() {
+			t.Errorf("Expected the %s message, got %+v", c.Name(), m)
+		}
+	}
+
+	r, _ := http.NewRequest("POST", srv.URL+"/logs/app", strings.NewReader("x"))
+	r.Header.Set("Content-Encoding", "br")
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Error("Expected an unknown encoding to be unsupported, got", resp.StatusCode)
+	}
+}
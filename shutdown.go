@@ -0,0 +1,129 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ShutdownTimeout is how long HandleShutdown waits for the loggers to close
+var ShutdownTimeout = 10 * time.Second
+
+// ErrShutdownTimeout is returned when the loggers haven't closed by the ShutdownTimeout
+var ErrShutdownTimeout = errors.New("jog: timed out closing loggers")
+
+// Dropper is implemented by Loggers that count the messages they've lost, eg. to
+// a full queue
+type Dropper interface {
+	Dropped() uint64
+}
+
+var registered = struct {
+	sync.Mutex
+	loggers []Logger
+}{}
+
+// Register adds loggers to be flushed and closed by HandleShutdown
+func Register(ls ...Logger) {
+	registered.Lock()
+	registered.loggers = append(registered.loggers, ls...)
+	registered.Unlock()
+}
+
+// ShutdownReport is the outcome of HandleShutdown
+type ShutdownReport struct {
+	Reason  string `json:"reason"`
+	Closed  int    `json:"closed"`
+	Dropped uint64 `json:"dropped"`
+}
+
+// HandleShutdown blocks until SIGINT or SIGTERM is received, or the context is
+// done, then logs a shutdown message and closes the registered loggers, or the
+// Jog's own logger when none are, waiting up to ShutdownTimeout. Loggers that
+// only implement `Flush() error` are flushed. The report counts the messages
+// the loggers dropped, see Dropper.
+func HandleShutdown(ctx context.Context, j *Jog) (ShutdownReport, error) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(c)
+
+	reason := "context: "
+	select {
+	case s := <-c:
+		reason = "signal: " + s.String()
+	case <-ctx.Done():
+		reason += ctx.Err().Error()
+	}
+	return shutdown(j, reason, ShutdownTimeout)
+}
+
+func shutdown(j *Jog, reason string, timeout time.Duration) (ShutdownReport, error) {
+	j.output(j.Depth, WARNING, map[string]interface{}{"message": "shutting down", "reason": reason})
+	return closeLoggers(j, reason, timeout)
+}
+
+// Closes, or flushes, the registered loggers, or the Jog's own when none are
+func closeLoggers(j *Jog, reason string, timeout time.Duration) (ShutdownReport, error) {
+	registered.Lock()
+	loggers := registered.loggers
+	registered.loggers = nil
+	registered.Unlock()
+	if len(loggers) == 0 {
+		loggers = []Logger{j.logger}
+	}
+
+	r := ShutdownReport{Reason: reason}
+	errs := make(chan error, len(loggers))
+	for _, l := range loggers {
+		go func(l Logger) {
+			switch c := l.(type) {
+			case io.Closer:
+				errs <- c.Close()
+			case interface{ Flush() error }:
+				errs <- c.Flush()
+			default:
+				errs <- nil
+			}
+		}(l)
+	}
+
+	var err error
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	for range loggers {
+		select {
+		case e := <-errs:
+			if e != nil && err == nil {
+				err = e
+			}
+			r.Closed++
+		case <-t.C:
+			err = ErrShutdownTimeout
+		}
+		if err == ErrShutdownTimeout {
+			break
+		}
+	}
+	for _, l := range loggers {
+		if d, ok := l.(Dropper); ok {
+			r.Dropped += d.Dropped()
+		}
+	}
+	if r.Dropped > 0 || err != nil {
+		f := map[string]interface{}{"reason": reason, "closed": r.Closed, "dropped": r.Dropped}
+		if err != nil {
+			f["error"] = err.Error()
+		}
+		Diagnose(WARNING, "shutdown", f)
+	}
+	return r, err
+}
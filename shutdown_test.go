@@ -0,0 +1,54 @@
+package jog
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type closeLogger struct {
+	countLogger
+	closed  bool
+	dropped uint64
+	block   chan struct{}
+}
+
+func (l *closeLogger) Close() error {
+	if l.block != nil {
+		<-l.block
+	}
+	l.closed = true
+	return nil
+}
+
+func (l *closeLogger) Dropped() uint64 { return l.dropped }
+
+func TestHandleShutdown(t *testing.T) {
+	l := &testLogger{}
+	c := &closeLogger{dropped: 3}
+	Register(c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, err := HandleShutdown(ctx, New(l))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.closed || r.Closed != 1 || r.Dropped != 3 || r.Reason != "context: context canceled" {
+		t.Errorf("Unexpected report %+v", r)
+	}
+	if l.message == nil || l.message.Level != WARNING {
+		t.Error("Expected a shutdown message to be logged")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	c := &closeLogger{block: make(chan struct{})}
+	defer close(c.block)
+	Register(c)
+
+	r, err := shutdown(New(&countLogger{}), "test", 10*time.Millisecond)
+	if err != ErrShutdownTimeout || r.Closed != 0 {
+		t.Errorf("Expected a timeout, got %v %+v", err, r)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"math"
+	"sync"
+)
+
+// Bucket bounds grow by a quarter power of 2 from 64 bytes, reaching 2GiB
+const (
+	sizeMin     = 64
+	sizeBuckets = 100
+)
+
+// Sizes is a histogram of sizes in bytes, such as those of encoded messages,
+// reporting percentiles in fixed memory. As with Latency, percentiles are the
+// upper bound of their bucket, so are within 19% of the actual value. The zero
+// value is ready to use.
+type Sizes struct {
+	mu     sync.Mutex
+	counts [sizeBuckets + 1]uint64
+	n      uint64
+	max    int
+}
+
+// SizeStats are the percentiles of a Sizes
+type SizeStats struct {
+	Count uint64 `json:"count"`
+	P50   int    `json:"p50"`
+	P95   int    `json:"p95"`
+	P99   int    `json:"p99"`
+	Max   int    `json:"max"`
+}
+
+// Observe adds a size to the histogram
+func (s *Sizes) Observe(n int) {
+	i := 0
+	if n > sizeMin {
+		i = int(math.Ceil(4 * math.Log2(float64(n)/sizeMin)))
+		if i > sizeBuckets {
+			i = sizeBuckets
+		}
+	}
+	s.mu.Lock()
+	s.counts[i]++
+	s.n++
+	if n > s.max {
+		s.max = n
+	}
+	s.mu.Unlock()
+}
+
+// Stats returns the percentiles of the observed sizes
+func (s *Sizes) Stats() SizeStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := SizeStats{Count: s.n, Max: s.max}
+	if s.n == 0 {
+		return st
+	}
+	st.P50, st.P95, st.P99 = s.percentile(0.5), s.percentile(0.95), s.percentile(0.99)
+	return st
+}
+
+// Percentile returns the `p` percentile, between 0 and 1, of the observed sizes,
+// along with their count
+func (s *Sizes) Percentile(p float64) (int, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.n == 0 {
+		return 0, 0
+	}
+	return s.percentile(p), s.n
+}
+
+func (s *Sizes) percentile(p float64) int {
+	rank := uint64(math.Ceil(p * float64(s.n)))
+	var seen uint64
+	for i, c := range s.counts {
+		if seen += c; seen >= rank {
+			n := int(sizeMin * math.Exp2(float64(i)/4))
+			if n > s.max {
+				n = s.max
+			}
+			return n
+		}
+	}
+	return s.max
+}
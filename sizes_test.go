@@ -0,0 +1,23 @@
+package jog
+
+import "testing"
+
+func TestSizes(t *testing.T) {
+	var s Sizes
+	if st := s.Stats(); st.Count != 0 || st.P99 != 0 {
+		t.Error("Expected empty stats, got", st)
+	}
+	for i := 1; i <= 100; i++ {
+		s.Observe(i * 1000)
+	}
+	st := s.Stats()
+	within := func(n, want int) bool {
+		return n >= want && float64(n) <= float64(want)*1.19
+	}
+	if st.Count != 100 || st.Max != 100000 || !within(st.P50, 50000) || !within(st.P95, 95000) || st.P99 < 99000 {
+		t.Errorf("Unexpected stats %+v", st)
+	}
+	if p, n := s.Percentile(0.5); p != st.P50 || n != 100 {
+		t.Error("Expected the percentile to match the stats, got", p, n)
+	}
+}
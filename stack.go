@@ -0,0 +1,80 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// WithStacktrace adds the caller's stack to messages at, or above, the Level
+func WithStacktrace(l Level) Option {
+	return func(j *Jog) {
+		j.stack = l
+	}
+}
+
+// StackConfig controls how stacks are captured, and rendered, see WithStackConfig
+type StackConfig struct {
+	// Depth is the most frames captured (defaults to 64)
+	Depth int
+	// Skip is the number of frames skipped above the caller, eg. of logging helpers
+	Skip int
+	// TrimGoroot drops the frames of the standard library and runtime
+	TrimGoroot bool
+	// TrimVendor drops the frames of vendored packages
+	TrimVendor bool
+	// Structured sets the message's Frames, rather than its Stack text
+	Structured bool
+}
+
+// WithStackConfig sets how the stacks added by WithStacktrace are captured
+func WithStackConfig(c StackConfig) Option {
+	return func(j *Jog) {
+		j.stackCfg = c
+	}
+}
+
+// Frame is a function call of a stack
+type Frame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// Captures the stack, starting `skip` frames above the caller of frames
+func frames(skip int, c StackConfig) []Frame {
+	if c.Depth <= 0 {
+		c.Depth = 64
+	}
+	pcs := make([]uintptr, c.Depth+c.Skip)
+	n := runtime.Callers(skip+1+c.Skip, pcs)
+	goroot := filepath.ToSlash(runtime.GOROOT()) + "/"
+	it := runtime.CallersFrames(pcs[:n])
+	var out []Frame
+	for {
+		f, more := it.Next()
+		trim := (c.TrimGoroot && goroot != "/" && strings.HasPrefix(f.File, goroot)) ||
+			(c.TrimVendor && strings.Contains(f.File, "/vendor/"))
+		if !trim && len(out) < c.Depth {
+			out = append(out, Frame{f.Function, f.File, f.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+// Formats the frames like a goroutine's trace; `func\n\tfile:line` per frame
+func formatFrames(frames []Frame) string {
+	var b strings.Builder
+	for _, f := range frames {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Func, f.File, f.Line)
+	}
+	return b.String()
+}
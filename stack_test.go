@@ -0,0 +1,27 @@
+package jog
+
+import (
+	"strings"
+	"testing"
+)
+
+func logHelper(j *Jog) {
+	j.Error("kaboom")
+}
+
+func TestStackConfig(t *testing.T) {
+	l := &testLogger{}
+	j := New(l, WithStacktrace(ERROR), WithStackConfig(StackConfig{Depth: 2, Skip: 1, TrimGoroot: true, Structured: true}))
+	logHelper(j)
+
+	f := l.message.Frames
+	if l.message.Stack != "" || len(f) != 1 || f[0].Func != "code.minty.io/jog.TestStackConfig" || f[0].Line == 0 {
+		t.Errorf("Expected the test's frame only, got %+v", f)
+	}
+
+	j = New(l, WithStacktrace(ERROR), WithStackConfig(StackConfig{TrimGoroot: true}))
+	j.Error("kaboom")
+	if strings.Contains(l.message.Stack, "testing.tRunner") || !strings.HasPrefix(l.message.Stack, "code.minty.io/jog.TestStackConfig\n\t") {
+		t.Error("Expected the runtime frames to be trimmed, got", l.message.Stack)
+	}
+}
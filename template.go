@@ -0,0 +1,96 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateFuncs are the helpers available to templates of NewTemplate
+//
+//	pad N S        pads S with spaces to N characters, eg. `{{pad 8 .Level}}`
+//	upper S        upper cases S
+//	time LAYOUT T  formats T, eg. `{{time "15:04:05.000" .Time}}`
+//	field PATH D   the value at the dot separated PATH of D, eg. `{{field "user.id" .Data}}`
+//	json V         V encoded as JSON
+//	kv M           the fields of M as sorted key=value pairs, eg. `{{kv .Meta}}`
+//	base PATH      the last element of PATH, eg. `{{base .File}}`
+var TemplateFuncs = template.FuncMap{
+	"pad": func(n int, s interface{}) string {
+		return fmt.Sprintf("%-*s", n, fmt.Sprint(s))
+	},
+	"upper": func(s interface{}) string {
+		return strings.ToUpper(fmt.Sprint(s))
+	},
+	"time": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	"field": field,
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"base": filepath.Base,
+	"kv": func(m map[string]interface{}) string {
+		var b strings.Builder
+		for i, f := range sortedFields(m) {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(f[0] + "=" + f[1])
+		}
+		return b.String()
+	},
+}
+
+// Looks up the value at a dot separated path, through maps and structs (by their JSON form)
+func field(path string, d interface{}) (interface{}, error) {
+	v := d
+	for _, k := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			if v == nil {
+				return nil, nil
+			}
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			if json.Unmarshal(b, &m) != nil {
+				return nil, nil
+			}
+		}
+		v = m[k]
+	}
+	return v, nil
+}
+
+// NewTemplate returns an Encoder rendering messages through a text/template, the
+// Message being the template's data, so its Meta (eg. the request ID) and Tags
+// are `.Meta` and `.Tags`. Each rendered message ends with a newline.
+//
+//	enc, err := jog.NewTemplate(`{{time "15:04:05" .Time}} {{pad 8 (upper .Level)}} {{.Data}} {{kv .Meta}}`)
+func NewTemplate(text string) (Encoder, error) {
+	t, err := template.New("jog").Funcs(TemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return EncoderFunc(func(m *Message) ([]byte, error) {
+		var b bytes.Buffer
+		if err := t.Execute(&b, m); err != nil {
+			return nil, err
+		}
+		if b.Len() == 0 || b.Bytes()[b.Len()-1] != '\n' {
+			b.WriteByte('\n')
+		}
+		return b.Bytes(), nil
+	}), nil
+}
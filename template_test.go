@@ -0,0 +1,34 @@
+package jog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTemplate(t *testing.T) {
+	enc, err := NewTemplate(`{{time "15:04:05" .Time}} {{pad 8 (upper .Level)}}|{{base .File}}:{{.Line}} {{field "user.name" .Data}} {{field "missing.key" .Data}} {{kv .Meta}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Message{
+		Level: WARNING,
+		File:  "/src/app/main.go",
+		Line:  12,
+		Time:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Data: map[string]interface{}{"user": struct {
+			Name string `json:"name"`
+		}{"bob"}},
+		Meta: map[string]interface{}{"request_id": "abc", "trace_id": "t 1"},
+	}
+	b, err := enc.Encode(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "03:04:05 WARNING |main.go:12 bob <no value> request_id=abc trace_id=\"t 1\"\n"; string(b) != exp {
+		t.Errorf("Expected %q, got %q", exp, b)
+	}
+
+	if _, err := NewTemplate(`{{.Nope`); err == nil {
+		t.Error("Expected a parse error")
+	}
+}
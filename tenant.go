@@ -0,0 +1,40 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+// Meta keys of the labels set by Service and Tenant
+const (
+	ServiceKey = "service"
+	TenantKey  = "tenant"
+)
+
+// Service returns a copy of the Jog whose messages are labelled with the service,
+// so modules hosted by one process can share a pipeline, and be routed by it, see
+// loggers.NewRouter
+func (j *Jog) Service(name string) *Jog {
+	return j.label(ServiceKey, name)
+}
+
+// Tenant returns a copy of the Jog whose messages are labelled with the tenant,
+// see Service
+func (j *Jog) Tenant(name string) *Jog {
+	return j.label(TenantKey, name)
+}
+
+func (j *Jog) label(key, v string) *Jog {
+	c := *j
+	c.meta = make(map[string]interface{}, len(j.meta)+1)
+	for k, e := range j.meta {
+		c.meta[k] = e
+	}
+	c.meta[key] = v
+	return &c
+}
+
+// Label returns the message's label of the key, eg. ServiceKey, or an empty string
+func (m *Message) Label(key string) string {
+	s, _ := m.Meta[key].(string)
+	return s
+}
@@ -0,0 +1,20 @@
+package jog
+
+import (
+	"testing"
+)
+
+func TestService(t *testing.T) {
+	l := &testLogger{}
+	j := New(l, WithMeta(map[string]interface{}{"host": "web1"}))
+	billing := j.Service("billing").Tenant("acme")
+
+	billing.Info("charged")
+	if l.message.Label(ServiceKey) != "billing" || l.message.Label(TenantKey) != "acme" || l.message.Meta["host"] != "web1" {
+		t.Error("Unexpected labels", l.message.Meta)
+	}
+	j.Info("parent")
+	if l.message.Label(ServiceKey) != "" {
+		t.Error("Expected the parent to be unlabelled, got", l.message.Meta)
+	}
+}
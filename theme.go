@@ -0,0 +1,113 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// ThemeEnv is the environment variable naming the console Theme used when the
+// ConsoleConfig doesn't set one, eg. `JOG_THEME=colorblind`
+const ThemeEnv = "JOG_THEME"
+
+// Theme is the styling of Console output
+type Theme struct {
+	// Colors are the ANSI SGR parameters of each level
+	Colors map[Level]string
+	// Muted is the SGR parameters of the timestamp, caller and stack
+	Muted string
+	// Markers precede the level names, so levels are distinguished without color
+	Markers map[Level]string
+	// ASCII escapes non-ASCII characters, eg. `é` as `\u00e9`, for terminals
+	// and fonts without Unicode
+	ASCII bool
+}
+
+var (
+	// DefaultTheme is the standard palette
+	DefaultTheme = Theme{Colors: DefaultColors, Muted: "90"}
+
+	// ColorBlindTheme uses the blue/orange/magenta palette of Okabe and Ito, rather
+	// than red and green, and marks each level with a shape
+	ColorBlindTheme = Theme{
+		Colors: map[Level]string{
+			DEBUG:    "38;5;246",
+			INFO:     "38;5;39",
+			WARNING:  "38;5;214",
+			ERROR:    "38;5;169",
+			CRITICAL: "1;4;38;5;169",
+		},
+		Muted:   "38;5;246",
+		Markers: map[Level]string{DEBUG: "·", INFO: "●", WARNING: "▲", ERROR: "✖", CRITICAL: "◆"},
+	}
+
+	// ASCIITheme marks levels with ASCII characters and escapes any other characters
+	ASCIITheme = Theme{
+		Colors:  DefaultColors,
+		Muted:   "90",
+		Markers: map[Level]string{DEBUG: ".", INFO: "-", WARNING: "!", ERROR: "x", CRITICAL: "X"},
+		ASCII:   true,
+	}
+
+	// HighContrastTheme uses bold, bright colors and backgrounds for the severe levels
+	HighContrastTheme = Theme{
+		Colors: map[Level]string{
+			DEBUG:    "97",
+			INFO:     "1;96",
+			WARNING:  "1;30;103",
+			ERROR:    "1;97;41",
+			CRITICAL: "1;97;45",
+		},
+		Muted: "97",
+	}
+)
+
+// Themes are the Themes selectable by name, eg. with ThemeEnv
+var Themes = map[string]Theme{
+	"default":       DefaultTheme,
+	"colorblind":    ColorBlindTheme,
+	"ascii":         ASCIITheme,
+	"high-contrast": HighContrastTheme,
+}
+
+// LookupTheme returns the named Theme, see Themes
+func LookupTheme(name string) (Theme, error) {
+	t, ok := Themes[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Theme{}, fmt.Errorf("jog: unknown console theme %q", name)
+	}
+	return t, nil
+}
+
+// Returns the Theme named by ThemeEnv, or the DefaultTheme
+func envTheme() Theme {
+	if name := os.Getenv(ThemeEnv); name != "" {
+		if t, err := LookupTheme(name); err == nil {
+			return t
+		}
+	}
+	return DefaultTheme
+}
+
+// Escapes the non-ASCII characters of `b` as `\uXXXX`
+func asciiOnly(b []byte) []byte {
+	var out bytes.Buffer
+	for len(b) > 0 {
+		r, n := utf8.DecodeRune(b)
+		if r < utf8.RuneSelf {
+			out.WriteByte(b[0])
+		} else if r > 0xffff {
+			fmt.Fprintf(&out, `\U%08x`, r)
+		} else {
+			fmt.Fprintf(&out, `\u%04x`, r)
+		}
+		b = b[n:]
+	}
+	return out.Bytes()
+}
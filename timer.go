@@ -0,0 +1,47 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import "time"
+
+// Elapsed is the Data logged for a timed operation
+type Elapsed struct {
+	Operation string  `json:"operation"`
+	Duration  float64 `json:"duration_ms"`
+}
+
+// Timer times an operation, logging its duration when Done is called
+type Timer struct {
+	Level Level
+	j     *Jog
+	name  string
+	start time.Time
+}
+
+// Done logs the time elapsed since the Timer was started
+func (t *Timer) Done() error {
+	_, err := t.j.output(t.j.Depth-1, t.Level, elapsed(t.name, t.j.now().Sub(t.start)))
+	return err
+}
+
+// Timer starts timing the named operation, which is logged at INFO when Done is called.
+//
+//	t := j.Timer("db.query")
+//	defer t.Done()
+func (j *Jog) Timer(name string) *Timer {
+	return &Timer{INFO, j, name, j.now()}
+}
+
+// LogDuration runs `fn` and logs how long it took with the given Level
+func (j *Jog) LogDuration(l Level, name string, fn func()) error {
+	start := j.now()
+	fn()
+	_, err := j.output(j.Depth-1, l, elapsed(name, j.now().Sub(start)))
+	return err
+}
+
+func elapsed(name string, d time.Duration) Elapsed {
+	return Elapsed{name, float64(d) / float64(time.Millisecond)}
+}
@@ -0,0 +1,27 @@
+package jog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimer(t *testing.T) {
+	l := &testLogger{}
+	j := New(l)
+
+	j.LogDuration(WARNING, "sleep", func() { time.Sleep(time.Millisecond) })
+	e, ok := l.message.Data.(Elapsed)
+	if !ok || e.Operation != "sleep" || e.Duration < 1 || l.message.Level != WARNING {
+		t.Errorf("Expected elapsed sleep at WARNING, got %+v %s", l.message.Data, l.message.Level)
+	}
+	if !strings.HasSuffix(l.message.File, "timer_test.go") {
+		t.Error("Expected caller to be the test, got", l.message.File)
+	}
+
+	tm := j.Timer("noop")
+	tm.Done()
+	if e, ok := l.message.Data.(Elapsed); !ok || e.Operation != "noop" || l.message.Level != INFO {
+		t.Errorf("Expected elapsed noop at INFO, got %+v", l.message.Data)
+	}
+}
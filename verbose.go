@@ -0,0 +1,100 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"strings"
+	"sync"
+)
+
+var verbosity = struct {
+	sync.RWMutex
+	levels map[string]int
+}{levels: map[string]int{}}
+
+// SetVerbosity sets the verbosity of the named logger, and of those named below
+// it (eg. `db` covers `db.pool`) that don't have their own. The empty name sets
+// the default, which is 0.
+func SetVerbosity(name string, v int) {
+	verbosity.Lock()
+	verbosity.levels[name] = v
+	verbosity.Unlock()
+}
+
+// ClearVerbosity removes the verbosity set for the named logger, so it inherits that
+// of the logger above it again
+func ClearVerbosity(name string) {
+	verbosity.Lock()
+	delete(verbosity.levels, name)
+	verbosity.Unlock()
+}
+
+// Verbosity returns the verbosity of the named logger
+func Verbosity(name string) int {
+	verbosity.RLock()
+	defer verbosity.RUnlock()
+	for {
+		if v, ok := verbosity.levels[name]; ok {
+			return v
+		}
+		if name == "" {
+			return 0
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			name = ""
+		} else {
+			name = name[:i]
+		}
+	}
+}
+
+// Named returns a copy of the Jog whose messages carry the name, as the `logger`
+// Meta field. Names are nested with dots, eg. `j.Named("db").Named("pool")` is
+// `db.pool`. The name selects the logger's verbosity, see V.
+func (j *Jog) Named(name string) *Jog {
+	c := *j
+	if j.name != "" {
+		name = j.name + "." + name
+	}
+	c.name = name
+	return &c
+}
+
+// Name returns the logger's name, see Named
+func (j *Jog) Name() string {
+	return j.name
+}
+
+// Verbose logs DEBUG messages when its verbosity is enabled, see V
+type Verbose struct {
+	j *Jog
+	v int
+}
+
+// V returns a Verbose logging at DEBUG when `v` is within the verbosity set for the
+// logger's name (see SetVerbosity), and DEBUG is enabled. It guards chatty
+// instrumentation that is kept in the code, but is off in normal runs.
+//
+//	j.V(2).Info("cache miss")
+func (j *Jog) V(v int) Verbose {
+	return Verbose{j, v}
+}
+
+// Enabled reports whether the Verbose logs
+func (v Verbose) Enabled() bool {
+	return v.j.Enabled(DEBUG) && v.v <= Verbosity(v.j.name)
+}
+
+// Info logs the object at DEBUG, with its verbosity as the `v` Meta field
+func (v Verbose) Info(o interface{}) error {
+	if !v.Enabled() {
+		return nil
+	}
+	m := v.j.newMessage(DEBUG, o, v.j.Depth-1)
+	m.SetMeta("v", v.v)
+	_, err := v.j.write(m)
+	return err
+}
@@ -0,0 +1,69 @@
+package jog
+
+import (
+	"strings"
+	"testing"
+)
+
+// Restores the verbosity settings after the test
+func keepVerbosity(t *testing.T) {
+	verbosity.Lock()
+	saved := make(map[string]int, len(verbosity.levels))
+	for k, v := range verbosity.levels {
+		saved[k] = v
+	}
+	verbosity.Unlock()
+	t.Cleanup(func() {
+		verbosity.Lock()
+		verbosity.levels = saved
+		verbosity.Unlock()
+	})
+}
+
+func TestVerbose(t *testing.T) {
+	keepVerbosity(t)
+
+	l := &anyLogger{}
+	j := New(l)
+	db := j.Named("db")
+	pool := db.Named("pool")
+	if pool.Name() != "db.pool" {
+		t.Error("Expected a nested name, got", pool.Name())
+	}
+
+	SetVerbosity("db", 2)
+	db.V(1).Info("logged")
+	db.V(3).Info("too verbose")
+	pool.V(2).Info("inherited")
+	j.V(1).Info("default verbosity is 0")
+
+	SetVerbosity("db.pool", 0)
+	pool.V(1).Info("overridden")
+	New(l, WithLevel(INFO)).Named("db").V(1).Info("DEBUG disabled")
+
+	if len(l.logged) != 2 {
+		t.Fatal("Expected 2 messages, got", len(l.logged))
+	}
+	m := l.logged[0].(*Message)
+	if m.Level != DEBUG || m.Meta["logger"] != "db" || m.Meta["v"] != 1 || !strings.HasSuffix(m.File, "verbose_test.go") {
+		t.Errorf("Unexpected message %+v", m)
+	}
+	if m := l.logged[1].(*Message); m.Meta["logger"] != "db.pool" {
+		t.Error("Expected the nested logger's name, got", m.Meta)
+	}
+}
+
+func TestClearVerbosity(t *testing.T) {
+	keepVerbosity(t)
+
+	SetVerbosity("db", 2)
+	SetVerbosity("db.pool", 0)
+	ClearVerbosity("db.pool")
+	if v := Verbosity("db.pool"); v != 2 {
+		t.Error("Expected the cleared logger to inherit its parent's verbosity, got", v)
+	}
+	ClearVerbosity("db")
+	if v := Verbosity("db.pool"); v != 0 {
+		t.Error("Expected the default verbosity, got", v)
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// WireSchema is the JSON Schema of the Message wire format (schema.json), for
+// producers in other languages to validate against
+//
+//go:embed schema.json
+var WireSchema []byte
+
+// WireError describes why an encoded message doesn't conform to the WireSchema
+type WireError struct {
+	Field  string
+	Reason string
+}
+
+func (e *WireError) Error() string {
+	if e.Field == "" {
+		return "jog: invalid message: " + e.Reason
+	}
+	return fmt.Sprintf("jog: invalid message field `%s`: %s", e.Field, e.Reason)
+}
+
+// Checks of the known fields, unknown fields are allowed so newer versions validate
+var wireFields = map[string]func(v interface{}) string{
+	"data":           func(interface{}) string { return "" },
+	"level":          wireLevel,
+	"file":           wireString,
+	"line":           wireCount,
+	"timestamp":      wireTime,
+	"id":             wireString,
+	"func":           wireString,
+	"stack":          wireString,
+	"frames":         wireFrames,
+	"schema_version": wireVersion,
+	"truncated":      wireBool,
+	"length":         wireCount,
+	"raw":            wireString,
+	"meta":           wireObject,
+	"tags":           wireTags,
+}
+
+var wireRequired = []string{"data", "level", "timestamp"}
+
+// ValidateWire reports whether `b` is a single message conforming to the WireSchema,
+// returning a *WireError describing the first problem found
+func ValidateWire(b []byte) error {
+	var o map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&o); err != nil {
+		return &WireError{Reason: err.Error()}
+	}
+	if d.More() {
+		return &WireError{Reason: "more than one value"}
+	}
+	if o == nil {
+		return &WireError{Reason: "not an object"}
+	}
+	for _, k := range wireRequired {
+		if _, ok := o[k]; !ok {
+			return &WireError{k, "missing"}
+		}
+	}
+	for k, v := range o {
+		if check, ok := wireFields[k]; ok {
+			if r := check(v); r != "" {
+				return &WireError{k, r}
+			}
+		}
+	}
+	return nil
+}
+
+func wireString(v interface{}) string {
+	if _, ok := v.(string); !ok {
+		return "not a string"
+	}
+	return ""
+}
+
+func wireBool(v interface{}) string {
+	if _, ok := v.(bool); !ok {
+		return "not a boolean"
+	}
+	return ""
+}
+
+func wireObject(v interface{}) string {
+	if _, ok := v.(map[string]interface{}); !ok {
+		return "not an object"
+	}
+	return ""
+}
+
+// Integers of at least `min`
+func wireInt(v interface{}, min int64) string {
+	n, ok := v.(json.Number)
+	if !ok {
+		return "not an integer"
+	}
+	i, err := strconv.ParseInt(string(n), 10, 64)
+	if err != nil {
+		return "not an integer"
+	}
+	if i < min {
+		return fmt.Sprintf("less than %d", min)
+	}
+	return ""
+}
+
+func wireCount(v interface{}) string   { return wireInt(v, 0) }
+func wireVersion(v interface{}) string { return wireInt(v, 1) }
+
+func wireLevel(v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		return "not a string"
+	}
+	if _, ok := severity[Level(s)]; !ok && Level(s) != UNKNOWN {
+		return fmt.Sprintf("unknown level %q", s)
+	}
+	return ""
+}
+
+func wireTime(v interface{}) string {
+	var b []byte
+	switch t := v.(type) {
+	case string:
+		b, _ = json.Marshal(t)
+	case json.Number:
+		b = []byte(t)
+	default:
+		return "not a string or number"
+	}
+	if _, err := parseTime(b); err != nil {
+		return "not a timestamp"
+	}
+	return ""
+}
+
+func wireTags(v interface{}) string {
+	a, ok := v.([]interface{})
+	if !ok {
+		return "not an array"
+	}
+	for _, t := range a {
+		if _, ok := t.(string); !ok {
+			return "not an array of strings"
+		}
+	}
+	return ""
+}
+
+func wireFrames(v interface{}) string {
+	a, ok := v.([]interface{})
+	if !ok {
+		return "not an array"
+	}
+	for i, e := range a {
+		f, ok := e.(map[string]interface{})
+		if !ok {
+			return fmt.Sprintf("frame %d is not an object", i)
+		}
+		for k, check := range map[string]func(interface{}) string{"func": wireString, "file": wireString, "line": wireCount} {
+			fv, ok := f[k]
+			if !ok {
+				return fmt.Sprintf("frame %d is missing `%s`", i, k)
+			}
+			if r := check(fv); r != "" {
+				return fmt.Sprintf("frame %d `%s` is %s", i, k, r)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package jog
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWireGolden(t *testing.T) {
+	valid, _ := filepath.Glob("testdata/wire/valid/*.json")
+	invalid, _ := filepath.Glob("testdata/wire/invalid/*.json")
+	if len(valid) == 0 || len(invalid) == 0 {
+		t.Fatal("Expected golden files in testdata/wire")
+	}
+	for _, f := range valid {
+		b, _ := os.ReadFile(f)
+		if err := ValidateWire(b); err != nil {
+			t.Error("Expected", f, "to be valid, got", err)
+		}
+		if _, err := Decode(b); err != nil {
+			t.Error("Expected", f, "to decode, got", err)
+		}
+	}
+	for _, f := range invalid {
+		b, _ := os.ReadFile(f)
+		var we *WireError
+		if err := ValidateWire(b); !errors.As(err, &we) {
+			t.Error("Expected", f, "to be invalid, got", err)
+		}
+	}
+}
+
+func TestWireEncoded(t *testing.T) {
+	m := &Message{Data: map[string]interface{}{"a": 1}, Level: WARNING, File: "a.go", Line: 2, Time: time.Now(), ID: "x", Tags: []string{"t"}}
+	m.SetMeta("host", "web1")
+	m.Frames = []Frame{{"main.main", "a.go", 2}}
+	v2, _ := NewVersioned(SchemaVersion)
+	for _, e := range []Encoder{JSON, v2} {
+		b, err := e.Encode(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ValidateWire(b); err != nil {
+			t.Error("Expected encoded messages to be valid, got", err, string(b))
+		}
+	}
+}
+
+// The schema must describe every field of Message
+func TestWireSchemaFields(t *testing.T) {
+	var s struct {
+		Properties map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(WireSchema, &s); err != nil {
+		t.Fatal(err)
+	}
+	typ := reflect.TypeOf(Message{})
+	for i := 0; i < typ.NumField(); i++ {
+		tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		if _, ok := s.Properties[tag]; !ok {
+			t.Error("Expected the schema to describe", tag)
+		}
+		if _, ok := wireFields[tag]; !ok {
+			t.Error("Expected ValidateWire to check", tag)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2013 Justin Wilson. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jog
+
+import "unicode/utf8"
+
+var (
+	// MaxWrite is the default size, in bytes, of the lines accepted by Write, see WithMaxWrite
+	MaxWrite = 1 << 20
+	// MaxDepth is the default nesting depth of the JSON lines parsed by Write, see WithMaxDepth
+	MaxDepth = 64
+)
+
+// WithMaxWrite limits the size of a line written with Write, eg. through
+// log.SetOutput, so a misbehaving library can't exhaust memory in the logging
+// path. Longer lines are cut down, marked Truncated, and not parsed as JSON.
+// Zero, or less, accepts lines of any size.
+func WithMaxWrite(n int) Option {
+	return func(j *Jog) {
+		j.maxWrite = n
+	}
+}
+
+// WithMaxDepth limits the nesting depth of the JSON lines parsed by Write, deeper
+// lines are logged as text. Zero, or less, parses any depth.
+func WithMaxDepth(n int) Option {
+	return func(j *Jog) {
+		j.maxDepth = n
+	}
+}
+
+// Returns the deepest nesting of objects and arrays in `p`, skipping strings
+func jsonDepth(p []byte) int {
+	var depth, max int
+	var str, esc bool
+	for _, c := range p {
+		switch {
+		case esc:
+			esc = false
+		case str:
+			if c == '\\' {
+				esc = true
+			} else if c == '"' {
+				str = false
+			}
+		case c == '"':
+			str = true
+		case c == '{' || c == '[':
+			if depth++; depth > max {
+				max = depth
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return max
+}
+
+// Returns at most `n` bytes of `p`, not splitting a UTF-8 encoded character
+func cutUTF8(p []byte, n int) []byte {
+	if len(p) <= n {
+		return p
+	}
+	for n > 0 && !utf8.RuneStart(p[n]) {
+		n--
+	}
+	return p[:n]
+}
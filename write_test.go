@@ -0,0 +1,48 @@
+package jog
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestMaxWrite(t *testing.T) {
+	l := &testLogger{}
+	j := New(l, WithMaxWrite(8))
+	if _, err := j.Write([]byte(`{"a":"ééééé"}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := l.message.Data.(string); s != `{"a":"é` || !l.message.Truncated || l.message.Length != 18 {
+		t.Errorf("Expected the line to be cut down, got %q %v %d", l.message.Data, l.message.Truncated, l.message.Length)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	l := &testLogger{}
+	j := New(l, WithMaxDepth(3))
+	j.Write([]byte(`{"a":{"b":{"c":"]]]]{{{{"}}}`))
+	if _, ok := l.message.Data.(map[string]interface{}); !ok {
+		t.Error("Expected JSON within the depth to be parsed, got", l.message.Data)
+	}
+	deep := strings.Repeat(`{"a":`, 4) + "1" + strings.Repeat("}", 4)
+	j.Write([]byte(deep))
+	if l.message.Data != deep {
+		t.Error("Expected deeper JSON to be logged as text, got", l.message.Data)
+	}
+}
+
+func FuzzWrite(f *testing.F) {
+	for _, s := range []string{"", "\n", "hello", `{"level":"error","a":1}`, `{"a":[[[[{}]]]]}`, `{"a":"\"}`, "{\xff}", `{}}`} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, p []byte) {
+		l := &testLogger{}
+		j := New(l, WithMaxWrite(64), WithMaxDepth(4))
+		if _, err := j.Write(p); err != nil {
+			t.Fatal(err)
+		}
+		if s, ok := l.message.Data.(string); ok && (len(s) > 64 || (utf8.Valid(p) && !utf8.ValidString(s))) {
+			t.Errorf("Expected at most 64 valid bytes, got %q", s)
+		}
+	})
+}